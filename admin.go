@@ -0,0 +1,116 @@
+package lite
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/pprof"
+)
+
+// SetAdminAddress serves the Swagger UI, the OpenAPI and AsyncAPI specs, the health probes, the metrics and pprof
+// on a separate listener at the given address, e.g. 127.0.0.1:9090. The public listener then only serves the routes
+// of the app. The address has the formats of SetAddress, Run returns an error otherwise.
+// Default is no admin listener.
+func SetAdminAddress(address string) Config {
+	return func(s *App) {
+		if _, _, err := parseAddress(address); err != nil {
+			s.configErrors = append(s.configErrors, fmt.Errorf("invalid admin address: %w", err))
+
+			return
+		}
+
+		s.adminAddress = address
+	}
+}
+
+// UseAdmin registers middlewares or handlers on the admin listener, like Use on the public listener,
+// e.g. to restrict the access to the admin endpoints or to serve other endpoints. It panics without SetAdminAddress.
+func UseAdmin(app *App, args ...any) {
+	if app.admin == nil {
+		panic("the admin listener requires SetAdminAddress")
+	}
+
+	app.admin.Use(args...)
+}
+
+// newAdminApp returns the fiber app of the admin listener, rendering the errors like the public one.
+func (s *App) newAdminApp() *fiber.App {
+	admin := fiber.New(fiber.Config{
+		ErrorHandler:          s.errorHandler,
+		DisableStartupMessage: true,
+	})
+	admin.Use(s.recoverMiddleware)
+
+	return admin
+}
+
+// docsRouter returns the router of the docs, the specs and the health probes: the admin app if there is one.
+func (s *App) docsRouter() fiber.Router {
+	if s.admin != nil {
+		return s.admin
+	}
+
+	return s.app
+}
+
+// registerAdminEndpoints serves the endpoints which are only served by the admin listener.
+func (s *App) registerAdminEndpoints() {
+	if s.admin == nil {
+		return
+	}
+
+	s.admin.Get(metricsPath, s.metrics.handler)
+	s.admin.Use(pprof.New())
+}
+
+// serveAdmin serves the admin app until it is shut down. The returned function closes the admin listener,
+// even if the admin app is not served yet, and waits until it is not served anymore.
+func (s *App) serveAdmin() (func(), error) {
+	if s.admin == nil {
+		return func() {}, nil
+	}
+
+	listener, err := s.listenAddress(s.adminAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	ln := &onceCloseListener{Listener: listener}
+
+	s.logger.Info("admin listening", slog.String("address", ln.Addr().String()))
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		if err := s.admin.Listener(ln); err != nil {
+			s.logger.Error("admin listener stopped", slog.Any("error", err))
+		}
+	}()
+
+	return func() {
+		_ = ln.Close()
+		<-done
+	}, nil
+}
+
+// onceCloseListener closes its listener once: the admin listener is closed by Run as well as by the shutdown
+// of the admin app, whichever comes first.
+type onceCloseListener struct {
+	net.Listener
+
+	once sync.Once
+	err  error
+}
+
+func (l *onceCloseListener) Close() error {
+	l.once.Do(func() {
+		l.err = l.Listener.Close()
+	})
+
+	return l.err
+}
@@ -0,0 +1,125 @@
+package lite
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	port, err := getFreePort()
+	require.NoError(t, err)
+
+	adminAddress := fmt.Sprintf("127.0.0.1:%d", port)
+
+	app := New(SetDisableLocalSave(true), SetAdminAddress(adminAddress))
+
+	UseAdmin(app, func(c *fiber.Ctx) error {
+		if c.Get(HeaderAuthorization) != "Bearer admin" {
+			return fiber.ErrUnauthorized
+		}
+
+		return c.Next()
+	})
+	UseAdmin(app, "/version", func(c *fiber.Ctx) error {
+		return c.SendString("1.0.0")
+	})
+
+	Get(app, "/users", func(c *ContextNoRequest) (string, error) {
+		return "bob", nil
+	})
+
+	address, ran := runApp(t, app)
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	get := func(url string, authorization string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		req.Header.Set(HeaderOrigin, "https://example.com")
+
+		if authorization != "" {
+			req.Header.Set(HeaderAuthorization, authorization)
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		return resp
+	}
+
+	resp := get("http://"+address+"/users", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, resp.Header.Get(HeaderAccessControlAllowOrigin))
+
+	// the public listener only serves the routes of the app
+	for _, path := range []string{"/swagger/index.html", "/api/openapi.yaml", "/healthz", "/readyz", "/debug/pprof/", "/metrics", "/version"} {
+		resp = get("http://"+address+path, "Bearer admin")
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://" + adminAddress + "/healthz")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for _, path := range []string{"/swagger/index.html", "/api/openapi.yaml", "/healthz", "/readyz", "/debug/pprof/", "/metrics", "/version"} {
+		resp = get("http://"+adminAddress+path, "Bearer admin")
+		assert.Equal(t, http.StatusOK, resp.StatusCode, path)
+
+		resp = get("http://"+adminAddress+path, "")
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode, path)
+	}
+
+	resp = get("http://"+adminAddress+"/api/openapi.yaml", "Bearer admin")
+	assert.Equal(t, "*", resp.Header.Get(HeaderAccessControlAllowOrigin))
+
+	// the metrics count the requests of the routes of the public listener
+	req, err := http.NewRequest(http.MethodGet, "http://"+adminAddress+"/metrics", nil)
+	require.NoError(t, err)
+	req.Header.Set(HeaderAuthorization, "Bearer admin")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+
+	metrics, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Contains(t, string(metrics), `lite_requests_total{method="GET",route="/users",status="200"} 1`)
+	assert.Contains(t, string(metrics), `lite_request_duration_seconds_count{method="GET",route="/users"} 1`)
+	assert.NotContains(t, string(metrics), `route="/swagger`)
+
+	// the probes of the admin listener are not part of the public API
+	assert.Nil(t, app.openAPISpec.Paths.Find("/healthz"))
+	assert.NotNil(t, app.openAPISpec.Paths.Find("/users"))
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+
+	_, err = client.Get("http://" + adminAddress + "/healthz")
+	assert.Error(t, err)
+}
+
+func TestAdmin_Config(t *testing.T) {
+	assert.Panics(t, func() {
+		UseAdmin(New(), "/metrics", func(c *fiber.Ctx) error {
+			return nil
+		})
+	})
+
+	err := New(SetAdminAddress("9090")).Run()
+	assert.ErrorContains(t, err, "invalid admin address")
+}
@@ -0,0 +1,272 @@
+package lite
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	asyncAPIVersion      = "2.6.0"
+	asyncAPISchemaFormat = "application/vnd.oai.openapi;version=3.0.0"
+)
+
+type asyncAPI struct {
+	AsyncAPI   string                     `json:"asyncapi"`
+	Info       *openapi3.Info             `json:"info"`
+	Servers    map[string]asyncAPIServer  `json:"servers,omitempty"`
+	Channels   map[string]asyncAPIChannel `json:"channels"`
+	Components asyncAPIComponents         `json:"components"`
+}
+
+type asyncAPIServer struct {
+	URL         string `json:"url"`
+	Protocol    string `json:"protocol"`
+	Description string `json:"description,omitempty"`
+}
+
+type asyncAPIChannel struct {
+	Description string                       `json:"description,omitempty"`
+	Parameters  map[string]asyncAPIParameter `json:"parameters,omitempty"`
+	Subscribe   *asyncAPIOperation           `json:"subscribe,omitempty"`
+	Publish     *asyncAPIOperation           `json:"publish,omitempty"`
+	Bindings    map[string]any               `json:"bindings,omitempty"`
+}
+
+type asyncAPIParameter struct {
+	Description string              `json:"description,omitempty"`
+	Schema      *openapi3.SchemaRef `json:"schema,omitempty"`
+}
+
+type asyncAPIOperation struct {
+	OperationID string          `json:"operationId,omitempty"`
+	Summary     string          `json:"summary,omitempty"`
+	Tags        []asyncAPITag   `json:"tags,omitempty"`
+	Message     asyncAPIMessage `json:"message"`
+}
+
+type asyncAPITag struct {
+	Name string `json:"name"`
+}
+
+type asyncAPIMessage struct {
+	SchemaFormat string         `json:"schemaFormat"`
+	ContentType  string         `json:"contentType"`
+	Payload      map[string]any `json:"payload"`
+}
+
+type asyncAPIComponents struct {
+	Schemas openapi3.Schemas `json:"schemas,omitempty"`
+}
+
+// SetAsyncAPIPath sets the path to serve and save the AsyncAPI spec.
+// By default, the spec is served next to the OpenAPI spec.
+func SetAsyncAPIPath(path string) Config {
+	path = strings.TrimLeft(path, ".")
+
+	return func(s *App) {
+		s.openAPIConfig.asyncapiPath = path
+	}
+}
+
+// asyncAPIPath returns the path of the AsyncAPI spec, next to the OpenAPI spec if not set.
+func (s *App) asyncAPIPath() string {
+	if s.openAPIConfig.asyncapiPath != "" {
+		return s.openAPIConfig.asyncapiPath
+	}
+
+	return path.Join(path.Dir(s.openAPIConfig.openapiPath), "asyncapi"+path.Ext(s.openAPIConfig.openapiPath))
+}
+
+// newAsyncAPISpec builds the AsyncAPI spec from the WebSocket operations of the OpenAPI spec.
+// It returns false if the app does not expose any channel.
+func (s *App) newAsyncAPISpec() (asyncAPI, bool) {
+	spec := asyncAPI{
+		AsyncAPI: asyncAPIVersion,
+		Info:     s.openAPISpec.Info,
+		Channels: make(map[string]asyncAPIChannel),
+		Components: asyncAPIComponents{
+			Schemas: s.openAPISpec.Components.Schemas,
+		},
+	}
+
+	for routePath, pathItem := range s.openAPISpec.Paths.Map() {
+		operation := pathItem.Get
+		if operation == nil {
+			continue
+		}
+
+		messages, ok := operation.Extensions["x-websocket"].(map[string]any)
+		if !ok {
+			continue
+		}
+
+		spec.Channels[routePath] = s.newAsyncAPIChannel(operation, messages)
+	}
+
+	if len(spec.Channels) == 0 {
+		return spec, false
+	}
+
+	for i, server := range s.openAPISpec.Servers {
+		name := "default"
+		if i > 0 {
+			name = "server" + strconv.Itoa(i)
+		}
+
+		url, protocol := webSocketURL(server.URL)
+
+		if spec.Servers == nil {
+			spec.Servers = make(map[string]asyncAPIServer)
+		}
+
+		spec.Servers[name] = asyncAPIServer{
+			URL:         url,
+			Protocol:    protocol,
+			Description: server.Description,
+		}
+	}
+
+	return spec, true
+}
+
+func (s *App) newAsyncAPIChannel(operation *openapi3.Operation, messages map[string]any) asyncAPIChannel {
+	channel := asyncAPIChannel{
+		Description: operation.Description,
+	}
+
+	var tags []asyncAPITag
+	for _, tag := range operation.Tags {
+		tags = append(tags, asyncAPITag{Name: tag})
+	}
+
+	// The client publishes the messages received by the server and subscribes to the messages it sends.
+	if receive, ok := messages["receive"].(map[string]any); ok {
+		channel.Publish = &asyncAPIOperation{
+			OperationID: operationIDWithSuffix(operation.OperationID, "Receive"),
+			Summary:     operation.Summary,
+			Tags:        tags,
+			Message:     newAsyncAPIMessage(receive),
+		}
+	}
+
+	if send, ok := messages["send"].(map[string]any); ok {
+		channel.Subscribe = &asyncAPIOperation{
+			OperationID: operationIDWithSuffix(operation.OperationID, "Send"),
+			Summary:     operation.Summary,
+			Tags:        tags,
+			Message:     newAsyncAPIMessage(send),
+		}
+	}
+
+	query := openapi3.NewObjectSchema()
+	headers := openapi3.NewObjectSchema()
+
+	for _, parameterRef := range operation.Parameters {
+		parameter := parameterRef.Value
+		if parameter == nil {
+			if ref, ok := s.openAPISpec.Components.Parameters[strings.TrimPrefix(parameterRef.Ref, "#/components/parameters/")]; ok {
+				parameter = ref.Value
+			}
+		}
+
+		if parameter == nil {
+			continue
+		}
+
+		switch parameter.In {
+		case openapi3.ParameterInPath:
+			if channel.Parameters == nil {
+				channel.Parameters = make(map[string]asyncAPIParameter)
+			}
+
+			channel.Parameters[parameter.Name] = asyncAPIParameter{
+				Description: parameter.Description,
+				Schema:      parameter.Schema,
+			}
+		case openapi3.ParameterInQuery:
+			query.WithPropertyRef(parameter.Name, parameter.Schema)
+
+			if parameter.Required {
+				query.Required = append(query.Required, parameter.Name)
+			}
+		case openapi3.ParameterInHeader:
+			headers.WithPropertyRef(parameter.Name, parameter.Schema)
+
+			if parameter.Required {
+				headers.Required = append(headers.Required, parameter.Name)
+			}
+		}
+	}
+
+	binding := map[string]any{
+		"method":         http.MethodGet,
+		"bindingVersion": "0.1.0",
+	}
+
+	if len(query.Properties) > 0 {
+		sort.Strings(query.Required)
+		binding["query"] = query
+	}
+
+	if len(headers.Properties) > 0 {
+		sort.Strings(headers.Required)
+		binding["headers"] = headers
+	}
+
+	channel.Bindings = map[string]any{"ws": binding}
+
+	return channel
+}
+
+func newAsyncAPIMessage(payload map[string]any) asyncAPIMessage {
+	return asyncAPIMessage{
+		SchemaFormat: asyncAPISchemaFormat,
+		ContentType:  string(ContentTypeJSON),
+		Payload:      payload,
+	}
+}
+
+func operationIDWithSuffix(operationID, suffix string) string {
+	if operationID == "" {
+		return ""
+	}
+
+	return operationID + suffix
+}
+
+// webSocketURL converts the URL of an HTTP server to the URL of the WebSocket server.
+func webSocketURL(url string) (string, string) {
+	switch {
+	case strings.HasPrefix(url, "https://"):
+		return "wss://" + strings.TrimPrefix(url, "https://"), "wss"
+	case strings.HasPrefix(url, "http://"):
+		return "ws://" + strings.TrimPrefix(url, "http://"), "ws"
+	default:
+		return url, "ws"
+	}
+}
+
+// saveAsyncAPISpec marshals the AsyncAPI spec in the format of the OpenAPI spec.
+func (s *App) saveAsyncAPISpec(spec asyncAPI) ([]byte, error) {
+	jsonData, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.openAPIConfig.typeOfExtension == JSONExtension {
+		return jsonData, nil
+	}
+
+	return writeOpenAPISpec(jsonData)
+}
+
+func (s *App) asyncAPIPathHandler(c *fiber.Ctx) error {
+	return sendSpec(c, s.asyncAPIPath(), s.asyncAPIData)
+}
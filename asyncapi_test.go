@@ -0,0 +1,134 @@
+package lite
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chatHeaderRequest struct {
+	Room  string `lite:"params=room"`
+	Name  string `lite:"query=name"`
+	Token string `lite:"header=X-Token"`
+}
+
+func TestApp_newAsyncAPISpec(t *testing.T) {
+	app := New(AddServer("https://example.com", "production"))
+
+	WebSocket(app, "/chat/:room", func(conn *WebSocketConn[chatHeaderRequest, chatIn, chatOut]) error {
+		return nil
+	}).OperationID("chat")
+
+	Get(app, "/items", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+
+	spec, ok := app.newAsyncAPISpec()
+	require.True(t, ok)
+
+	assert.Equal(t, asyncAPIVersion, spec.AsyncAPI)
+	assert.Equal(t, app.openAPISpec.Info, spec.Info)
+	assert.Equal(t, asyncAPIServer{URL: "wss://example.com", Protocol: "wss", Description: "production"}, spec.Servers["default"])
+	require.Len(t, spec.Channels, 1)
+
+	channel := spec.Channels["/chat/{room}"]
+	require.NotNil(t, channel.Publish)
+	require.NotNil(t, channel.Subscribe)
+	assert.Equal(t, "chatReceive", channel.Publish.OperationID)
+	assert.Equal(t, "chatSend", channel.Subscribe.OperationID)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/chatIn"}, channel.Publish.Message.Payload)
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/chatOut"}, channel.Subscribe.Message.Payload)
+	assert.Contains(t, channel.Parameters, "room")
+
+	binding := channel.Bindings["ws"].(map[string]any)
+	assert.Contains(t, binding, "query")
+	assert.Contains(t, binding, "headers")
+
+	assert.Contains(t, spec.Components.Schemas, "chatIn")
+	assert.Contains(t, spec.Components.Schemas, "chatOut")
+}
+
+func TestApp_newAsyncAPISpec_NoChannels(t *testing.T) {
+	app := New()
+
+	Get(app, "/items", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+
+	_, ok := app.newAsyncAPISpec()
+	assert.False(t, ok)
+}
+
+func TestApp_asyncAPIPath(t *testing.T) {
+	app := New()
+	assert.Equal(t, "/api/asyncapi.yaml", app.asyncAPIPath())
+
+	app = New(SetOpenAPIPath("/docs/openapi.json"))
+	assert.Equal(t, "/docs/asyncapi.json", app.asyncAPIPath())
+
+	app = New(SetAsyncAPIPath("./events/asyncapi.yaml"))
+	assert.Equal(t, "/events/asyncapi.yaml", app.asyncAPIPath())
+}
+
+func TestWebSocketURL(t *testing.T) {
+	url, protocol := webSocketURL("http://localhost:9000")
+	assert.Equal(t, "ws://localhost:9000", url)
+	assert.Equal(t, "ws", protocol)
+
+	url, protocol = webSocketURL("https://example.com")
+	assert.Equal(t, "wss://example.com", url)
+	assert.Equal(t, "wss", protocol)
+}
+
+func TestApp_saveAsyncAPISpec(t *testing.T) {
+	app := New(SetTypeOfExtension(JSONExtension))
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	spec, ok := app.newAsyncAPISpec()
+	require.True(t, ok)
+
+	data, err := app.saveAsyncAPISpec(spec)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, asyncAPIVersion, decoded["asyncapi"])
+
+	app = New()
+
+	data, err = app.saveAsyncAPISpec(spec)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "asyncapi: 2.6.0")
+}
+
+func TestApp_Setup_AsyncAPI(t *testing.T) {
+	dir := t.TempDir()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	defer func() {
+		_ = os.Chdir(wd)
+	}()
+
+	app := New()
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	require.NoError(t, app.setup())
+
+	data, err := os.ReadFile("./api/asyncapi.yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "/chat/{room}")
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/api/asyncapi.yaml", nil))
+	require.NoError(t, err)
+	assert.Equal(t, StatusOK, resp.StatusCode)
+}
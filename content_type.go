@@ -87,4 +87,10 @@ const (
 	ContentTypeXFormData   ContentType = "application/x-www-form-urlencoded"
 	ContentTypeFormData    ContentType = "multipart/form-data"
 	ContentTypeOctetStream ContentType = "application/octet-stream"
+
+	ContentTypeOffsetOctetStream ContentType = "application/offset+octet-stream"
+	ContentTypeProblemJSON       ContentType = "application/problem+json"
+	ContentTypeProblemXML        ContentType = "application/problem+xml"
+	ContentTypeJSONLD            ContentType = "application/ld+json"
+	ContentTypeYAML              ContentType = "application/yaml"
 )
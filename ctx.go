@@ -134,6 +134,10 @@ func (c *ContextWithRequest[Request]) Requests() (Request, error) {
 		return req, err
 	}
 
+	if c.app != nil && c.app.devMode {
+		c.ctx.Locals(debugRequestKey, req)
+	}
+
 	if typeOfReq.Kind() == reflect.Struct {
 		err := c.app.validate(req)
 		if err != nil {
@@ -1,13 +1,17 @@
 package lite
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
+	"io"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/valyala/fasthttp"
 )
 
@@ -116,20 +120,39 @@ func parseTag(tag string) map[string]string {
 	return tagMap
 }
 
+// requestBodyReader returns a reader of the request body, streamed when the server streams request bodies.
+// The server does not limit the streamed bodies, so they are limited to the body limit of the route.
+func requestBodyReader(ctx *fasthttp.RequestCtx) io.Reader {
+	if ctx.Request.IsBodyStream() {
+		limit, ok := ctx.UserValue(bodyLimitKey).(int)
+		if !ok {
+			limit = fiber.DefaultBodyLimit
+		}
+
+		return &limitedReader{reader: ctx.RequestBodyStream(), limit: int64(limit), err: bodyTooLargeError(limit)}
+	}
+
+	return bytes.NewReader(ctx.Request.Body())
+}
+
 func deserializeBody(ctx *fasthttp.RequestCtx, fieldVal reflect.Value) error {
 	contentType := string(ctx.Request.Header.ContentType())
 
 	switch {
 	case strings.HasPrefix(contentType, "application/json"):
-		return json.Unmarshal(ctx.Request.Body(), fieldVal.Addr().Interface())
+		return bodyDecodingError("JSON", json.Unmarshal(ctx.Request.Body(), fieldVal.Addr().Interface()))
 	case strings.HasPrefix(contentType, "multipart/form-data"):
+		if binder, ok := fieldVal.Addr().Interface().(multipartStreamBinder); ok {
+			return binder.bindMultipart(ctx)
+		}
+
 		return parseMultipartForm(ctx, fieldVal.Addr().Interface())
 	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
 		return parseFormURLEncoded(ctx, fieldVal.Addr().Interface())
 	case strings.HasPrefix(contentType, "text/plain"):
 		fieldVal.SetString(string(ctx.Request.Body()))
 	case strings.HasPrefix(contentType, "application/xml"), strings.HasPrefix(contentType, "text/xml"):
-		return xml.Unmarshal(ctx.Request.Body(), fieldVal.Addr().Interface())
+		return bodyDecodingError("XML", xml.Unmarshal(ctx.Request.Body(), fieldVal.Addr().Interface()))
 	case strings.HasPrefix(contentType, "application/octet-stream"):
 		return parseOctetStream(ctx, fieldVal.Addr().Interface())
 	case strings.HasPrefix(contentType, "text/html"):
@@ -141,10 +164,11 @@ func deserializeBody(ctx *fasthttp.RequestCtx, fieldVal reflect.Value) error {
 	case strings.HasPrefix(contentType, "image/"):
 		return parseBinaryData(ctx, fieldVal.Addr().Interface())
 	default:
-		return InternalServerError{
+		return BadRequestError{
 			Context:     "/api/contexts/DeserializationError",
 			Type:        "DeserializationError",
-			Title:       "Internal server error",
+			Status:      StatusBadRequest,
+			Title:       "Deserialization error",
 			Description: "Unsupported content type: " + contentType,
 			Violations: []Violation{
 				{
@@ -158,6 +182,27 @@ func deserializeBody(ctx *fasthttp.RequestCtx, fieldVal reflect.Value) error {
 	return nil
 }
 
+// bodyDecodingError returns the error of a request body that could not be decoded, or nil if err is nil.
+func bodyDecodingError(format string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return BadRequestError{
+		Context:     "/api/contexts/DeserializationError",
+		Type:        "DeserializationError",
+		Status:      StatusBadRequest,
+		Title:       "Deserialization error",
+		Description: "Failed to decode " + format + " body",
+		Violations: []Violation{
+			{
+				PropertyPath: "body",
+				Message:      err.Error(),
+			},
+		},
+	}
+}
+
 func parseFormURLEncoded(ctx *fasthttp.RequestCtx, dst any) error {
 	formData := ctx.PostArgs()
 	data := make(map[string][]any)
@@ -170,13 +215,18 @@ func parseFormURLEncoded(ctx *fasthttp.RequestCtx, dst any) error {
 }
 
 func parseMultipartForm(ctx *fasthttp.RequestCtx, dst any) error {
+	// the body is checked before the form copies its files to memory or to temporary files
+	if err := scanMultipartFiles(ctx.Request.Body(), string(ctx.Request.Header.MultipartFormBoundary()), dst); err != nil {
+		return err
+	}
+
 	mr, err := ctx.MultipartForm()
 	if err != nil {
-		return InternalServerError{
+		return BadRequestError{
 			Context:     "/api/contexts/DeserializationError",
 			Type:        "DeserializationError",
-			Status:      StatusInternalServerError,
-			Title:       "Internal server error",
+			Status:      StatusBadRequest,
+			Title:       "Deserialization error",
 			Description: "Failed to parse multipart form",
 			Violations: []Violation{
 				{
@@ -286,7 +336,23 @@ func setFieldValue(fieldVal reflect.Value, valueStr any, dataValType ...reflect.
 
 	case reflect.Struct:
 		if str, ok := valueStr.(string); ok {
-			return json.Unmarshal([]byte(str), fieldVal.Addr().Interface())
+			if err := json.Unmarshal([]byte(str), fieldVal.Addr().Interface()); err != nil {
+				return BadRequestError{
+					Context:     "/api/contexts/DeserializationError",
+					Type:        "DeserializationError",
+					Status:      StatusBadRequest,
+					Title:       "Deserialization error",
+					Description: "Failed to unmarshal struct",
+					Violations: []Violation{
+						{
+							PropertyPath: fieldVal.Type().Name(),
+							Message:      err.Error(),
+						},
+					},
+				}
+			}
+
+			return nil
 		}
 
 		val := reflect.ValueOf(valueStr)
@@ -294,27 +360,57 @@ func setFieldValue(fieldVal reflect.Value, valueStr any, dataValType ...reflect.
 			val = val.Elem()
 		}
 
+		if val.Type() != fieldVal.Type() {
+			return unexpectedValueError(fieldVal, valueStr)
+		}
+
 		fieldVal.Set(val)
 
 	case reflect.String:
-		fieldVal.SetString(valueStr.(string))
+		str, err := textValue(fieldVal, valueStr)
+		if err != nil {
+			return err
+		}
+
+		fieldVal.SetString(str)
 
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		return setIntValue(fieldVal, valueStr.(string))
+		str, err := textValue(fieldVal, valueStr)
+		if err != nil {
+			return err
+		}
+
+		return setIntValue(fieldVal, str)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		return setUintValue(fieldVal, valueStr.(string))
+		str, err := textValue(fieldVal, valueStr)
+		if err != nil {
+			return err
+		}
+
+		return setUintValue(fieldVal, str)
 
 	case reflect.Float32, reflect.Float64:
-		return setFloatValue(fieldVal, valueStr.(string))
+		str, err := textValue(fieldVal, valueStr)
+		if err != nil {
+			return err
+		}
+
+		return setFloatValue(fieldVal, str)
 
 	case reflect.Bool:
-		boolValue, err := strconv.ParseBool(valueStr.(string))
+		str, err := textValue(fieldVal, valueStr)
 		if err != nil {
-			return InternalServerError{
+			return err
+		}
+
+		boolValue, err := strconv.ParseBool(str)
+		if err != nil {
+			return BadRequestError{
 				Context:     "/api/contexts/DeserializationError",
 				Type:        "DeserializationError",
-				Title:       "Internal server error",
+				Status:      StatusBadRequest,
+				Title:       "Deserialization error",
 				Description: "Failed to parse bool",
 				Violations: []Violation{
 					{
@@ -329,7 +425,12 @@ func setFieldValue(fieldVal reflect.Value, valueStr any, dataValType ...reflect.
 
 	case reflect.Slice, reflect.Array:
 		if fieldVal.Type().Elem().Kind() == reflect.Uint8 {
-			fieldVal.SetBytes([]byte(valueStr.(string)))
+			str, err := textValue(fieldVal, valueStr)
+			if err != nil {
+				return err
+			}
+
+			fieldVal.SetBytes([]byte(str))
 		} else {
 			// create a new slice of the same type as the data
 			if len(dataValType) == 0 {
@@ -347,24 +448,43 @@ func setFieldValue(fieldVal reflect.Value, valueStr any, dataValType ...reflect.
 				}
 			}
 
-			sliceType := reflect.SliceOf(dataValType[0])
-			newSlice := reflect.MakeSlice(sliceType, 0, 0)
+			// the values are converted to the type of the items, e.g. the texts of a []int
+			elemType := fieldVal.Type().Elem()
+			newSlice := reflect.MakeSlice(reflect.SliceOf(elemType), 0, 0)
 
 			for _, v := range valueStr.([]any) {
-				newSlice = reflect.Append(newSlice, reflect.ValueOf(v))
+				item := reflect.New(elemType).Elem()
+
+				if value := reflect.ValueOf(v); value.Type().AssignableTo(elemType) {
+					item.Set(value)
+				} else if err := setFieldValue(item, v); err != nil {
+					return err
+				}
+
+				newSlice = reflect.Append(newSlice, item)
 			}
 
-			fieldVal.Set(newSlice)
+			if fieldVal.Kind() == reflect.Array {
+				reflect.Copy(fieldVal, newSlice)
+			} else {
+				fieldVal.Set(newSlice)
+			}
 		}
 	case reflect.Interface:
 		fieldVal.Set(reflect.ValueOf(valueStr))
 	case reflect.Map:
 		if fieldVal.Type().Key().Kind() == reflect.String {
-			if err := json.Unmarshal([]byte(valueStr.(string)), fieldVal.Addr().Interface()); err != nil {
-				return InternalServerError{
+			str, err := textValue(fieldVal, valueStr)
+			if err != nil {
+				return err
+			}
+
+			if err = json.Unmarshal([]byte(str), fieldVal.Addr().Interface()); err != nil {
+				return BadRequestError{
 					Context:     "/api/contexts/DeserializationError",
 					Type:        "DeserializationError",
-					Title:       "Internal server error",
+					Status:      StatusBadRequest,
+					Title:       "Deserialization error",
 					Description: "Failed to unmarshal map",
 					Violations: []Violation{
 						{
@@ -410,13 +530,40 @@ func setFieldValue(fieldVal reflect.Value, valueStr any, dataValType ...reflect.
 	return nil
 }
 
+// textValue returns the text of a form value, or a BadRequestError if the value is not a text, e.g. a file.
+func textValue(fieldVal reflect.Value, value any) (string, error) {
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+
+	return "", unexpectedValueError(fieldVal, value)
+}
+
+// unexpectedValueError returns the error of a form value of the wrong kind, e.g. a file sent for a text field.
+func unexpectedValueError(fieldVal reflect.Value, value any) error {
+	return BadRequestError{
+		Context:     "/api/contexts/DeserializationError",
+		Type:        "DeserializationError",
+		Status:      StatusBadRequest,
+		Title:       "Deserialization error",
+		Description: "Unexpected value",
+		Violations: []Violation{
+			{
+				PropertyPath: fieldVal.Type().Name(),
+				Message:      fmt.Sprintf("Unexpected %T value for %s", value, fieldVal.Type()),
+			},
+		},
+	}
+}
+
 func setIntValue(fieldVal reflect.Value, valueStr string) error {
 	intValue, err := strconv.ParseInt(valueStr, 10, fieldVal.Type().Bits())
 	if err != nil {
-		return InternalServerError{
+		return BadRequestError{
 			Context:     "/api/contexts/DeserializationError",
 			Type:        "DeserializationError",
-			Title:       "Internal server error",
+			Status:      StatusBadRequest,
+			Title:       "Deserialization error",
 			Description: "Failed to parse int",
 			Violations: []Violation{
 				{
@@ -435,10 +582,11 @@ func setIntValue(fieldVal reflect.Value, valueStr string) error {
 func setUintValue(fieldVal reflect.Value, valueStr string) error {
 	uintValue, err := strconv.ParseUint(valueStr, 10, fieldVal.Type().Bits())
 	if err != nil {
-		return InternalServerError{
+		return BadRequestError{
 			Context:     "/api/contexts/DeserializationError",
 			Type:        "DeserializationError",
-			Title:       "Internal server error",
+			Status:      StatusBadRequest,
+			Title:       "Deserialization error",
 			Description: "Failed to parse uint",
 			Violations: []Violation{
 				{
@@ -457,10 +605,11 @@ func setUintValue(fieldVal reflect.Value, valueStr string) error {
 func setFloatValue(fieldVal reflect.Value, valueStr string) error {
 	floatValue, err := strconv.ParseFloat(valueStr, fieldVal.Type().Bits())
 	if err != nil {
-		return InternalServerError{
+		return BadRequestError{
 			Context:     "/api/contexts/DeserializationError",
 			Type:        "DeserializationError",
-			Title:       "Internal server error",
+			Status:      StatusBadRequest,
+			Title:       "Deserialization error",
 			Description: "Failed to parse float",
 			Violations: []Violation{
 				{
@@ -525,3 +525,75 @@ func (suite *DeserializerTestSuite) TestMapToStructWithEmptyTag() {
 	err := mapToStruct(m, val.Addr().Interface())
 	assert.NoError(suite.T(), err)
 }
+
+func (suite *DeserializerTestSuite) TestMapToStructUnexpectedValue() {
+	type testStruct struct {
+		Name   string                `form:"name"`
+		Age    int                   `form:"age"`
+		Scores []int                 `form:"scores"`
+		Avatar *multipart.FileHeader `form:"avatar"`
+	}
+
+	file := &multipart.FileHeader{Filename: "avatar.png"}
+
+	// a file sent for a text field, and a text sent for a file field
+	for _, m := range []map[string][]any{
+		{"name": {file}},
+		{"age": {file}},
+		{"scores": {file}},
+		{"avatar": {"avatar.png"}},
+	} {
+		var test testStruct
+
+		err := mapToStruct(m, &test)
+
+		var badRequest BadRequestError
+		assert.ErrorAs(suite.T(), err, &badRequest)
+	}
+
+	// the texts of a slice are converted to its items
+	var test testStruct
+
+	err := mapToStruct(map[string][]any{"scores": {"1", "2"}, "avatar": {file}}, &test)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []int{1, 2}, test.Scores)
+	assert.Equal(suite.T(), "avatar.png", test.Avatar.Filename)
+}
+
+func (suite *DeserializerTestSuite) TestMapToStructConvertedValues() {
+	type point struct {
+		X int `json:"x"`
+	}
+
+	type testStruct struct {
+		Point  point  `form:"point"`
+		Ranks  [2]int `form:"ranks"`
+		Labels []any  `form:"labels"`
+	}
+
+	var test testStruct
+
+	err := mapToStruct(map[string][]any{
+		"point":  {`{"x":1}`},
+		"ranks":  {"3", "4"},
+		"labels": {"a", "b"},
+	}, &test)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), point{X: 1}, test.Point)
+	assert.Equal(suite.T(), [2]int{3, 4}, test.Ranks)
+	assert.Equal(suite.T(), []any{"a", "b"}, test.Labels)
+
+	// an invalid JSON struct, an invalid item and a value of another type are rejected
+	for _, m := range []map[string][]any{
+		{"point": {`{"x":`}},
+		{"ranks": {"3", "four"}},
+		{"point": {&multipart.FileHeader{}}},
+	} {
+		var test testStruct
+
+		err := mapToStruct(m, &test)
+
+		var badRequest BadRequestError
+		assert.ErrorAs(suite.T(), err, &badRequest)
+	}
+}
@@ -0,0 +1,271 @@
+package lite
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// Environment is the environment the app is deployed in.
+type Environment string
+
+const (
+	EnvironmentDevelopment Environment = "development"
+	EnvironmentProduction  Environment = "production"
+
+	// EnvironmentVariable is the environment variable setting the default environment of the apps, e.g. LITE_ENV=production.
+	EnvironmentVariable = "LITE_ENV"
+
+	debugBodyPreviewSize = 4096
+	debugRequestKey      = "lite.debug.request"
+	debugErrorKey        = "lite.debug.error"
+)
+
+var redactedHeaders = []string{
+	HeaderAuthorization,
+	"Proxy-Authorization",
+	"Cookie",
+}
+
+// SetEnvironment sets the environment of the app. Default is the value of the LITE_ENV environment variable.
+// The dev mode can only be enabled in development.
+func SetEnvironment(environment Environment) Config {
+	return func(s *App) {
+		s.environment = environment
+	}
+}
+
+// SetDevMode enables the development mode, off by default. The error responses contain debug information:
+// the error chain, the stack trace of the panics, the decoded request, the headers and a preview of the body
+// of the request, the matched route and its OpenAPI operation, in an HTML page for the browsers.
+// New panics if the dev mode is enabled in another environment than EnvironmentDevelopment.
+func SetDevMode(enabled bool) Config {
+	return func(s *App) {
+		s.devMode = enabled
+	}
+}
+
+func defaultEnvironment() Environment {
+	return Environment(strings.ToLower(strings.TrimSpace(os.Getenv(EnvironmentVariable))))
+}
+
+// checkDevMode panics if the dev mode is enabled outside development, as it exposes the internals of the app.
+func (s *App) checkDevMode() {
+	if s.devMode && s.environment != EnvironmentDevelopment {
+		panic(fmt.Sprintf("the dev mode can only be enabled in the %s environment, set with SetEnvironment or %s, got %q",
+			EnvironmentDevelopment, EnvironmentVariable, s.environment))
+	}
+}
+
+// debugInfo is the debug information of an error response in dev mode.
+type debugInfo struct {
+	Errors    []string            `json:"errors,omitempty"`
+	Method    string              `json:"method"`
+	Path      string              `json:"path"`
+	Route     string              `json:"route,omitempty"`
+	Operation *openapi3.Operation `json:"operation,omitempty"`
+	Request   any                 `json:"request,omitempty"`
+	Headers   map[string]string   `json:"headers"`
+	Body      string              `json:"body,omitempty"`
+	Trace     []string            `json:"-"`
+}
+
+// debugHTTPError is an HTTPError with the stack trace of a recovered panic and the debug information, rendered in dev mode.
+type debugHTTPError struct {
+	HTTPError
+	Trace []string   `json:"trace,omitempty" xml:"trace>line,omitempty"`
+	Debug *debugInfo `json:"debug,omitempty" xml:"-"`
+}
+
+// setDebugError keeps the error of the request for its debug information, in dev mode.
+func (s *App) setDebugError(c *fiber.Ctx, err error) {
+	if s.devMode {
+		c.Locals(debugErrorKey, err)
+	}
+}
+
+// debugInfo collects the debug information of the request of an error response.
+func (s *App) debugInfo(c *fiber.Ctx, httpError HTTPError) *debugInfo {
+	info := &debugInfo{
+		Method:  c.Method(),
+		Path:    c.Path(),
+		Request: c.Locals(debugRequestKey),
+		Headers: make(map[string]string),
+		Body:    bodyPreview(c.Body()),
+		Trace:   httpError.trace,
+	}
+
+	if err, ok := c.Locals(debugErrorKey).(error); ok {
+		info.Errors = errorChain(err)
+	}
+
+	if routePath, ok := matchedRoute(c); ok {
+		info.Route = c.Method() + " " + routePath
+
+		openAPIPath, _ := parseRoutePath(routePath)
+		if pathItem := s.openAPISpec.Paths.Find(openAPIPath); pathItem != nil {
+			info.Operation = pathItem.GetOperation(c.Method())
+		}
+	}
+
+	redacted := s.redactedHeaders()
+
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		name := string(key)
+		info.Headers[name] = string(value)
+
+		if _, ok := redacted[strings.ToLower(name)]; ok {
+			info.Headers[name] = "[redacted]"
+		}
+	})
+
+	return info
+}
+
+// redactedHeaders returns the lowercase names of the headers redacted from the debug information:
+// the credentials headers and the headers of the security schemes of the OpenAPI spec, e.g. an API key.
+func (s *App) redactedHeaders() map[string]struct{} {
+	headers := make(map[string]struct{}, len(redactedHeaders)+len(s.openAPISpec.Components.SecuritySchemes))
+
+	for _, name := range redactedHeaders {
+		headers[strings.ToLower(name)] = struct{}{}
+	}
+
+	for key, scheme := range s.openAPISpec.Components.SecuritySchemes {
+		// the security schemes are registered under the header they are read from
+		headers[strings.ToLower(key)] = struct{}{}
+
+		if scheme.Value != nil && scheme.Value.In == "header" && scheme.Value.Name != "" {
+			headers[strings.ToLower(scheme.Value.Name)] = struct{}{}
+		}
+	}
+
+	return headers
+}
+
+// errorChain returns the messages of an error and of the errors it wraps.
+func errorChain(err error) []string {
+	var chain []string
+
+	for err != nil {
+		chain = append(chain, fmt.Sprintf("%T: %s", err, err.Error()))
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, wrapped := range joined.Unwrap() {
+				chain = append(chain, errorChain(wrapped)...)
+			}
+
+			break
+		}
+
+		err = errors.Unwrap(err)
+	}
+
+	return chain
+}
+
+// bodyPreview returns the beginning of a request body, or its size if it is binary.
+func bodyPreview(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	preview := body
+	if len(preview) > debugBodyPreviewSize {
+		preview = preview[:debugBodyPreviewSize]
+	}
+
+	if !utf8.Valid(preview) {
+		return fmt.Sprintf("[%d bytes of binary data]", len(body))
+	}
+
+	if len(preview) < len(body) {
+		return fmt.Sprintf("%s… [%d bytes]", preview, len(body))
+	}
+
+	return string(preview)
+}
+
+// acceptsHTML reports whether the request comes from a browser, asking for HTML explicitly.
+func acceptsHTML(c *fiber.Ctx) bool {
+	return strings.Contains(c.Get(HeaderAccept), string(ContentTypeHTML)) &&
+		c.Accepts(string(ContentTypeHTML), string(ContentTypeJSON)) == string(ContentTypeHTML)
+}
+
+// renderDebugPage writes the debug page of an error response, for the browsers in dev mode.
+func (s *App) renderDebugPage(c *fiber.Ctx, httpError HTTPError, info *debugInfo) error {
+	headers := make([]string, 0, len(info.Headers))
+	for name := range info.Headers {
+		headers = append(headers, name)
+	}
+
+	sort.Strings(headers)
+
+	var operation string
+	if info.Operation != nil {
+		data, err := info.Operation.MarshalJSON()
+		if err != nil {
+			return err
+		}
+
+		operation = string(data)
+	}
+
+	var request string
+	if info.Request != nil {
+		request = fmt.Sprintf("%+v", info.Request)
+	}
+
+	c.Set(HeaderContentType, string(ContentTypeHTML)+"; charset=utf-8")
+
+	return debugPageTemplate.Execute(c.Response().BodyWriter(), map[string]any{
+		"Error":       httpError,
+		"Info":        info,
+		"HeaderNames": headers,
+		"Request":     request,
+		"Operation":   operation,
+	})
+}
+
+var debugPageTemplate = template.Must(template.New("debug").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Error.StatusCode}} {{.Error.Title}}</title>
+<style>
+body { font-family: -apple-system, sans-serif; margin: 2rem; color: #1f2328; }
+h1 { color: #cf222e; }
+h2 { border-bottom: 1px solid #d0d7de; padding-bottom: .3rem; }
+pre { background: #f6f8fa; padding: 1rem; overflow-x: auto; }
+th { text-align: left; padding-right: 1rem; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>{{.Error.StatusCode}} {{.Error.Title}}</h1>
+<p>{{.Error.Description}}</p>
+<p><strong>{{.Info.Method}} {{.Info.Path}}</strong>{{if .Info.Route}} matched <code>{{.Info.Route}}</code>{{end}}</p>
+{{if .Error.Violations}}<h2>Violations</h2>
+<table>{{range .Error.Violations}}<tr><th>{{.PropertyPath}}</th><td>{{.Message}}</td></tr>{{end}}</table>{{end}}
+{{if .Info.Errors}}<h2>Error chain</h2>
+<ol>{{range .Info.Errors}}<li><code>{{.}}</code></li>{{end}}</ol>{{end}}
+{{if .Info.Trace}}<h2>Stack trace</h2>
+<pre>{{range .Info.Trace}}{{.}}
+{{end}}</pre>{{end}}
+{{if .Request}}<h2>Decoded request</h2>
+<pre>{{.Request}}</pre>{{end}}
+<h2>Headers</h2>
+<table>{{range .HeaderNames}}<tr><th>{{.}}</th><td>{{index $.Info.Headers .}}</td></tr>{{end}}</table>
+{{if .Info.Body}}<h2>Body</h2>
+<pre>{{.Info.Body}}</pre>{{end}}
+{{if .Operation}}<h2>OpenAPI operation</h2>
+<pre>{{.Operation}}</pre>{{end}}
+</body>
+</html>
+`))
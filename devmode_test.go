@@ -0,0 +1,189 @@
+package lite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type debugOrderRequest struct {
+	ID   uint64         `lite:"params=id"`
+	Body debugOrderBody `lite:"req=body"`
+}
+
+type debugOrderBody struct {
+	Product  string `json:"product" validate:"required"`
+	Quantity int    `json:"quantity"`
+}
+
+type debugKeyRequest struct {
+	Key string `lite:"header=X-Api-Key,isauth,type=apiKey,name=X-Api-Key"`
+}
+
+func newDevModeApp(config ...Config) *App {
+	app := New(append([]Config{SetEnvironment(EnvironmentDevelopment)}, config...)...)
+
+	Put(app, "/orders/:id", func(c *ContextWithRequest[debugOrderRequest]) (string, error) {
+		req, err := c.Requests()
+		if err != nil {
+			return "", err
+		}
+
+		return "", fmt.Errorf("save order %d: %w", req.ID, sql.ErrNoRows)
+	}).OperationID("replaceOrder")
+
+	Get(app, "/keys", func(c *ContextWithRequest[debugKeyRequest]) (string, error) {
+		return "", nil
+	})
+
+	return app
+}
+
+func putOrder(t *testing.T, app *App, body string, accept string) (*http.Response, string) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/orders/42", strings.NewReader(body))
+	req.Header.Set(HeaderContentType, string(ContentTypeJSON))
+	req.Header.Set(HeaderAuthorization, "Bearer secret-token")
+	req.Header.Set("X-Api-Key", "secret-key")
+
+	if accept != "" {
+		req.Header.Set(HeaderAccept, accept)
+	}
+
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp, string(data)
+}
+
+func TestDevMode_Production(t *testing.T) {
+	assert.Panics(t, func() {
+		New(SetEnvironment(EnvironmentProduction), SetDevMode(true))
+	})
+
+	t.Setenv(EnvironmentVariable, "production")
+
+	assert.Panics(t, func() {
+		New(SetDevMode(true))
+	})
+
+	assert.NotPanics(t, func() {
+		New(SetEnvironment(EnvironmentDevelopment), SetDevMode(true))
+	})
+
+	assert.Equal(t, EnvironmentProduction, New().environment)
+	assert.False(t, New().devMode)
+
+	// the environments other than development, e.g. staging or the default one, are not trusted either
+	t.Setenv(EnvironmentVariable, "")
+
+	assert.Panics(t, func() {
+		New(SetDevMode(true))
+	})
+
+	assert.Panics(t, func() {
+		New(SetEnvironment("staging"), SetDevMode(true))
+	})
+
+	t.Setenv(EnvironmentVariable, "development")
+
+	assert.NotPanics(t, func() {
+		New(SetDevMode(true))
+	})
+}
+
+func TestDevMode_Off(t *testing.T) {
+	app := newDevModeApp()
+
+	resp, body := putOrder(t, app, `{"product":"book"}`, "")
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.NotContains(t, body, "debug")
+	assert.NotContains(t, body, "secret-token")
+
+	_, body = putOrder(t, app, `{"product":"book"}`, "text/html")
+	assert.NotContains(t, body, "<html")
+}
+
+func TestDevMode_JSON(t *testing.T) {
+	app := newDevModeApp(SetDevMode(true))
+
+	resp, body := putOrder(t, app, `{"product":"book","quantity":2}`, "")
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	var document struct {
+		Status int `json:"status"`
+		Debug  struct {
+			Errors    []string          `json:"errors"`
+			Method    string            `json:"method"`
+			Path      string            `json:"path"`
+			Route     string            `json:"route"`
+			Operation map[string]any    `json:"operation"`
+			Request   map[string]any    `json:"request"`
+			Headers   map[string]string `json:"headers"`
+			Body      string            `json:"body"`
+		} `json:"debug"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(body), &document), body)
+
+	debug := document.Debug
+
+	assert.Equal(t, http.StatusNotFound, document.Status)
+	assert.Equal(t, []string{
+		"*fmt.wrapError: save order 42: sql: no rows in result set",
+		"*errors.errorString: sql: no rows in result set",
+	}, debug.Errors)
+	assert.Equal(t, http.MethodPut, debug.Method)
+	assert.Equal(t, "/orders/42", debug.Path)
+	assert.Equal(t, "PUT /orders/:id", debug.Route)
+	assert.Equal(t, "replaceOrder", debug.Operation["operationId"])
+	assert.Equal(t, float64(42), debug.Request["ID"])
+	assert.Equal(t, "[redacted]", debug.Headers[HeaderAuthorization])
+	assert.Equal(t, "[redacted]", debug.Headers["X-Api-Key"])
+	assert.Equal(t, `{"product":"book","quantity":2}`, debug.Body)
+	assert.NotContains(t, body, "secret-token")
+	assert.NotContains(t, body, "secret-key")
+}
+
+func TestDevMode_HTML(t *testing.T) {
+	app := newDevModeApp(SetDevMode(true))
+
+	resp, body := putOrder(t, app, `{"product":"<script>"}`, "text/html,application/xhtml+xml,*/*;q=0.8")
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get(HeaderContentType))
+	assert.Contains(t, body, "<h1>404 Resource not found</h1>")
+	assert.Contains(t, body, "<code>PUT /orders/:id</code>")
+	assert.Contains(t, body, "save order 42: sql: no rows in result set")
+	assert.Contains(t, body, "replaceOrder")
+	assert.Contains(t, body, "&lt;script&gt;")
+	assert.NotContains(t, body, "<script>")
+	assert.NotContains(t, body, "secret-token")
+
+	// The validation errors show the decoded request and the violations.
+	_, body = putOrder(t, app, `{"quantity":2}`, "text/html")
+	assert.Contains(t, body, "<h2>Violations</h2>")
+	assert.Contains(t, body, "Quantity:2")
+}
+
+func TestBodyPreview(t *testing.T) {
+	assert.Equal(t, "", bodyPreview(nil))
+	assert.Equal(t, "name=john", bodyPreview([]byte("name=john")))
+	assert.Equal(t, "[3 bytes of binary data]", bodyPreview([]byte{0xff, 0xfe, 0x00}))
+
+	preview := bodyPreview([]byte(strings.Repeat("a", debugBodyPreviewSize+10)))
+	assert.True(t, strings.HasSuffix(preview, fmt.Sprintf("… [%d bytes]", debugBodyPreviewSize+10)))
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package lite
+
+import "net"
+
+// watchDisconnect does not watch the connection on this platform: the context of the handlers
+// is not canceled when the client disconnects.
+func watchDisconnect(net.Conn, func()) (stop func()) {
+	return func() {}
+}
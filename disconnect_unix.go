@@ -0,0 +1,61 @@
+//go:build unix
+
+package lite
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// watchDisconnect calls disconnected when the client resets the connection before stop is called.
+// It peeks at the socket without consuming the bytes, so a request pipelined on the connection
+// stops the watch and is read as usual. The end of the stream stops the watch too: the client may only have closed
+// its half of the connection, and still be waiting for the response.
+func watchDisconnect(conn net.Conn, disconnected func()) (stop func()) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+
+	syscallConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return func() {}
+	}
+
+	rawConn, err := syscallConn.SyscallConn()
+	if err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		buffer := make([]byte, 1)
+
+		_ = rawConn.Read(func(fd uintptr) bool {
+			_, _, err := syscall.Recvfrom(int(fd), buffer, syscall.MSG_PEEK)
+			if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EINTR) {
+				// wait until the socket is readable
+				return false
+			}
+
+			// a pipelined request or the end of the stream are not errors, the connection is still writable
+			if err != nil {
+				disconnected()
+			}
+
+			return true
+		})
+	}()
+
+	return func() {
+		// the read deadline wakes up the watch, and is then reset for the next requests of the connection
+		_ = conn.SetReadDeadline(time.Unix(1, 0))
+		<-done
+		_ = conn.SetReadDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,126 @@
+package lite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorMapping converts an error into an HTTPError, or returns false if it does not match the error.
+type errorMapping func(err error) (HTTPError, bool)
+
+// errorMapper holds the error mappings of an app, shared with its groups.
+type errorMapper struct {
+	mu       sync.RWMutex
+	mappings []errorMapping
+}
+
+func newErrorMapper() *errorMapper {
+	mapper := &errorMapper{}
+
+	mapper.add(isMapping(sql.ErrNoRows, func(error) HTTPError {
+		return NewNotFoundError("The resource does not exist")
+	}))
+	mapper.add(isMapping(context.DeadlineExceeded, func(error) HTTPError {
+		return NewError(StatusGatewayTimeout, "The request timed out")
+	}))
+
+	return mapper
+}
+
+func (m *errorMapper) add(mapping errorMapping) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.mappings = append(m.mappings, mapping)
+}
+
+// resolve converts the error with the last registered mapping matching it.
+func (m *errorMapper) resolve(err error) (HTTPError, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.mappings) - 1; i >= 0; i-- {
+		if httpError, ok := m.mappings[i](err); ok {
+			return httpError, true
+		}
+	}
+
+	return HTTPError{}, false
+}
+
+func isMapping(target error, mapper func(error) HTTPError) errorMapping {
+	return func(err error) (HTTPError, bool) {
+		if !errors.Is(err, target) {
+			return HTTPError{}, false
+		}
+
+		return mapper(err), true
+	}
+}
+
+// MapError maps the errors returned by the handlers matching target with errors.Is to the HTTPError returned by mapper.
+// The mappings registered last take precedence, so the default mappings of sql.ErrNoRows (404)
+// and context.DeadlineExceeded (504) can be overridden.
+func MapError(app *App, target error, mapper func(err error) HTTPError) {
+	app.errorMapper.add(isMapping(target, mapper))
+}
+
+// MapErrorType maps the errors returned by the handlers matching the error type T with errors.As
+// to the HTTPError returned by mapper.
+func MapErrorType[T error](app *App, mapper func(err T) HTTPError) {
+	app.errorMapper.add(func(err error) (HTTPError, bool) {
+		var target T
+		if !errors.As(err, &target) {
+			return HTTPError{}, false
+		}
+
+		return mapper(target), true
+	})
+}
+
+// resolveError converts an error returned by a handler into an HTTPError,
+// as is or with the error mappings, or returns false if the error is not mapped.
+func (s *App) resolveError(err error) (HTTPError, bool) {
+	if httpError, ok := asHTTPError(err); ok {
+		return httpError, true
+	}
+
+	return s.errorMapper.resolve(err)
+}
+
+// handleError writes the response of an error: the body of a TypedError, the resolved HTTPError,
+// or an internal server error for the errors which are not mapped, without their cause.
+func (s *App) handleError(c *fiber.Ctx, err error) error {
+	s.setDebugError(c, err)
+
+	var typed typedError
+	if errors.As(err, &typed) {
+		return s.renderTypedError(c, typed)
+	}
+
+	if httpError, ok := s.resolveError(err); ok {
+		return s.renderError(c, httpError)
+	}
+
+	return s.renderError(c, NewInternalServerError())
+}
+
+// errorHandler is the fiber error handler of the app. It renders the errors returned outside the lite handlers,
+// by the middlewares or by fiber itself (unknown route, request body too large...), like the handler errors.
+func (s *App) errorHandler(c *fiber.Ctx, err error) error {
+	var fiberError *fiber.Error
+	if errors.As(err, &fiberError) {
+		s.setDebugError(c, err)
+
+		return s.renderError(c, newStatusError(fiberError.Code, fiberError.Message))
+	}
+
+	s.logger.ErrorContext(c.Context(), "error", slog.Any("error", err))
+
+	return s.handleError(c, err)
+}
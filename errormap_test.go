@@ -0,0 +1,284 @@
+package lite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	fiberrecover "github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errOutOfStock = errors.New("out of stock: warehouse 42 is empty")
+
+type quotaError struct {
+	Limit int
+}
+
+func (e *quotaError) Error() string {
+	return fmt.Sprintf("quota of %d requests exceeded", e.Limit)
+}
+
+func TestMapError(t *testing.T) {
+	app := New()
+	orders := Group(app, "/orders")
+
+	MapError(app, errOutOfStock, func(error) HTTPError {
+		return NewConflictError("The product is out of stock")
+	})
+	MapErrorType(orders, func(err *quotaError) HTTPError {
+		return NewError(http.StatusTooManyRequests, fmt.Sprintf("At most %d orders per day", err.Limit))
+	})
+
+	var handlerErr error
+
+	Get(orders, "/", func(c *ContextNoRequest) (string, error) {
+		return "", handlerErr
+	})
+
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		description string
+	}{
+		{
+			name:        "sentinel",
+			err:         fmt.Errorf("order 1: %w", errOutOfStock),
+			status:      http.StatusConflict,
+			description: "The product is out of stock",
+		},
+		{
+			name:        "type",
+			err:         fmt.Errorf("order 1: %w", &quotaError{Limit: 3}),
+			status:      http.StatusTooManyRequests,
+			description: "At most 3 orders per day",
+		},
+		{
+			name:        "no rows",
+			err:         fmt.Errorf("find order: %w", sql.ErrNoRows),
+			status:      http.StatusNotFound,
+			description: "The resource does not exist",
+		},
+		{
+			name:        "deadline exceeded",
+			err:         context.DeadlineExceeded,
+			status:      http.StatusGatewayTimeout,
+			description: "The request timed out",
+		},
+		{
+			name:        "http error",
+			err:         NewForbiddenError("Not your order"),
+			status:      http.StatusForbidden,
+			description: "Not your order",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerErr = tt.err
+
+			resp, document := getJSON(t, app, "/orders")
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.description, document["description"])
+		})
+	}
+}
+
+func TestMapError_Unmapped(t *testing.T) {
+	app := New()
+
+	Get(app, "/orders", func(c *ContextNoRequest) (string, error) {
+		return "", errors.New("dial tcp 10.0.0.12:5432: connection refused")
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/orders", nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.NotContains(t, string(body), "10.0.0.12")
+}
+
+func TestMapError_Precedence(t *testing.T) {
+	app := New()
+
+	MapError(app, sql.ErrNoRows, func(error) HTTPError {
+		return NewNotFoundError("The order does not exist")
+	})
+
+	httpError, ok := app.resolveError(sql.ErrNoRows)
+	require.True(t, ok)
+	assert.Equal(t, "The order does not exist", httpError.Description)
+
+	_, ok = app.resolveError(errOutOfStock)
+	assert.False(t, ok)
+}
+
+type renderRequest struct {
+	ID   uint64      `lite:"params=id"`
+	Body renderInput `lite:"req=body"`
+}
+
+type renderInput struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type brokenResponse struct{}
+
+func (brokenResponse) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("broken encoder")
+}
+
+func newRenderApp(config ...Config) *App {
+	app := New(config...)
+
+	Use(app, fiberrecover.New())
+	Use(app, func(c *fiber.Ctx) error {
+		if c.Get(HeaderAuthorization) == "invalid" {
+			return fiber.ErrUnauthorized
+		}
+
+		return c.Next()
+	})
+
+	Post(app, "/items/:id", func(c *ContextWithRequest[renderRequest]) (string, error) {
+		_, err := c.Requests()
+
+		return "", err
+	})
+
+	Get(app, "/broken", func(c *ContextNoRequest) (brokenResponse, error) {
+		return brokenResponse{}, nil
+	})
+
+	Get(app, "/panic", func(c *ContextNoRequest) (string, error) {
+		panic("nil map")
+	})
+
+	return app
+}
+
+func TestErrorHandler(t *testing.T) {
+	app := newRenderApp()
+
+	tests := []struct {
+		name          string
+		method        string
+		target        string
+		body          string
+		authorization string
+		status        int
+		context       string
+	}{
+		{
+			name:    "decoding",
+			method:  http.MethodPost,
+			target:  "/items/abc",
+			body:    `{"name":"book"}`,
+			status:  http.StatusBadRequest,
+			context: "DeserializationError",
+		},
+		{
+			name:    "malformed body",
+			method:  http.MethodPost,
+			target:  "/items/1",
+			body:    `{"name":`,
+			status:  http.StatusBadRequest,
+			context: "DeserializationError",
+		},
+		{
+			name:    "validation",
+			method:  http.MethodPost,
+			target:  "/items/1",
+			body:    `{}`,
+			status:  http.StatusBadRequest,
+			context: "ConstraintViolationList",
+		},
+		{
+			name:    "serialization",
+			method:  http.MethodGet,
+			target:  "/broken",
+			status:  http.StatusInternalServerError,
+			context: "SerializationError",
+		},
+		{
+			name:    "panic",
+			method:  http.MethodGet,
+			target:  "/panic",
+			status:  http.StatusInternalServerError,
+			context: "InternalServerError",
+		},
+		{
+			name:    "unknown route",
+			method:  http.MethodGet,
+			target:  "/unknown",
+			status:  http.StatusNotFound,
+			context: "NotFound",
+		},
+		{
+			name:          "middleware",
+			method:        http.MethodGet,
+			target:        "/broken",
+			authorization: "invalid",
+			status:        http.StatusUnauthorized,
+			context:       "AuthenticationFailure",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req.Header.Set(HeaderContentType, string(ContentTypeJSON))
+
+			if tt.authorization != "" {
+				req.Header.Set(HeaderAuthorization, tt.authorization)
+			}
+
+			resp, err := app.app.Test(req)
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			var httpError HTTPError
+			require.NoError(t, json.Unmarshal(body, &httpError), string(body))
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, string(ContentTypeJSON), resp.Header.Get(HeaderContentType))
+			assert.Equal(t, tt.status, httpError.Status)
+			assert.Equal(t, "/api/contexts/"+tt.context, httpError.Context)
+		})
+	}
+}
+
+func TestErrorHandler_Problem(t *testing.T) {
+	app := newRenderApp(SetErrorFormat(ErrorFormatProblem))
+
+	for _, target := range []string{"/unknown", "/broken", "/panic"} {
+		resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		require.NoError(t, err)
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		var problem ProblemDetails
+		require.NoError(t, json.Unmarshal(body, &problem), string(body))
+
+		assert.Equal(t, string(ContentTypeProblemJSON), resp.Header.Get(HeaderContentType), target)
+		assert.Equal(t, resp.StatusCode, problem.Status, target)
+		assert.Equal(t, target, problem.Instance)
+	}
+}
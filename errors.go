@@ -1,6 +1,8 @@
 package lite
 
 import (
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"net/http"
 )
@@ -11,19 +13,22 @@ type Error interface {
 }
 
 type Violation struct {
-	PropertyPath string         `json:"propertyPath,omitempty"`
-	Message      string         `json:"message,omitempty"`
-	Code         string         `json:"code,omitempty"`
-	More         map[string]any `json:"more,omitempty"`
+	PropertyPath string         `json:"propertyPath,omitempty" xml:"propertyPath,omitempty"`
+	Message      string         `json:"message,omitempty" xml:"message,omitempty"`
+	Code         string         `json:"code,omitempty" xml:"code,omitempty"`
+	More         map[string]any `json:"more,omitempty" xml:"-"`
 }
 
 type HTTPError struct {
-	Context     string      `json:"@context,omitempty"`
-	Type        string      `json:"@type,omitempty"`
-	Status      int         `json:"status,omitempty"`
-	Title       string      `json:"title,omitempty"`
-	Description string      `json:"description,omitempty"`
-	Violations  []Violation `json:"violations,omitempty"`
+	XMLName     xml.Name    `json:"-" xml:"error"`
+	Context     string      `json:"@context,omitempty" xml:"context,attr,omitempty"`
+	Type        string      `json:"@type,omitempty" xml:"type,attr,omitempty"`
+	Status      int         `json:"status,omitempty" xml:"status,omitempty"`
+	Title       string      `json:"title,omitempty" xml:"title,omitempty"`
+	Description string      `json:"description,omitempty" xml:"description,omitempty"`
+	Violations  []Violation `json:"violations,omitempty" xml:"violations>violation,omitempty"`
+
+	trace []string // stack trace of a recovered panic, rendered in dev mode
 }
 
 func (e HTTPError) Error() string {
@@ -257,10 +262,14 @@ func (e HTTPError) Descriptions() string {
 		return "Not Found"
 	case StatusConflict:
 		return "Conflict"
+	case StatusRequestEntityTooLarge:
+		return "Request Entity Too Large"
 	case StatusInternalServerError:
 		return "Internal Server Error"
 	case StatusServiceUnavailable:
 		return "Service Unavailable"
+	case StatusGatewayTimeout:
+		return "Gateway Timeout"
 	default:
 		return "Unknown Error"
 	}
@@ -271,6 +280,9 @@ var DefaultErrorResponses = map[int]HTTPError{
 	StatusInternalServerError: NewInternalServerError("Internal Server Error"),
 }
 
+// DefaultErrorContentTypeResponses were the content types of the documented error responses.
+//
+// Deprecated: the error responses of a route are documented in JSON and in the content type of the route response.
 var DefaultErrorContentTypeResponses = []string{
 	"application/json",
 	"application/xml",
@@ -388,3 +400,62 @@ func (e ServiceUnavailableError) Error() string {
 func (e ServiceUnavailableError) StatusCode() int {
 	return StatusServiceUnavailable
 }
+
+// newStatusError returns the HTTPError of a status code, with the JSON-LD context of the status when there is one.
+func newStatusError(status int, description string) HTTPError {
+	switch status {
+	case StatusBadRequest:
+		return NewBadRequestError(description)
+	case StatusUnauthorized:
+		return NewUnauthorizedError(description)
+	case StatusForbidden:
+		return NewForbiddenError(description)
+	case StatusNotFound:
+		return NewNotFoundError(description)
+	case StatusConflict:
+		return NewConflictError(description)
+	case StatusInternalServerError:
+		return NewInternalServerError(description)
+	case StatusServiceUnavailable:
+		return NewServiceUnavailableError(description)
+	default:
+		return NewError(status, description)
+	}
+}
+
+// asHTTPError converts an HTTPError, one of the typed errors (BadRequestError, NotFoundError...)
+// or any other Error into an HTTPError, or returns false if err has no status code.
+func asHTTPError(err error) (HTTPError, bool) {
+	var httpError HTTPError
+	if errors.As(err, &httpError) {
+		return httpError, true
+	}
+
+	var statusError Error
+	if !errors.As(err, &statusError) {
+		return HTTPError{}, false
+	}
+
+	switch typedError := statusError.(type) {
+	case BadRequestError:
+		httpError = HTTPError(typedError)
+	case UnauthorizedError:
+		httpError = HTTPError(typedError)
+	case ForbiddenError:
+		httpError = HTTPError(typedError)
+	case NotFoundError:
+		httpError = HTTPError(typedError)
+	case ConflictError:
+		httpError = HTTPError(typedError)
+	case InternalServerError:
+		httpError = HTTPError(typedError)
+	case ServiceUnavailableError:
+		httpError = HTTPError(typedError)
+	default:
+		return newStatusError(statusError.StatusCode(), statusError.Error()), true
+	}
+
+	httpError.Status = statusError.StatusCode()
+
+	return httpError, true
+}
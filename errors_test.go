@@ -1,6 +1,8 @@
 package lite
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 )
@@ -301,6 +303,20 @@ func TestErrorDescriptions(t *testing.T) {
 			},
 			expected: "Service Unavailable",
 		},
+		{
+			name: "Request Entity Too Large Description",
+			response: HTTPError{
+				Status: StatusRequestEntityTooLarge,
+			},
+			expected: "Request Entity Too Large",
+		},
+		{
+			name: "Gateway Timeout Description",
+			response: HTTPError{
+				Status: StatusGatewayTimeout,
+			},
+			expected: "Gateway Timeout",
+		},
 		{
 			name: "Unknown Error Description",
 			response: HTTPError{
@@ -379,3 +395,90 @@ func TestDefaultErrorContentTypeResponses(t *testing.T) {
 		}
 	}
 }
+
+type teapotError struct{}
+
+func (teapotError) Error() string {
+	return "I'm a teapot"
+}
+
+func (teapotError) StatusCode() int {
+	return http.StatusTeapot
+}
+
+func TestAsHTTPError(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		ok          bool
+		status      int
+		context     string
+		description string
+	}{
+		{
+			name:        "HTTPError",
+			err:         fmt.Errorf("find user: %w", NewNotFoundError("User not found")),
+			ok:          true,
+			status:      StatusNotFound,
+			context:     "/api/contexts/NotFound",
+			description: "User not found",
+		},
+		{
+			name: "typed error without status",
+			err: BadRequestError{
+				Context:     "/api/contexts/DeserializationError",
+				Description: "Failed to parse int",
+			},
+			ok:          true,
+			status:      StatusBadRequest,
+			context:     "/api/contexts/DeserializationError",
+			description: "Failed to parse int",
+		},
+		{
+			name:        "serialization error",
+			err:         newSerializationError("unsupported type: chan"),
+			ok:          true,
+			status:      StatusInternalServerError,
+			context:     "/api/contexts/SerializationError",
+			description: "unsupported type: chan",
+		},
+		{
+			name:        "error with a status code",
+			err:         teapotError{},
+			ok:          true,
+			status:      http.StatusTeapot,
+			context:     "/api/contexts/Error",
+			description: "I'm a teapot",
+		},
+		{
+			name: "error without a status code",
+			err:  errors.New("connection refused"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpError, ok := asHTTPError(tt.err)
+
+			if ok != tt.ok {
+				t.Fatalf("asHTTPError() ok = %v, want %v", ok, tt.ok)
+			}
+
+			if !ok {
+				return
+			}
+
+			if httpError.Status != tt.status {
+				t.Errorf("asHTTPError().Status = %d, want %d", httpError.Status, tt.status)
+			}
+
+			if httpError.Context != tt.context {
+				t.Errorf("asHTTPError().Context = %s, want %s", httpError.Context, tt.context)
+			}
+
+			if httpError.Description != tt.description {
+				t.Errorf("asHTTPError().Description = %s, want %s", httpError.Description, tt.description)
+			}
+		})
+	}
+}
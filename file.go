@@ -0,0 +1,403 @@
+package lite
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+var fileType = reflect.TypeOf(File{})
+
+var formatMediaType = mime.FormatMediaType
+
+// File is a response body streamed to the client as a file download.
+// It honors the Range and If-Range request headers with partial content responses.
+type File struct {
+	name      string
+	mediaType string
+	modTime   time.Time
+	size      int64
+	content   io.ReadSeeker
+	inline    bool
+}
+
+// NewFile creates a File from the file at the given path.
+func NewFile(path string) (File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return File{}, err
+	}
+
+	if info.IsDir() {
+		_ = f.Close()
+
+		return File{}, errors.New(path + " is a directory")
+	}
+
+	return newFile(info.Name(), f, info.Size(), info.ModTime()), nil
+}
+
+// NewFileFromReader creates a File named name from an io.ReadSeeker.
+// If content is an io.Closer, it is closed once the response is sent.
+func NewFileFromReader(name string, content io.ReadSeeker) (File, error) {
+	size, err := content.Seek(0, io.SeekEnd)
+	if err != nil {
+		return File{}, err
+	}
+
+	_, err = content.Seek(0, io.SeekStart)
+	if err != nil {
+		return File{}, err
+	}
+
+	return newFile(name, content, size, time.Time{}), nil
+}
+
+// NewFileFromFS creates a File from the named file of fsys.
+func NewFileFromFS(fsys fs.FS, name string) (File, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return File{}, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+
+		return File{}, err
+	}
+
+	if info.IsDir() {
+		_ = f.Close()
+
+		return File{}, errors.New(name + " is a directory")
+	}
+
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		defer f.Close()
+
+		data, err := io.ReadAll(f)
+		if err != nil {
+			return File{}, err
+		}
+
+		content = bytes.NewReader(data)
+	}
+
+	return newFile(info.Name(), content, info.Size(), info.ModTime()), nil
+}
+
+func newFile(name string, content io.ReadSeeker, size int64, modTime time.Time) File {
+	mediaType := mime.TypeByExtension(filepath.Ext(name))
+	if mediaType == "" {
+		mediaType = string(ContentTypeOctetStream)
+	}
+
+	return File{
+		name:      name,
+		mediaType: mediaType,
+		modTime:   modTime,
+		size:      size,
+		content:   content,
+	}
+}
+
+// WithName sets the file name sent in the Content-Disposition header.
+func (f File) WithName(name string) File {
+	f.name = name
+
+	return f
+}
+
+// WithMediaType sets the media type sent in the Content-Type header.
+func (f File) WithMediaType(mediaType string) File {
+	f.mediaType = mediaType
+
+	return f
+}
+
+// WithModTime sets the modification time sent in the Last-Modified header.
+func (f File) WithModTime(modTime time.Time) File {
+	f.modTime = modTime
+
+	return f
+}
+
+// Inline displays the file in the browser instead of downloading it.
+func (f File) Inline() File {
+	f.inline = true
+
+	return f
+}
+
+// Name returns the file name.
+func (f File) Name() string {
+	return f.name
+}
+
+// MediaType returns the file media type.
+func (f File) MediaType() string {
+	return f.mediaType
+}
+
+// ModTime returns the file modification time.
+func (f File) ModTime() time.Time {
+	return f.modTime
+}
+
+// Size returns the file size in bytes.
+func (f File) Size() int64 {
+	return f.size
+}
+
+type fileBody struct {
+	io.Reader
+	io.Closer
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// writeFile streams the file to the client, with a partial content response if a satisfiable range is requested.
+func writeFile(c *fiber.Ctx, f File, app *App) error {
+	if f.content == nil {
+		return app.renderError(c, NewInternalServerError("The file has no content"))
+	}
+
+	var closer io.Closer = nopCloser{}
+	if contentCloser, ok := f.content.(io.Closer); ok {
+		closer = contentCloser
+	}
+
+	disposition := "attachment"
+	if f.inline {
+		disposition = "inline"
+	}
+
+	if f.name != "" {
+		disposition = contentDisposition(disposition, f.name)
+	}
+
+	c.Set(HeaderContentType, f.mediaType)
+	c.Set(HeaderContentDisposition, disposition)
+	c.Set(HeaderAcceptRanges, "bytes")
+
+	if !f.modTime.IsZero() {
+		c.Set(HeaderLastModified, f.modTime.UTC().Format(http.TimeFormat))
+	}
+
+	start, length := int64(0), f.size
+
+	rangeHeader := c.Get(HeaderRange)
+	if rangeHeader != "" && checkIfRange(c.Get(HeaderIfRange), f.modTime) {
+		rangeStart, rangeLength, ok, satisfiable := parseRange(rangeHeader, f.size)
+
+		switch {
+		case !satisfiable:
+			_ = closer.Close()
+
+			// the error replaces the file, so its headers must not describe it
+			for _, header := range []string{HeaderETag, HeaderContentDisposition, HeaderLastModified} {
+				c.Response().Header.Del(header)
+			}
+
+			c.Set(HeaderContentRange, "bytes */"+strconv.FormatInt(f.size, 10))
+
+			return app.renderError(c, NewError(StatusRequestedRangeNotSatisfiable, "The requested range is not satisfiable"))
+		case ok:
+			start, length = rangeStart, rangeLength
+
+			c.Set(HeaderContentRange, "bytes "+strconv.FormatInt(start, 10)+"-"+
+				strconv.FormatInt(start+length-1, 10)+"/"+strconv.FormatInt(f.size, 10))
+			c.Status(StatusPartialContent)
+		}
+	}
+
+	if _, err := f.content.Seek(start, io.SeekStart); err != nil {
+		_ = closer.Close()
+
+		return app.renderError(c, NewInternalServerError("Failed to read the file"))
+	}
+
+	c.Context().SetBodyStream(fileBody{Reader: io.LimitReader(f.content, length), Closer: closer}, int(length))
+
+	return nil
+}
+
+// contentDisposition formats the Content-Disposition header of a file named name.
+// If the name cannot be formatted, it falls back to the name with its special characters replaced.
+func contentDisposition(disposition, name string) string {
+	if formatted := formatMediaType(disposition, map[string]string{"filename": name}); formatted != "" {
+		return formatted
+	}
+
+	sanitized := strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' || r == '"' || r == '\\' {
+			return '_'
+		}
+
+		return r
+	}, name)
+
+	return disposition + `; filename="` + sanitized + `"`
+}
+
+// checkIfRange reports whether the Range header applies according to the If-Range header.
+// Files have no entity tag, so only a date validator can match, and only if it is the exact modification date.
+func checkIfRange(ifRange string, modTime time.Time) bool {
+	if ifRange == "" {
+		return true
+	}
+
+	if modTime.IsZero() {
+		return false
+	}
+
+	date, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+
+	return modTime.Truncate(time.Second).Equal(date)
+}
+
+// parseRange parses a single byte range of a Range header.
+// ok is false when the range must be ignored (invalid or multiple ranges),
+// satisfiable is false when the range does not overlap the file.
+func parseRange(header string, size int64) (start, length int64, ok, satisfiable bool) {
+	const prefix = "bytes="
+
+	if !strings.HasPrefix(header, prefix) {
+		return 0, 0, false, true
+	}
+
+	spec := strings.TrimSpace(strings.TrimPrefix(header, prefix))
+	if spec == "" || strings.Contains(spec, ",") {
+		return 0, 0, false, true
+	}
+
+	startStr, endStr, found := strings.Cut(spec, "-")
+	if !found {
+		return 0, 0, false, true
+	}
+
+	startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+	if startStr == "" {
+		// Suffix range: the last N bytes.
+		suffix, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || suffix < 0 {
+			return 0, 0, false, true
+		}
+
+		if suffix == 0 || size == 0 {
+			return 0, 0, false, false
+		}
+
+		if suffix > size {
+			suffix = size
+		}
+
+		return size - suffix, suffix, true, true
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, false, true
+	}
+
+	if start >= size {
+		return 0, 0, false, false
+	}
+
+	end := size - 1
+
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false, true
+		}
+
+		if end >= size {
+			end = size - 1
+		}
+	}
+
+	return start, end - start + 1, true, true
+}
+
+// setFileResponseSchema documents a File response as binary content with its download and range headers.
+func setFileResponseSchema(s *App, operation *openapi3.Operation, resContentType string, statusCode int) {
+	content := openapi3.NewContentWithSchema(openapi3.NewStringSchema().WithFormat("binary"), []string{resContentType})
+
+	headers := openapi3.Headers{
+		HeaderContentDisposition: fileHeader("attachment; filename=\"file.txt\""),
+		HeaderAcceptRanges:       fileHeader("bytes"),
+		HeaderLastModified:       fileHeader(""),
+	}
+
+	response := openapi3.NewResponse().WithDescription("OK").WithContent(content)
+	response.Headers = headers
+
+	operation.AddResponse(statusCode, response)
+
+	partialHeaders := openapi3.Headers{
+		HeaderContentRange: fileHeader("bytes 0-99/1000"),
+	}
+
+	for name, header := range headers {
+		partialHeaders[name] = header
+	}
+
+	partial := openapi3.NewResponse().WithDescription(StatusMessage(StatusPartialContent)).WithContent(content)
+	partial.Headers = partialHeaders
+
+	operation.AddResponse(StatusPartialContent, partial)
+
+	operation.AddResponse(StatusRequestedRangeNotSatisfiable, s.errorResponse(StatusRequestedRangeNotSatisfiable, "application/json"))
+
+	operation.AddParameter(openapi3.NewHeaderParameter(HeaderRange).
+		WithSchema(openapi3.NewStringSchema()).
+		WithDescription("Byte range to download, e.g. bytes=0-1023"))
+	operation.AddParameter(openapi3.NewHeaderParameter(HeaderIfRange).
+		WithSchema(openapi3.NewStringSchema()).
+		WithDescription("Only honor the Range header if the file was not modified since this date"))
+}
+
+func fileHeader(example string) *openapi3.HeaderRef {
+	schema := openapi3.NewStringSchema()
+	if example != "" {
+		schema.Example = example
+	}
+
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Schema: openapi3.NewSchemaRef("", schema),
+			},
+		},
+	}
+}
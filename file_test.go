@@ -0,0 +1,256 @@
+package lite
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFileApp(t *testing.T, content string, modTime time.Time) *App {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	require.NoError(t, os.Chtimes(path, modTime, modTime))
+
+	app := New()
+
+	Get(app, "/report", func(c *ContextNoRequest) (File, error) {
+		return NewFile(path)
+	})
+
+	return app
+}
+
+func TestFile_Download(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	app := newFileApp(t, "hello world", modTime)
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/report", nil))
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "hello world", string(body))
+	assert.Contains(t, resp.Header.Get(HeaderContentType), "text/plain")
+	assert.Equal(t, `attachment; filename=report.txt`, resp.Header.Get(HeaderContentDisposition))
+	assert.Equal(t, "bytes", resp.Header.Get(HeaderAcceptRanges))
+	assert.Equal(t, modTime.Format(http.TimeFormat), resp.Header.Get(HeaderLastModified))
+}
+
+func TestFile_Range(t *testing.T) {
+	modTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	app := newFileApp(t, "hello world", modTime)
+
+	tests := []struct {
+		name         string
+		rangeHeader  string
+		ifRange      string
+		status       int
+		body         string
+		contentRange string
+	}{
+		{"range", "bytes=0-4", "", StatusPartialContent, "hello", "bytes 0-4/11"},
+		{"open range", "bytes=6-", "", StatusPartialContent, "world", "bytes 6-10/11"},
+		{"suffix range", "bytes=-3", "", StatusPartialContent, "rld", "bytes 8-10/11"},
+		{"multiple ranges are ignored", "bytes=0-1,3-4", "", StatusOK, "hello world", ""},
+		{"matching if-range", "bytes=0-4", modTime.Format(http.TimeFormat), StatusPartialContent, "hello", "bytes 0-4/11"},
+		{"stale if-range", "bytes=0-4", modTime.Add(-time.Hour).Format(http.TimeFormat), StatusOK, "hello world", ""},
+		{"newer if-range", "bytes=0-4", modTime.Add(time.Hour).Format(http.TimeFormat), StatusOK, "hello world", ""},
+		{"etag if-range", "bytes=0-4", `"etag"`, StatusOK, "hello world", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/report", nil)
+			req.Header.Set(HeaderRange, tt.rangeHeader)
+
+			if tt.ifRange != "" {
+				req.Header.Set(HeaderIfRange, tt.ifRange)
+			}
+
+			resp, err := app.app.Test(req)
+			require.NoError(t, err)
+
+			body, _ := io.ReadAll(resp.Body)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, tt.body, string(body))
+			assert.Equal(t, tt.contentRange, resp.Header.Get(HeaderContentRange))
+		})
+	}
+}
+
+func TestFile_RangeNotSatisfiable(t *testing.T) {
+	app := newFileApp(t, "hello world", time.Now())
+
+	req := httptest.NewRequest(http.MethodGet, "/report", nil)
+	req.Header.Set(HeaderRange, "bytes=20-30")
+
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusRequestedRangeNotSatisfiable, resp.StatusCode)
+	assert.Equal(t, "bytes */11", resp.Header.Get(HeaderContentRange))
+	assert.Empty(t, resp.Header.Get(HeaderContentDisposition))
+	assert.Empty(t, resp.Header.Get(HeaderLastModified))
+	assert.Contains(t, resp.Header.Get(HeaderContentType), "json")
+}
+
+func TestContentDisposition(t *testing.T) {
+	assert.Equal(t, `attachment; filename="a b.txt"`, contentDisposition("attachment", "a b.txt"))
+
+	realFormatMediaType := formatMediaType
+	defer func() {
+		formatMediaType = realFormatMediaType
+	}()
+
+	formatMediaType = func(string, map[string]string) string {
+		return ""
+	}
+
+	assert.Equal(t, `inline; filename="r_sum_ _a_b_.txt"`, contentDisposition("inline", "résumé \"a\\b\".txt"))
+}
+
+func TestFile_FromReaderAndFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"docs/guide.pdf": &fstest.MapFile{Data: []byte("%PDF-1.4"), ModTime: time.Now()},
+	}
+
+	app := New()
+
+	Get(app, "/guide", func(c *ContextNoRequest) (File, error) {
+		return NewFileFromFS(fsys, "docs/guide.pdf")
+	})
+
+	Get(app, "/data", func(c *ContextNoRequest) (File, error) {
+		f, err := NewFileFromReader("data", bytes.NewReader([]byte("raw data")))
+		if err != nil {
+			return File{}, err
+		}
+
+		return f.WithName("data.bin").WithMediaType("application/x-custom").Inline(), nil
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/guide", nil))
+	require.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "%PDF-1.4", string(body))
+	assert.Equal(t, "application/pdf", resp.Header.Get(HeaderContentType))
+
+	resp, err = app.app.Test(httptest.NewRequest(http.MethodGet, "/data", nil))
+	require.NoError(t, err)
+
+	body, _ = io.ReadAll(resp.Body)
+	assert.Equal(t, "raw data", string(body))
+	assert.Equal(t, "application/x-custom", resp.Header.Get(HeaderContentType))
+	assert.Equal(t, "inline; filename=data.bin", resp.Header.Get(HeaderContentDisposition))
+	assert.Empty(t, resp.Header.Get(HeaderLastModified))
+}
+
+func TestNewFile_Error(t *testing.T) {
+	_, err := NewFile(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+
+	_, err = NewFile(t.TempDir())
+	assert.Error(t, err)
+
+	_, err = NewFileFromFS(fstest.MapFS{}, "missing.txt")
+	assert.Error(t, err)
+}
+
+func TestFile_Accessors(t *testing.T) {
+	modTime := time.Now()
+
+	f, err := NewFileFromReader("a.json", bytes.NewReader([]byte("{}")))
+	require.NoError(t, err)
+
+	f = f.WithModTime(modTime)
+
+	assert.Equal(t, "a.json", f.Name())
+	assert.Equal(t, "application/json", f.MediaType())
+	assert.Equal(t, modTime, f.ModTime())
+	assert.Equal(t, int64(2), f.Size())
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		header      string
+		start       int64
+		length      int64
+		ok          bool
+		satisfiable bool
+	}{
+		{"bytes=0-9", 0, 10, true, true},
+		{"bytes=90-", 90, 10, true, true},
+		{"bytes=90-200", 90, 10, true, true},
+		{"bytes=-200", 0, 100, true, true},
+		{"bytes=100-", 0, 0, false, false},
+		{"bytes=-0", 0, 0, false, false},
+		{"bytes=5-1", 0, 0, false, true},
+		{"bytes=a-b", 0, 0, false, true},
+		{"items=0-9", 0, 0, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			start, length, ok, satisfiable := parseRange(tt.header, 100)
+
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.length, length)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.satisfiable, satisfiable)
+		})
+	}
+}
+
+func TestFile_OpenAPI(t *testing.T) {
+	app := New()
+
+	Get(app, "/report", func(c *ContextNoRequest) (File, error) {
+		return File{}, nil
+	}).SetResponseContentType(ContentTypePDF)
+
+	operation := app.openAPISpec.Paths.Find("/report").Get
+	require.NotNil(t, operation)
+
+	for _, status := range []int{StatusOK, StatusPartialContent} {
+		response := operation.Responses.Value(strconv.Itoa(status))
+		require.NotNil(t, response)
+
+		mediaType := response.Value.Content[string(ContentTypePDF)]
+		require.NotNil(t, mediaType)
+		assert.Equal(t, "binary", mediaType.Schema.Value.Format)
+		assert.Contains(t, response.Value.Headers, HeaderContentDisposition)
+		assert.Nil(t, response.Value.Content[string(ContentTypeOctetStream)])
+	}
+
+	assert.Contains(t, operation.Responses.Value(strconv.Itoa(StatusPartialContent)).Value.Headers, HeaderContentRange)
+	assert.NotNil(t, operation.Responses.Value(strconv.Itoa(StatusRequestedRangeNotSatisfiable)))
+	assert.NotNil(t, operation.Parameters.GetByInAndName("header", HeaderRange))
+}
+
+func TestFile_NoContent(t *testing.T) {
+	app := New()
+
+	Get(app, "/empty", func(c *ContextNoRequest) (File, error) {
+		return File{}, nil
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/empty", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
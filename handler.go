@@ -2,10 +2,10 @@ package lite
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"reflect"
 	"regexp"
 	"strings"
 
@@ -22,6 +22,15 @@ func newLiteContext[Request any, Contexter Context[Request]](ctx ContextNoReques
 	case *ContextNoRequest:
 		return any(&ctx).(Contexter)
 	case *ContextWithRequest[Request]:
+		return any(&ContextWithRequest[Request]{
+			ContextNoRequest: ctx,
+		}).(Contexter)
+	case nil:
+		// the handlers taking the Context interface, e.g. to be unit tested with a fake context
+		if reflect.TypeOf((*Request)(nil)).Elem() == reflect.TypeOf((*any)(nil)).Elem() {
+			return any(&ctx).(Contexter)
+		}
+
 		return any(&ContextWithRequest[Request]{
 			ContextNoRequest: ctx,
 		}).(Contexter)
@@ -46,24 +55,71 @@ func fiberHandler[ResponseBody, Request any, Contexter Context[Request]](
 
 		response, err := controller(ctx)
 		if err != nil {
-			// check if the error is a HTTPError and if so, return the error code
-			var httpError HTTPError
-			if errors.As(err, &httpError) {
-				c.Status(httpError.StatusCode())
+			logger.ErrorContext(ctx.Context(), "error", slog.Any("error", err))
 
-				logger.ErrorContext(ctx.Context(), "error", slog.Any("error", err))
+			// the HTTPError and the mapped errors are rendered as is, the cause of the other errors is only logged
+			return app.handleError(c, err)
+		}
 
-				return c.JSON(httpError)
-			}
+		if file, ok := any(response).(File); ok {
+			return writeFile(c, file, app)
+		}
 
-			logger.ErrorContext(ctx.Context(), "error", slog.Any("error", err))
+		if err = serializeResponse(c.Context(), &response); err != nil {
+			logger.ErrorContext(ctx.Context(), "error serializing response", slog.Any("error", err))
 
-			c.Status(http.StatusInternalServerError)
+			// discard the part of the response which may have been encoded
+			c.Response().ResetBody()
 
-			return NewInternalServerError(err.Error())
+			return app.handleError(c, app.serializationError(err))
 		}
 
-		return serializeResponse(c.Context(), &response)
+		return nil
+	}
+}
+
+// routePathKey is the local of the path of the route of the app matching the request, unset for the unknown routes.
+const routePathKey = "lite.route.path"
+
+// matchedRoute returns the path of the route of the app matching the request, false for the unknown routes and
+// the routes registered on the fiber app directly.
+func matchedRoute(c *fiber.Ctx) (string, bool) {
+	path, ok := c.Locals(routePathKey).(string)
+
+	return path, ok
+}
+
+// markRoute returns a handler marking the request as matched by the route of the app at the path, for the routes
+// which are not registered with registerRoute, e.g. the health probes.
+func markRoute(path string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(routePathKey, path)
+
+		return c.Next()
+	}
+}
+
+// routeHandler marks the request as matched by the route, then applies the settings of the route before its handlers:
+// its body limit, then its timeout and the detection of the client disconnections, which are only set up when they are
+// enabled. The settings are read on every request, as they are changed by the Route methods once the route is
+// registered. The WebSocket connections outlive the handler, so they have no body limit nor timeout.
+func (app *App) routeHandler(settings *routeSettings) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(routePathKey, settings.path)
+
+		if settings.webSocket {
+			return c.Next()
+		}
+
+		if err := app.limitBody(c, settings); err != nil {
+			return app.handleError(c, err)
+		}
+
+		if settings.timeout <= 0 && !app.detectDisconnect {
+			return c.Next()
+		}
+
+		return app.runWithTimeout(c, settings)
 	}
 }
 
@@ -77,9 +133,42 @@ func Group(app *App, path string) *App {
 		tag:           app.tag,
 		basePath:      app.basePath,
 		address:       app.address,
+		tls:           app.tls,
 		serverURL:     app.serverURL,
+		admin:         app.admin,
+		adminAddress:  app.adminAddress,
+		metrics:       app.metrics,
 		logger:        app.logger,
 		validator:     app.validator,
+
+		errorFormat:         app.errorFormat,
+		errorContextBaseURL: app.errorContextBaseURL,
+		errorContexts:       app.errorContexts,
+		errorMapper:         app.errorMapper,
+
+		panicHook:   app.panicHook,
+		environment: app.environment,
+		devMode:     app.devMode,
+
+		responseValidator: app.responseValidator,
+
+		timeout:          app.timeout,
+		detectDisconnect: app.detectDisconnect,
+
+		serverConfig: app.serverConfig,
+		prefork:      app.prefork,
+		backend:      app.backend,
+		http:         app.http,
+
+		lifecycle:       app.lifecycle,
+		shutdownTimeout: app.shutdownTimeout,
+		shutdownDelay:   app.shutdownDelay,
+
+		health:        app.health,
+		livenessPath:  app.livenessPath,
+		readinessPath: app.readinessPath,
+
+		webSocketConfig: app.webSocketConfig,
 	}
 
 	newApp.basePath += path
@@ -270,6 +359,25 @@ func registerRoute[ResponseBody, Request any](
 	middleware ...fiber.Handler,
 ) Route[ResponseBody, Request] {
 	fullPath := app.basePath + route.path
+	route.settings = &routeSettings{
+		path:       fullPath,
+		streamBody: streamsBody(reflect.TypeOf((*Request)(nil)).Elem()),
+		webSocket:  route.statusCode == StatusSwitchingProtocols,
+	}
+
+	if route.settings.streamBody && !app.serverConfig.StreamRequestBody {
+		panic("the request body of " + route.method + " " + fullPath + " is streamed, which requires SetStreamRequestBody(true)")
+	}
+
+	if reflect.TypeOf(*new(ResponseBody)) == fileType && route.contentType == string(ContentTypeJSON) {
+		route.contentType = string(ContentTypeOctetStream)
+	}
+
+	app.app.Add(
+		route.method,
+		fullPath,
+		app.routeHandler(route.settings),
+	)
 
 	if len(middleware) > 0 {
 		app.app.Add(
@@ -302,8 +410,18 @@ func registerRoute[ResponseBody, Request any](
 		operation.Description = setDescription(route.method, app.tag)
 	}
 
+	route.app = app
 	route.operation = operation
 
+	if app.timeout > 0 && route.statusCode != StatusSwitchingProtocols {
+		route = route.Timeout(app.timeout)
+	}
+
+	// the request bodies larger than the body limit are rejected
+	if operation.RequestBody != nil {
+		route = route.AddErrorResponse(StatusRequestEntityTooLarge)
+	}
+
 	return route
 }
 
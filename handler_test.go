@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -194,7 +195,7 @@ func (suite *HandlerTestSuite) TestContextWithRequest_Path_Error() {
 	resp, err := app.app.Test(req)
 	assert.NoError(suite.T(), err)
 
-	assert.Equal(suite.T(), 500, resp.StatusCode, "Expected status code 400")
+	assert.Equal(suite.T(), 400, resp.StatusCode, "Expected status code 400")
 }
 
 type requestQuery struct {
@@ -645,6 +646,28 @@ func (suite *HandlerTestSuite) TestCustomContext() {
 	}, "unknown type")
 }
 
+func (suite *HandlerTestSuite) TestContextInterface() {
+	c := newLiteContext[request, Context[request]](ContextNoRequest{})
+	assert.IsType(suite.T(), &ContextWithRequest[request]{}, c)
+
+	noRequest := newLiteContext[any, Context[any]](ContextNoRequest{})
+	assert.IsType(suite.T(), &ContextNoRequest{}, noRequest)
+
+	app := New()
+	Get(app, "/foo/:id", func(c Context[requestRoute]) (responseRoute, error) {
+		req, err := c.Requests()
+
+		return responseRoute{ID: req.ID}, err
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest("GET", "/foo/42", nil))
+	assert.NoError(suite.T(), err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(suite.T(), err)
+	assert.JSONEq(suite.T(), `{"id":42}`, string(body))
+}
+
 type requestRoute struct {
 	ID uint64 `lite:"params=id"`
 }
@@ -689,7 +712,7 @@ func (suite *HandlerTestSuite) TestContextWithRequest_FullBody() {
 
 		assert.Equal(suite.T(), "", c.Get("User-Agent"))
 
-		err = c.SaveFile(req.Body.File, "./logo/lite.png")
+		err = c.SaveFile(req.Body.File, filepath.Join(suite.T().TempDir(), "lite.png"))
 		if err != nil {
 			return testResponse{}, err
 		}
@@ -1019,22 +1042,16 @@ paths:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
                     description: Bad Request
-                "500":
+                "413":
                     content:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
+                    description: Request Entity Too Large
+                "500":
+                    content:
+                        application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
                     description: Internal Server Error
@@ -1128,22 +1145,16 @@ paths:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
                     description: Bad Request
-                "500":
+                "413":
                     content:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
+                    description: Request Entity Too Large
+                "500":
+                    content:
+                        application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
                     description: Internal Server Error`
@@ -1232,22 +1243,16 @@ paths:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
                     description: Bad Request
-                "500":
+                "413":
                     content:
                         application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
-                        application/xml:
-                            schema:
-                                $ref: '#/components/schemas/httpGenericError'
-                        multipart/form-data:
+                    description: Request Entity Too Large
+                "500":
+                    content:
+                        application/json:
                             schema:
                                 $ref: '#/components/schemas/httpGenericError'
                     description: Internal Server Error`
@@ -1282,7 +1287,7 @@ func (suite *HandlerTestSuite) TestContextWithRequest_Body_StringJSONSwagger() {
 	spec, err := app.saveOpenAPISpec()
 	assert.NoError(suite.T(), err)
 
-	expected := `{"components":{"schemas":{"httpGenericError":{"properties":{"@context":{"type":"string"},"@type":{"type":"string"},"description":{"type":"string"},"status":{"type":"integer"},"title":{"type":"string"},"violations":{"items":{"properties":{"code":{"type":"string"},"message":{"type":"string"},"more":{"additionalProperties":{},"type":"object"},"propertyPath":{"type":"string"}},"type":"object"},"type":"array"}},"type":"object"},"string":{"type":"string"}}},"info":{"description":"OpenAPI","title":"OpenAPI","version":"0.0.1"},"openapi":"3.0.3","paths":{"/foo":{"post":{"requestBody":{"content":{"text/plain":{"schema":{"$ref":"#/components/schemas/string"}}}},"responses":{"201":{"content":{"text/plain":{"schema":{"$ref":"#/components/schemas/string"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/httpGenericError"}},"application/xml":{"schema":{"$ref":"#/components/schemas/httpGenericError"}},"multipart/form-data":{"schema":{"$ref":"#/components/schemas/httpGenericError"}}},"description":"Bad Request"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/httpGenericError"}},"application/xml":{"schema":{"$ref":"#/components/schemas/httpGenericError"}},"multipart/form-data":{"schema":{"$ref":"#/components/schemas/httpGenericError"}}},"description":"Internal Server Error"}}}}}}`
+	expected := `{"components":{"schemas":{"httpGenericError":{"properties":{"@context":{"type":"string"},"@type":{"type":"string"},"description":{"type":"string"},"status":{"type":"integer"},"title":{"type":"string"},"violations":{"items":{"properties":{"code":{"type":"string"},"message":{"type":"string"},"more":{"additionalProperties":{},"type":"object"},"propertyPath":{"type":"string"}},"type":"object"},"type":"array"}},"type":"object"},"string":{"type":"string"}}},"info":{"description":"OpenAPI","title":"OpenAPI","version":"0.0.1"},"openapi":"3.0.3","paths":{"/foo":{"post":{"requestBody":{"content":{"text/plain":{"schema":{"$ref":"#/components/schemas/string"}}}},"responses":{"201":{"content":{"text/plain":{"schema":{"$ref":"#/components/schemas/string"}}},"description":"OK"},"400":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/httpGenericError"}}},"description":"Bad Request"},"413":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/httpGenericError"}}},"description":"Request Entity Too Large"},"500":{"content":{"application/json":{"schema":{"$ref":"#/components/schemas/httpGenericError"}}},"description":"Internal Server Error"}}}}}}`
 	assert.JSONEq(suite.T(), expected, string(spec), "openapi generated spec")
 }
 
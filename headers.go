@@ -134,6 +134,17 @@ const (
 	HeaderTrailer          = "Trailer"
 	HeaderTransferEncoding = "Transfer-Encoding"
 
+	// Tus resumable uploads.
+	HeaderTusResumable      = "Tus-Resumable"
+	HeaderTusVersion        = "Tus-Version"
+	HeaderTusExtension      = "Tus-Extension"
+	HeaderTusMaxSize        = "Tus-Max-Size"
+	HeaderUploadOffset      = "Upload-Offset"
+	HeaderUploadLength      = "Upload-Length"
+	HeaderUploadMetadata    = "Upload-Metadata"
+	HeaderUploadExpires     = "Upload-Expires"
+	HeaderUploadDeferLength = "Upload-Defer-Length"
+
 	// WebSockets.
 	HeaderSecWebSocketAccept     = "Sec-WebSocket-Accept"
 	HeaderSecWebSocketExtensions = "Sec-WebSocket-Extensions" /* #nosec G101 */
@@ -0,0 +1,322 @@
+package lite
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// DefaultLivenessPath is the path of the liveness probe.
+	DefaultLivenessPath = "/healthz"
+	// DefaultReadinessPath is the path of the readiness probe.
+	DefaultReadinessPath = "/readyz"
+
+	defaultHealthCheckTimeout = 5 * time.Second
+)
+
+// HealthStatus is the status of a health check or of a probe.
+type HealthStatus string
+
+const (
+	// HealthStatusPass is the status of a passing check, and of a probe whose checks all pass.
+	HealthStatusPass HealthStatus = "pass"
+	// HealthStatusWarn is the status of a failing non-critical check, and of a probe whose critical checks pass.
+	HealthStatusWarn HealthStatus = "warn"
+	// HealthStatusFail is the status of a failing critical check, and of a probe with a failing critical check.
+	HealthStatusFail HealthStatus = "fail"
+)
+
+// HealthCheck is a named check of a component of the app, e.g. a ping of the database.
+type HealthCheck struct {
+	Name     string                          // Name of the check in the report
+	Check    func(ctx context.Context) error // Check returns an error when the component is unhealthy
+	Timeout  time.Duration                   // Timeout of the check, 5 seconds if zero
+	Critical bool                            // A failing critical check fails the probe, the others only degrade it
+	Liveness bool                            // The check is run by the liveness probe, every check is run by the readiness probe
+}
+
+// HealthReport is the body of the responses of the liveness and readiness probes.
+type HealthReport struct {
+	Status HealthStatus                 `json:"status"`
+	Checks map[string]HealthCheckResult `json:"checks,omitempty"`
+}
+
+// HealthCheckResult is the result of a health check in a HealthReport.
+type HealthCheckResult struct {
+	Status   HealthStatus `json:"status"`
+	Latency  string       `json:"latency"`
+	Critical bool         `json:"critical"`
+	Error    string       `json:"error,omitempty"`
+}
+
+// healthRegistry holds the health checks of an app, shared with its groups.
+type healthRegistry struct {
+	mu     sync.RWMutex
+	checks []HealthCheck
+}
+
+func (r *healthRegistry) list(liveness bool) []HealthCheck {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var checks []HealthCheck
+
+	for _, check := range r.checks {
+		if check.Liveness || !liveness {
+			checks = append(checks, check)
+		}
+	}
+
+	return checks
+}
+
+// SetServeHealthProbes serves the liveness and readiness probes on the public listener, off by default: the routes of
+// the app are then unchanged. The admin listener of SetAdminAddress always serves them.
+func SetServeHealthProbes(serve bool) Config {
+	return func(s *App) {
+		s.serveHealth = serve
+	}
+}
+
+// SetHealthPaths serves the liveness and readiness probes at the paths, instead of /healthz and /readyz.
+// An empty path disables the probe, as well as a route of the app registered at the same path.
+func SetHealthPaths(livenessPath, readinessPath string) Config {
+	return func(s *App) {
+		s.serveHealth = true
+		s.livenessPath = livenessPath
+		s.readinessPath = readinessPath
+	}
+}
+
+// SetDocumentHealthProbes documents the probes of the public listener in the OpenAPI spec, off by default: the probes
+// are meant for the orchestrator rather than for the clients of the API.
+func SetDocumentHealthProbes(document bool) Config {
+	return func(s *App) {
+		s.documentHealth = document
+	}
+}
+
+// AddHealthCheck registers a health check, run by the readiness probe and by the liveness probe if check.Liveness is set.
+func (s *App) AddHealthCheck(check HealthCheck) {
+	if check.Timeout <= 0 {
+		check.Timeout = defaultHealthCheckTimeout
+	}
+
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+
+	s.health.checks = append(s.health.checks, check)
+}
+
+// HealthReport runs the checks of the liveness or of the readiness probe concurrently and returns their report.
+// The readiness probe fails once the app is shutting down.
+func (s *App) HealthReport(ctx context.Context, readiness bool) HealthReport {
+	if readiness && s.lifecycle.shuttingDown.Load() {
+		return HealthReport{
+			Status: HealthStatusFail,
+			Checks: map[string]HealthCheckResult{
+				"shutdown": {Status: HealthStatusFail, Latency: "0s", Critical: true, Error: "the app is shutting down"},
+			},
+		}
+	}
+
+	checks := s.health.list(!readiness)
+	results := make([]HealthCheckResult, len(checks))
+
+	var wg sync.WaitGroup
+
+	for i, check := range checks {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			results[i] = runHealthCheck(ctx, check)
+		}()
+	}
+
+	wg.Wait()
+
+	report := HealthReport{
+		Status: HealthStatusPass,
+		Checks: make(map[string]HealthCheckResult, len(checks)),
+	}
+
+	for i, result := range results {
+		report.Checks[checks[i].Name] = result
+
+		switch {
+		case result.Status == HealthStatusFail:
+			report.Status = HealthStatusFail
+		case result.Status == HealthStatusWarn && report.Status == HealthStatusPass:
+			report.Status = HealthStatusWarn
+		}
+	}
+
+	return report
+}
+
+// runHealthCheck runs a check within its timeout. A check ignoring the timeout is reported as failed
+// without waiting for it, and a check which panics is reported as failed.
+func runHealthCheck(ctx context.Context, check HealthCheck) HealthCheckResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("panic: %v", recovered)
+			}
+		}()
+
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", check.Timeout)
+	}
+
+	result := HealthCheckResult{
+		Status:   HealthStatusPass,
+		Latency:  time.Since(start).String(),
+		Critical: check.Critical,
+	}
+
+	if err != nil {
+		result.Status = HealthStatusWarn
+		result.Error = err.Error()
+
+		if check.Critical {
+			result.Status = HealthStatusFail
+		}
+	}
+
+	return result
+}
+
+func (s *App) healthHandler(readiness bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := s.HealthReport(c.UserContext(), readiness)
+
+		status := StatusOK
+		if report.Status == HealthStatusFail {
+			status = StatusServiceUnavailable
+		}
+
+		c.Set(HeaderCacheControl, "no-store")
+
+		return c.Status(status).JSON(report)
+	}
+}
+
+// healthProbe is a liveness or readiness probe.
+type healthProbe struct {
+	path        string
+	readiness   bool
+	operationID string
+	summary     string
+}
+
+// healthProbes returns the enabled probes, skipping the probes of the public listener whose path is already a route
+// of the app. The public listener serves no probes unless they are enabled.
+func (s *App) healthProbes() []healthProbe {
+	if s.admin == nil && !s.serveHealth {
+		return nil
+	}
+
+	probes := make([]healthProbe, 0, 2)
+
+	for _, probe := range []healthProbe{
+		{s.livenessPath, false, "getLiveness", "Check that the app is alive"},
+		{s.readinessPath, true, "getReadiness", "Check that the app is ready to handle requests"},
+	} {
+		if probe.path == "" || s.admin == nil && s.hasRoute(probe.path) {
+			continue
+		}
+
+		probes = append(probes, probe)
+	}
+
+	return probes
+}
+
+// hasRoute returns whether a route of the fiber app, except the middlewares, has the path.
+func (s *App) hasRoute(path string) bool {
+	for _, route := range s.app.GetRoutes(true) {
+		if route.Path == path {
+			return true
+		}
+	}
+
+	return false
+}
+
+// registerHealthProbes serves the liveness and readiness probes, or serves them on the admin listener.
+// It is called by setup, once the public probes are documented by documentHealthProbes.
+func (s *App) registerHealthProbes() {
+	router := s.docsRouter()
+
+	for _, probe := range s.healthProbes() {
+		router.Get(probe.path, markRoute(probe.path), s.healthHandler(probe.readiness))
+	}
+}
+
+// documentHealthProbes documents the probes of the public listener in the OpenAPI spec if SetDocumentHealthProbes is
+// set. The probes of the admin listener are not documented.
+func (s *App) documentHealthProbes() error {
+	if s.admin != nil || !s.documentHealth {
+		return nil
+	}
+
+	for _, probe := range s.healthProbes() {
+		schema, err := s.healthReportSchema()
+		if err != nil {
+			return err
+		}
+
+		operation := openapi3.NewOperation()
+		operation.OperationID = probe.operationID
+		operation.Summary = probe.summary
+		operation.Tags = []string{"Health"}
+		operation.AddResponse(StatusOK, openapi3.NewResponse().
+			WithDescription("The critical checks pass").
+			WithContent(openapi3.NewContentWithJSONSchemaRef(schema)))
+		operation.AddResponse(StatusServiceUnavailable, openapi3.NewResponse().
+			WithDescription("A critical check fails").
+			WithContent(openapi3.NewContentWithJSONSchemaRef(schema)))
+		operation.Responses.Delete("default")
+
+		s.openAPISpec.AddOperation(probe.path, fiber.MethodGet, operation)
+	}
+
+	return nil
+}
+
+// healthReportSchema returns a reference to the HealthReport schema, generated once.
+func (s *App) healthReportSchema() (*openapi3.SchemaRef, error) {
+	schema, ok := s.openAPISpec.Components.Schemas["HealthReport"]
+	if !ok {
+		var err error
+
+		schema, err = generatorNewSchemaRefForValue(new(HealthReport), s.openAPISpec.Components.Schemas)
+		if err != nil {
+			return nil, err
+		}
+
+		s.openAPISpec.Components.Schemas["HealthReport"] = schema
+	}
+
+	return openapi3.NewSchemaRef("#/components/schemas/HealthReport", schema.Value), nil
+}
@@ -0,0 +1,185 @@
+package lite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getHealthReport(t *testing.T, app *App, target string) (*http.Response, HealthReport) {
+	t.Helper()
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var report HealthReport
+	require.NoError(t, json.Unmarshal(body, &report), string(body))
+
+	return resp, report
+}
+
+func TestHealth_Probes(t *testing.T) {
+	app := New(SetDisableSwagger(true), SetServeHealthProbes(true))
+	cache := Group(app, "/cache")
+
+	var errDatabase error
+
+	app.AddHealthCheck(HealthCheck{
+		Name:     "database",
+		Critical: true,
+		Check: func(context.Context) error {
+			return errDatabase
+		},
+	})
+	cache.AddHealthCheck(HealthCheck{
+		Name: "cache",
+		Check: func(context.Context) error {
+			return errors.New("connection refused")
+		},
+	})
+	app.AddHealthCheck(HealthCheck{
+		Name:     "event loop",
+		Critical: true,
+		Liveness: true,
+		Check: func(context.Context) error {
+			return nil
+		},
+	})
+
+	require.NoError(t, app.setup())
+
+	resp, report := getHealthReport(t, app, DefaultLivenessPath)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "no-store", resp.Header.Get(HeaderCacheControl))
+	assert.Equal(t, HealthStatusPass, report.Status)
+	assert.Len(t, report.Checks, 1)
+	assert.Equal(t, HealthStatusPass, report.Checks["event loop"].Status)
+	assert.NotEmpty(t, report.Checks["event loop"].Latency)
+
+	resp, report = getHealthReport(t, app, DefaultReadinessPath)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, HealthStatusWarn, report.Status)
+	assert.Len(t, report.Checks, 3)
+	assert.Equal(t, HealthCheckResult{
+		Status:  HealthStatusWarn,
+		Latency: report.Checks["cache"].Latency,
+		Error:   "connection refused",
+	}, report.Checks["cache"])
+
+	errDatabase = errors.New("too many connections")
+
+	resp, report = getHealthReport(t, app, DefaultReadinessPath)
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Equal(t, "too many connections", report.Checks["database"].Error)
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	app := New()
+	release := make(chan struct{})
+
+	defer close(release)
+
+	app.AddHealthCheck(HealthCheck{
+		Name:     "stuck",
+		Timeout:  20 * time.Millisecond,
+		Critical: true,
+		Check: func(context.Context) error {
+			<-release
+
+			return nil
+		},
+	})
+	app.AddHealthCheck(HealthCheck{
+		Name: "broken",
+		Check: func(context.Context) error {
+			panic("nil pointer")
+		},
+	})
+
+	report := app.HealthReport(context.Background(), true)
+
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Equal(t, "timed out after 20ms", report.Checks["stuck"].Error)
+	assert.Equal(t, HealthStatusWarn, report.Checks["broken"].Status)
+	assert.Equal(t, "panic: nil pointer", report.Checks["broken"].Error)
+}
+
+func TestHealth_Shutdown(t *testing.T) {
+	app := New()
+
+	assert.Equal(t, HealthStatusPass, app.HealthReport(context.Background(), true).Status)
+
+	require.NoError(t, app.ShutdownWithContext(context.Background()))
+
+	report := app.HealthReport(context.Background(), true)
+	assert.Equal(t, HealthStatusFail, report.Status)
+	assert.Equal(t, "the app is shutting down", report.Checks["shutdown"].Error)
+
+	// the app is still alive while it drains the requests
+	assert.Equal(t, HealthStatusPass, app.HealthReport(context.Background(), false).Status)
+}
+
+func TestHealth_Disabled(t *testing.T) {
+	app := New(SetDisableSwagger(true))
+
+	require.NoError(t, app.setup())
+
+	assert.Nil(t, app.openAPISpec.Paths.Find(DefaultLivenessPath))
+	assert.NotContains(t, app.openAPISpec.Components.Schemas, "HealthReport")
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, DefaultLivenessPath, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// the probes are served, but kept out of the spec
+	app = New(SetDisableSwagger(true), SetServeHealthProbes(true))
+
+	require.NoError(t, app.setup())
+
+	assert.Nil(t, app.openAPISpec.Paths.Find(DefaultReadinessPath))
+
+	resp, _ = getHealthReport(t, app, DefaultReadinessPath)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHealth_OpenAPI(t *testing.T) {
+	app := New(SetDisableSwagger(true), SetHealthPaths("", "/health/ready"), SetDocumentHealthProbes(true))
+
+	Get(app, "/healthz", func(c *ContextNoRequest) (string, error) {
+		return "custom", nil
+	})
+
+	require.NoError(t, app.setup())
+
+	operation := app.openAPISpec.Paths.Find("/health/ready").Get
+	require.NotNil(t, operation)
+	assert.Equal(t, "getReadiness", operation.OperationID)
+	assert.Equal(t, "#/components/schemas/HealthReport",
+		operation.Responses.Value("503").Value.Content.Get(string(ContentTypeJSON)).Schema.Ref)
+	assert.Contains(t, app.openAPISpec.Components.Schemas, "HealthReport")
+
+	// the route of the app is kept
+	assert.NotEqual(t, "getLiveness", app.openAPISpec.Paths.Find("/healthz").Get.OperationID)
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "custom", string(body))
+}
@@ -0,0 +1,284 @@
+package lite
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// DefaultErrorContextBaseURL is the base URL of the JSON-LD contexts set in the @context of the errors.
+	DefaultErrorContextBaseURL = "/api/contexts"
+
+	hydraVocabulary = "http://www.w3.org/ns/hydra/core#"
+)
+
+// ErrorContext documents an error type, served as a JSON-LD context at <base URL>/<Name>.
+type ErrorContext struct {
+	Name        string // Name of the error type, the last segment of the @context URL, e.g. NotFound
+	Title       string // Short summary of the error type
+	Description string // Description of when the error occurs
+	Status      int    // Status code of the error, 0 if it varies
+}
+
+var defaultErrorContexts = []ErrorContext{
+	{
+		Name:        "Error",
+		Title:       "An error occurred",
+		Description: "A generic error, the status code describes the error.",
+	},
+	{
+		Name:        "BadRequest",
+		Title:       "Bad request",
+		Description: "The request is invalid.",
+		Status:      StatusBadRequest,
+	},
+	{
+		Name:        "ConstraintViolationList",
+		Title:       "A constraint violation occurred",
+		Description: "The request violates constraints, listed in violations with the path of the invalid property.",
+	},
+	{
+		Name:        "DeserializationError",
+		Title:       "Deserialization error",
+		Description: "The parameters or the body of the request could not be decoded.",
+		Status:      StatusBadRequest,
+	},
+	{
+		Name:        "RequestBodyError",
+		Title:       "A request body is required",
+		Description: "The body of the request is missing or has an unsupported type.",
+		Status:      StatusBadRequest,
+	},
+	{
+		Name:        "AuthenticationFailure",
+		Title:       "Authentication failure",
+		Description: "The request is not authenticated.",
+		Status:      StatusUnauthorized,
+	},
+	{
+		Name:        "AccessDenied",
+		Title:       "Access denied",
+		Description: "The authenticated client is not allowed to perform the request.",
+		Status:      StatusForbidden,
+	},
+	{
+		Name:        "NotFound",
+		Title:       "Resource not found",
+		Description: "The requested resource does not exist.",
+		Status:      StatusNotFound,
+	},
+	{
+		Name:        "Conflict",
+		Title:       "Conflict",
+		Description: "The request conflicts with the current state of the resource.",
+		Status:      StatusConflict,
+	},
+	{
+		Name:        "SerializationError",
+		Title:       "Serialization error",
+		Description: "The response could not be encoded.",
+		Status:      StatusInternalServerError,
+	},
+	{
+		Name:        "OpenAPIError",
+		Title:       "OpenAPI error",
+		Description: "The OpenAPI documentation of a route could not be generated.",
+		Status:      StatusInternalServerError,
+	},
+	{
+		Name:        "InternalServerError",
+		Title:       "Internal server error",
+		Description: "An unexpected error occurred on the server.",
+		Status:      StatusInternalServerError,
+	},
+	{
+		Name:        "ServiceUnavailable",
+		Title:       "Service unavailable",
+		Description: "The server is temporarily unable to handle the request.",
+		Status:      StatusServiceUnavailable,
+	},
+}
+
+// errorContextRegistry holds the error contexts of an app, shared with its groups.
+type errorContextRegistry struct {
+	mu       sync.RWMutex
+	contexts map[string]ErrorContext
+}
+
+func newErrorContextRegistry() *errorContextRegistry {
+	registry := &errorContextRegistry{
+		contexts: make(map[string]ErrorContext, len(defaultErrorContexts)),
+	}
+
+	for _, errorContext := range defaultErrorContexts {
+		registry.contexts[errorContext.Name] = errorContext
+	}
+
+	return registry
+}
+
+func (r *errorContextRegistry) get(name string) (ErrorContext, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	errorContext, ok := r.contexts[name]
+
+	return errorContext, ok
+}
+
+func (r *errorContextRegistry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.contexts))
+	for name := range r.contexts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// SetErrorContextBaseURL sets the base URL of the JSON-LD contexts of the errors. Default is /api/contexts.
+func SetErrorContextBaseURL(baseURL string) Config {
+	return func(s *App) {
+		s.errorContextBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// SetServeErrorContexts serves the JSON-LD contexts of the errors at their base URL and documents their route in the
+// OpenAPI spec, off by default: the @context of the errors is then an identifier only, and the routes and the spec of
+// the app are unchanged. The contexts are served when the base URL is a path, or an absolute URL of the app.
+func SetServeErrorContexts(serve bool) Config {
+	return func(s *App) {
+		s.serveErrorContexts = serve
+	}
+}
+
+// RegisterErrorContext registers the JSON-LD context of a custom error type.
+// Errors of this type set their @context to <DefaultErrorContextBaseURL>/<Name>.
+func (s *App) RegisterErrorContext(errorContext ErrorContext) {
+	s.errorContexts.mu.Lock()
+	defer s.errorContexts.mu.Unlock()
+
+	s.errorContexts.contexts[errorContext.Name] = errorContext
+}
+
+// errorContextURL rewrites the @context of an error with the base URL of the app.
+func (s *App) errorContextURL(context string) string {
+	name, ok := strings.CutPrefix(context, DefaultErrorContextBaseURL+"/")
+	if !ok || s.errorContextBaseURL == DefaultErrorContextBaseURL {
+		return context
+	}
+
+	return s.errorContextBaseURL + "/" + name
+}
+
+// errorContextDocument returns the JSON-LD context document of an error type.
+func (s *App) errorContextDocument(errorContext ErrorContext) map[string]any {
+	id := s.errorContextBaseURL + "/" + errorContext.Name
+
+	document := map[string]any{
+		"@context": map[string]any{
+			"@vocab":       id + "#",
+			"hydra":        hydraVocabulary,
+			"title":        "hydra:title",
+			"description":  "hydra:description",
+			"status":       "hydra:statusCode",
+			"violations":   map[string]any{"@id": "violations", "@container": "@list"},
+			"propertyPath": "propertyPath",
+			"message":      "message",
+			"code":         "code",
+		},
+		"@id":         id,
+		"@type":       "hydra:Error",
+		"title":       errorContext.Title,
+		"description": errorContext.Description,
+	}
+
+	if errorContext.Status != 0 {
+		document["status"] = errorContext.Status
+	}
+
+	return document
+}
+
+// errorContextPath returns the path of the app serving the contexts, or false if they are not served
+// or served elsewhere.
+func (s *App) errorContextPath() (string, bool) {
+	if !s.serveErrorContexts {
+		return "", false
+	}
+
+	if strings.HasPrefix(s.errorContextBaseURL, "/") {
+		return s.errorContextBaseURL, true
+	}
+
+	baseURL, err := url.Parse(s.errorContextBaseURL)
+	if err != nil || baseURL.Path == "" || !strings.HasPrefix(s.errorContextBaseURL, s.serverURL) {
+		return "", false
+	}
+
+	return strings.TrimRight(baseURL.Path, "/"), true
+}
+
+func (s *App) errorContextHandler(c *fiber.Ctx) error {
+	errorContext, ok := s.errorContexts.get(c.Params("name"))
+	if !ok {
+		return s.renderError(c, NewNotFoundError("The error context does not exist"))
+	}
+
+	return c.JSON(s.errorContextDocument(errorContext), string(ContentTypeJSONLD))
+}
+
+// registerErrorContexts serves the JSON-LD contexts, documented by documentErrorContexts. It is called by setup.
+func (s *App) registerErrorContexts() {
+	if path, ok := s.errorContextPath(); ok {
+		s.app.Get(path+"/:name", markRoute(path+"/:name"), s.errorContextHandler)
+	}
+}
+
+// documentErrorContexts documents the JSON-LD contexts in the OpenAPI spec.
+// It is called by setup, once the custom contexts are registered.
+func (s *App) documentErrorContexts() error {
+	path, ok := s.errorContextPath()
+	if !ok {
+		return nil
+	}
+
+	if _, err := s.errorSchema(); err != nil {
+		return err
+	}
+
+	var enum []any
+	for _, name := range s.errorContexts.names() {
+		enum = append(enum, name)
+	}
+
+	parameter := openapi3.NewPathParameter("name").
+		WithSchema(openapi3.NewStringSchema().WithEnum(enum...)).
+		WithDescription("Name of the error type, the last segment of the @context of the error")
+
+	response := openapi3.NewResponse().
+		WithDescription("JSON-LD context of the error type").
+		WithContent(openapi3.NewContentWithSchema(openapi3.NewObjectSchema(), []string{string(ContentTypeJSONLD)}))
+
+	operation := openapi3.NewOperation()
+	operation.OperationID = "getErrorContext"
+	operation.Summary = "Get the JSON-LD context of an error type"
+	operation.Tags = []string{"Contexts"}
+	operation.AddParameter(parameter)
+	operation.AddResponse(StatusOK, response)
+	operation.AddResponse(StatusNotFound, s.errorResponse(StatusNotFound, "application/json"))
+	operation.Responses.Delete("default")
+
+	s.openAPISpec.AddOperation(path+"/{name}", fiber.MethodGet, operation)
+
+	return nil
+}
@@ -0,0 +1,146 @@
+package lite
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var paymentRequiredContext = ErrorContext{
+	Name:        "PaymentRequired",
+	Title:       "Payment required",
+	Description: "The subscription of the account has expired.",
+	Status:      http.StatusPaymentRequired,
+}
+
+func newErrorContextApp(t *testing.T, config ...Config) *App {
+	t.Helper()
+
+	app := New(append([]Config{SetDisableSwagger(true), SetServeErrorContexts(true)}, config...)...)
+	app.RegisterErrorContext(paymentRequiredContext)
+
+	Get(app, "/invoices", func(c *ContextNoRequest) (string, error) {
+		return "", NewErrorResponse(
+			http.StatusPaymentRequired,
+			DefaultErrorContextBaseURL+"/PaymentRequired",
+			"Payment required",
+			"PaymentRequired",
+			"The subscription has expired",
+			nil,
+		)
+	})
+
+	require.NoError(t, app.setup())
+
+	return app
+}
+
+func getJSON(t *testing.T, app *App, target string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(body, &document))
+
+	return resp, document
+}
+
+func TestErrorContext_Serve(t *testing.T) {
+	app := newErrorContextApp(t)
+
+	resp, document := getJSON(t, app, "/api/contexts/NotFound")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, string(ContentTypeJSONLD), resp.Header.Get(HeaderContentType))
+	assert.Equal(t, "/api/contexts/NotFound", document["@id"])
+	assert.Equal(t, "Resource not found", document["title"])
+	assert.Equal(t, float64(http.StatusNotFound), document["status"])
+
+	context, ok := document["@context"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, hydraVocabulary, context["hydra"])
+
+	// Every context set by the framework errors is served.
+	for _, errorContext := range defaultErrorContexts {
+		resp, _ = getJSON(t, app, DefaultErrorContextBaseURL+"/"+errorContext.Name)
+		assert.Equal(t, http.StatusOK, resp.StatusCode, errorContext.Name)
+	}
+
+	resp, document = getJSON(t, app, "/api/contexts/PaymentRequired")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "Payment required", document["title"])
+
+	resp, _ = getJSON(t, app, "/api/contexts/Unknown")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestErrorContext_BaseURL(t *testing.T) {
+	app := newErrorContextApp(t, SetErrorContextBaseURL("/errors/"))
+
+	resp, document := getJSON(t, app, "/invoices")
+	assert.Equal(t, http.StatusPaymentRequired, resp.StatusCode)
+	assert.Equal(t, "/errors/PaymentRequired", document["@context"])
+
+	resp, document = getJSON(t, app, "/errors/PaymentRequired")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "/errors/PaymentRequired", document["@id"])
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/api/contexts/PaymentRequired", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestErrorContext_ExternalBaseURL(t *testing.T) {
+	app := newErrorContextApp(t, SetErrorContextBaseURL("https://errors.example.com/contexts"))
+
+	_, document := getJSON(t, app, "/invoices")
+	assert.Equal(t, "https://errors.example.com/contexts/PaymentRequired", document["@context"])
+
+	assert.Nil(t, app.openAPISpec.Paths.Find("/contexts/{name}"))
+}
+
+func TestErrorContext_OpenAPI(t *testing.T) {
+	app := newErrorContextApp(t)
+
+	pathItem := app.openAPISpec.Paths.Find("/api/contexts/{name}")
+	require.NotNil(t, pathItem)
+	require.NotNil(t, pathItem.Get)
+
+	parameter := pathItem.Get.Parameters.GetByInAndName("path", "name")
+	require.NotNil(t, parameter)
+	assert.Contains(t, parameter.Schema.Value.Enum, "PaymentRequired")
+	assert.Contains(t, parameter.Schema.Value.Enum, "ConstraintViolationList")
+
+	assert.NotNil(t, pathItem.Get.Responses.Value("200").Value.Content.Get(string(ContentTypeJSONLD)))
+}
+
+func TestErrorContext_Problem(t *testing.T) {
+	app := newErrorContextApp(t, SetErrorFormat(ErrorFormatProblem), SetErrorContextBaseURL("/errors"))
+
+	_, document := getJSON(t, app, "/invoices")
+	assert.Equal(t, "/errors/PaymentRequired", document["type"])
+}
+
+func TestErrorContext_NotServed(t *testing.T) {
+	app := newErrorContextApp(t, SetServeErrorContexts(false))
+
+	_, document := getJSON(t, app, "/invoices")
+	assert.Equal(t, "/api/contexts/PaymentRequired", document["@context"])
+
+	// the contexts are identifiers only, the routes and the spec of the app are unchanged
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/api/contexts/PaymentRequired", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	assert.Nil(t, app.openAPISpec.Paths.Find("/api/contexts/{name}"))
+}
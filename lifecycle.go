@@ -0,0 +1,163 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const defaultShutdownTimeout = 30 * time.Second
+
+// lifecycle holds the start and shutdown hooks of an app, shared with its groups.
+type lifecycle struct {
+	mu            sync.Mutex
+	startHooks    []func(ctx context.Context) error
+	shutdownHooks []func(ctx context.Context) error
+
+	requests       context.Context // parent of the contexts of the requests, canceled when the in-flight requests are not drained before the shutdown deadline
+	cancelRequests context.CancelCauseFunc
+
+	shuttingDown atomic.Bool // set when the shutdown starts, the readiness probe fails from then on
+}
+
+func newLifecycle() *lifecycle {
+	requests, cancelRequests := context.WithCancelCause(context.Background())
+
+	return &lifecycle{
+		requests:       requests,
+		cancelRequests: cancelRequests,
+	}
+}
+
+func (l *lifecycle) abort() {
+	l.cancelRequests(errServerShutdown)
+}
+
+func (l *lifecycle) hooks(shutdown bool) []func(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if shutdown {
+		return append([]func(ctx context.Context) error(nil), l.shutdownHooks...)
+	}
+
+	return append([]func(ctx context.Context) error(nil), l.startHooks...)
+}
+
+// SetShutdownTimeout sets the time given to the in-flight requests and to the shutdown hooks
+// when Run receives SIGTERM or SIGINT. Default is 30 seconds.
+// Set it below the termination grace period of the deployment, e.g. 30 seconds on Kubernetes.
+func SetShutdownTimeout(timeout time.Duration) Config {
+	return func(s *App) {
+		s.shutdownTimeout = timeout
+	}
+}
+
+// SetShutdownDelay sets the time between the reception of SIGTERM or SIGINT by Run and the shutdown of the app.
+// The readiness probe fails during the delay, so the load balancers stop sending requests before the app stops
+// listening. Default is no delay.
+func SetShutdownDelay(delay time.Duration) Config {
+	return func(s *App) {
+		s.shutdownDelay = delay
+	}
+}
+
+// OnStart registers a hook called by Run before the app listens, e.g. to connect to a database.
+// The hooks are called in the order of registration, and the first failing hook stops the start:
+// the shutdown hooks are then called and Run returns the errors.
+func (s *App) OnStart(hook func(ctx context.Context) error) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+
+	s.lifecycle.startHooks = append(s.lifecycle.startHooks, hook)
+}
+
+// OnShutdown registers a hook called once the in-flight requests are drained, e.g. to close a database.
+// The hooks are called in the reverse order of registration, all of them even if some fail.
+func (s *App) OnShutdown(hook func(ctx context.Context) error) {
+	s.lifecycle.mu.Lock()
+	defer s.lifecycle.mu.Unlock()
+
+	s.lifecycle.shutdownHooks = append(s.lifecycle.shutdownHooks, hook)
+}
+
+// start calls the start hooks, and the shutdown hooks if one of them fails.
+func (s *App) start(ctx context.Context) error {
+	for _, hook := range s.lifecycle.hooks(false) {
+		if err := hook(ctx); err != nil {
+			return errors.Join(err, s.runShutdownHooks(ctx))
+		}
+	}
+
+	return nil
+}
+
+// runShutdownHooks calls the shutdown hooks and returns their errors joined.
+func (s *App) runShutdownHooks(ctx context.Context) error {
+	hooks := s.lifecycle.hooks(true)
+
+	var errs []error
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// ShutdownWithContext stops the app from accepting connections, waits for the in-flight requests to complete
+// until the context is done, then calls the shutdown hooks with the context.
+// When the context is done first, the contexts of the handlers still running are canceled and a 503 HTTPError is
+// written instead of their response. It returns the error of the drain and the errors of the hooks joined.
+func (s *App) ShutdownWithContext(ctx context.Context) error {
+	s.lifecycle.shuttingDown.Store(true)
+
+	stop := context.AfterFunc(ctx, s.lifecycle.abort)
+	err := errors.Join(s.app.ShutdownWithContext(ctx), s.shutdownHTTP(ctx))
+	stop()
+
+	// the admin listener serves the probes until the requests are drained
+	if s.admin != nil {
+		err = errors.Join(err, s.admin.ShutdownWithContext(ctx))
+	}
+
+	return errors.Join(err, s.runShutdownHooks(ctx))
+}
+
+// requestContextMiddleware sets the user context of the requests, canceled when the shutdown of the app times out,
+// and derived from the context of the net/http request for the requests served by ServeHTTP, so that the handlers
+// are canceled when the client disconnects. It writes a 503 HTTPError instead of the response of the handlers
+// canceled by the shutdown.
+func (s *App) requestContextMiddleware(c *fiber.Ctx) error {
+	ctx := s.lifecycle.requests
+
+	if requestCtx, ok := httpRequestContext(c); ok {
+		var cancel context.CancelCauseFunc
+
+		ctx, cancel = context.WithCancelCause(requestCtx)
+		defer cancel(nil)
+
+		stop := context.AfterFunc(s.lifecycle.requests, func() {
+			cancel(errServerShutdown)
+		})
+		defer stop()
+	}
+
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	if errors.Is(context.Cause(ctx), errServerShutdown) {
+		c.Response().ResetBody()
+
+		return s.renderError(c, NewServiceUnavailableError("The server is shutting down"))
+	}
+
+	return err
+}
@@ -0,0 +1,220 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errCloseDatabase = errors.New("close database")
+
+// runApp runs the app on a free port and returns its address, once it accepts connections, and the error of Run.
+func runApp(t *testing.T, app *App) (string, <-chan error) {
+	t.Helper()
+
+	port, err := getFreePort()
+	require.NoError(t, err)
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	ran := make(chan error, 1)
+
+	go func() {
+		ran <- app.Listen(address)
+	}()
+
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", address)
+		if err != nil {
+			return false
+		}
+
+		_ = conn.Close()
+
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return address, ran
+}
+
+func TestLifecycle_Hooks(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true))
+	group := Group(app, "/users")
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	hook := func(name string) func(context.Context) error {
+		return func(context.Context) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			calls = append(calls, name)
+
+			return nil
+		}
+	}
+
+	app.OnStart(hook("start database"))
+	group.OnStart(hook("start cache"))
+	app.OnShutdown(hook("stop database"))
+	group.OnShutdown(hook("stop cache"))
+
+	_, ran := runApp(t, app)
+
+	mu.Lock()
+	assert.Equal(t, []string{"start database", "start cache"}, calls)
+	mu.Unlock()
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+
+	assert.Equal(t, []string{"start database", "start cache", "stop cache", "stop database"}, calls)
+}
+
+func TestLifecycle_StartError(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true))
+	errConnect := errors.New("connect to database")
+
+	listening := false
+
+	app.OnStart(func(context.Context) error {
+		return errConnect
+	})
+	app.OnStart(func(context.Context) error {
+		listening = true
+
+		return nil
+	})
+	app.OnShutdown(func(context.Context) error {
+		return errCloseDatabase
+	})
+
+	err := app.Run()
+
+	assert.ErrorIs(t, err, errConnect)
+	assert.ErrorIs(t, err, errCloseDatabase)
+	assert.False(t, listening)
+}
+
+func TestLifecycle_Signal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the signals can not be sent on windows")
+	}
+
+	app := New(
+		SetDisableLocalSave(true),
+		SetDisableSwagger(true),
+		SetShutdownTimeout(5*time.Second),
+		SetShutdownDelay(300*time.Millisecond),
+		SetServeHealthProbes(true),
+	)
+	started := make(chan struct{})
+
+	Get(app, "/slow", func(c *ContextNoRequest) (string, error) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+
+		return "done", nil
+	})
+
+	drained := false
+
+	app.OnShutdown(func(context.Context) error {
+		drained = true
+
+		return errCloseDatabase
+	})
+
+	address, ran := runApp(t, app)
+
+	go func() {
+		<-started
+
+		process, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			_ = process.Signal(syscall.SIGTERM)
+		}
+	}()
+
+	// the in-flight request completes before the shutdown hooks are called
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Get("http://" + address + "/slow")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// the app still listens during the shutdown delay, but is not ready anymore
+	resp, err = client.Get("http://" + address + DefaultReadinessPath)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	select {
+	case err = <-ran:
+		assert.ErrorIs(t, err, errCloseDatabase)
+		assert.True(t, drained)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the app did not shut down")
+	}
+
+	_, err = net.Dial("tcp", address)
+	assert.Error(t, err)
+}
+
+func TestShutdownWithContext(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true))
+	started, release := make(chan struct{}), make(chan struct{})
+
+	Get(app, "/slow", func(c *ContextNoRequest) (string, error) {
+		close(started)
+		<-release
+
+		return "done", nil
+	})
+
+	var hookErr error
+
+	app.OnShutdown(func(ctx context.Context) error {
+		hookErr = ctx.Err()
+
+		return nil
+	})
+
+	address, ran := runApp(t, app)
+
+	go func() {
+		resp, err := http.Get("http://" + address + "/slow")
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err := app.ShutdownWithContext(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.ErrorIs(t, hookErr, context.DeadlineExceeded)
+
+	close(release)
+	assert.NoError(t, <-ran)
+}
@@ -0,0 +1,317 @@
+package lite
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// unixAddressPrefix is the prefix of the addresses of unix sockets, e.g. unix:/run/app.sock.
+const unixAddressPrefix = "unix:"
+
+// certificateCheckInterval is the minimum duration between two checks of the modification of the certificate files.
+var certificateCheckInterval = time.Second
+
+// tlsFiles holds the PEM files of the TLS configuration of the app.
+type tlsFiles struct {
+	certFile     string
+	keyFile      string
+	clientCAFile string
+	clientAuth   tls.ClientAuthType
+}
+
+// SetTLS serves the app over TLS with the certificate and the key of the PEM files.
+// The files are reloaded when they change, so a rotated certificate is served without restarting the app.
+func SetTLS(certFile, keyFile string) Config {
+	return func(s *App) {
+		s.tls.certFile = certFile
+		s.tls.keyFile = keyFile
+	}
+}
+
+// SetClientCA verifies the certificates of the clients with the CA certificates of the PEM file (mutual TLS).
+// The clients must present a valid certificate, unless another clientAuth is given.
+// It requires SetTLS, Run returns an error otherwise.
+func SetClientCA(caFile string, clientAuth ...tls.ClientAuthType) Config {
+	return func(s *App) {
+		s.tls.clientCAFile = caFile
+		s.tls.clientAuth = tls.RequireAndVerifyClientCert
+
+		if len(clientAuth) > 0 {
+			s.tls.clientAuth = clientAuth[0]
+		}
+	}
+}
+
+// parseAddress returns the network and the address to listen on of an app address:
+// host:port, [ipv6]:port, :port or unix:/path/to/socket.
+func parseAddress(address string) (network string, addr string, err error) {
+	if path, ok := strings.CutPrefix(address, unixAddressPrefix); ok {
+		if path == "" {
+			return "", "", fmt.Errorf("address %q: missing socket path", address)
+		}
+
+		return "unix", path, nil
+	}
+
+	_, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", "", fmt.Errorf("address %q: %w", address, err)
+	}
+
+	if _, err = strconv.ParseUint(port, 10, 16); err != nil {
+		return "", "", fmt.Errorf("address %q: invalid port %q", address, port)
+	}
+
+	return "tcp", address, nil
+}
+
+// localServerURL returns the URL of the app, derived from its address and its TLS configuration.
+func (s *App) localServerURL() string {
+	scheme := "http"
+	if s.tls.certFile != "" {
+		scheme = "https"
+	}
+
+	network, addr, err := parseAddress(s.address)
+	if err != nil || network == "unix" {
+		return scheme + "://localhost"
+	}
+
+	host, port, _ := net.SplitHostPort(addr)
+
+	switch host {
+	case "", "0.0.0.0", "::":
+		host = "localhost"
+	}
+
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// listen returns the listener of the app address, with TLS if configured.
+func (s *App) listen() (net.Listener, error) {
+	ln, err := s.listenAddress(s.address)
+	if err != nil || s.tls.certFile == "" {
+		return ln, err
+	}
+
+	config, err := s.tls.config(s.logger)
+	if err != nil {
+		_ = ln.Close()
+
+		return nil, err
+	}
+
+	// HTTP/2 is served by net/http only
+	if s.backend == BackendNetHTTP {
+		config.NextProtos = []string{"h2", "http/1.1"}
+	}
+
+	return tls.NewListener(ln, config), nil
+}
+
+// listenAddress returns the listener of an app address, shared with the other children in a prefork child.
+func (s *App) listenAddress(address string) (net.Listener, error) {
+	network, addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.preforkChild() {
+		return listenReusePort(network, addr)
+	}
+
+	if network == "unix" {
+		if err = removeStaleSocket(addr); err != nil {
+			return nil, err
+		}
+	}
+
+	ln, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return ln, nil
+}
+
+// removeStaleSocket removes the socket file left by an app which has not been shut down.
+func removeStaleSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("failed to listen: %s exists and is not a socket", path)
+	}
+
+	return os.Remove(path)
+}
+
+// validate returns an error if the TLS configuration is incomplete, e.g. a client CA without a certificate,
+// which would serve the app without TLS.
+func (f tlsFiles) validate() error {
+	if f.clientCAFile != "" && f.certFile == "" {
+		return errors.New("the client CA requires a TLS certificate, set with SetTLS")
+	}
+
+	if (f.certFile == "") != (f.keyFile == "") {
+		return errors.New("the TLS certificate requires both a certificate file and a key file")
+	}
+
+	return nil
+}
+
+func (f tlsFiles) config(logger *slog.Logger) (*tls.Config, error) {
+	if err := f.validate(); err != nil {
+		return nil, err
+	}
+
+	reloader, err := newCertificateReloader(f.certFile, f.keyFile, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		NextProtos:     []string{"http/1.1"},
+		GetCertificate: reloader.getCertificate,
+	}
+
+	if f.clientCAFile != "" {
+		data, err := os.ReadFile(f.clientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the client CA: %w", err)
+		}
+
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("failed to read the client CA: no certificate in %s", f.clientCAFile)
+		}
+
+		config.ClientAuth = f.clientAuth
+	}
+
+	return config, nil
+}
+
+// certificateReloader loads the certificate of the TLS handshakes, again when its files are modified.
+// The files are checked at most once per certificateCheckInterval, by a single handshake at a time.
+type certificateReloader struct {
+	certFile string
+	keyFile  string
+	logger   *slog.Logger
+
+	certificate atomic.Pointer[tls.Certificate]
+	nextCheck   atomic.Int64 // time of the next check of the files, in Unix nanoseconds
+
+	mu         sync.Mutex // held while the files are checked
+	modTime    time.Time  // modification time of the files of the certificate
+	failed     bool       // the files failed to load at failedTime, the failure is already logged
+	failedTime time.Time
+}
+
+func newCertificateReloader(certFile, keyFile string, logger *slog.Logger) (*certificateReloader, error) {
+	reloader := &certificateReloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		logger:   logger,
+	}
+
+	modTime, err := reloader.filesModTime()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+
+	if err = reloader.load(modTime); err != nil {
+		return nil, err
+	}
+
+	reloader.nextCheck.Store(time.Now().Add(certificateCheckInterval).UnixNano())
+
+	return reloader, nil
+}
+
+// filesModTime returns the latest modification time of the certificate and key files.
+func (r *certificateReloader) filesModTime() (time.Time, error) {
+	var modTime time.Time
+
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		if info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+
+	return modTime, nil
+}
+
+func (r *certificateReloader) load(modTime time.Time) error {
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load the TLS certificate: %w", err)
+	}
+
+	r.certificate.Store(&certificate)
+	r.modTime = modTime
+	r.failed = false
+
+	return nil
+}
+
+// reload loads the certificate again if its files have been modified since it was loaded.
+// A failure is logged once per modification of the files, e.g. once while the key is not yet rotated.
+func (r *certificateReloader) reload() {
+	modTime, err := r.filesModTime()
+	if err == nil && modTime.Equal(r.modTime) {
+		return
+	}
+
+	if err == nil {
+		if err = r.load(modTime); err == nil {
+			return
+		}
+	}
+
+	if r.failed && modTime.Equal(r.failedTime) {
+		return
+	}
+
+	r.failed = true
+	r.failedTime = modTime
+
+	r.logger.Error("failed to reload the TLS certificate", slog.Any("error", err))
+}
+
+// getCertificate returns the certificate, reloaded if its files have been modified since it was loaded.
+// The previous certificate is kept while the files can not be loaded, e.g. while the key is not yet rotated.
+// The handshakes do not wait for the check of the files made by another handshake.
+func (r *certificateReloader) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := time.Now()
+
+	if now.UnixNano() >= r.nextCheck.Load() && r.mu.TryLock() {
+		r.nextCheck.Store(now.Add(certificateCheckInterval).UnixNano())
+		r.reload()
+		r.mu.Unlock()
+	}
+
+	return r.certificate.Load(), nil
+}
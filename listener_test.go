@@ -0,0 +1,398 @@
+package lite
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues the certificates of the TLS tests.
+type testCA struct {
+	certificate *x509.Certificate
+	key         *ecdsa.PrivateKey
+	pool        *x509.CertPool
+	file        string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "lite test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	ca := &testCA{certificate: certificate, key: key, pool: x509.NewCertPool()}
+	ca.pool.AddCert(certificate)
+	ca.file = writePEM(t, "ca.pem", "CERTIFICATE", der)
+
+	return ca
+}
+
+// issue writes a certificate signed by the CA and its key, and returns their files.
+func (ca *testCA) issue(t *testing.T, name string, serial int64, usage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.certificate, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return writePEM(t, name+".pem", "CERTIFICATE", der), writePEM(t, name+"-key.pem", "PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+
+	return file
+}
+
+func newListenerApp(config ...Config) *App {
+	app := New(append([]Config{SetDisableLocalSave(true), SetDisableSwagger(true)}, config...)...)
+
+	Get(app, "/ping", func(c *ContextNoRequest) (string, error) {
+		return "pong", nil
+	})
+
+	return app
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := []struct {
+		address string
+		network string
+		addr    string
+		err     bool
+	}{
+		{address: ":9000", network: "tcp", addr: ":9000"},
+		{address: "127.0.0.1:8080", network: "tcp", addr: "127.0.0.1:8080"},
+		{address: "[::1]:8080", network: "tcp", addr: "[::1]:8080"},
+		{address: "unix:/run/app.sock", network: "unix", addr: "/run/app.sock"},
+		{address: "8080", err: true},
+		{address: ":invalid", err: true},
+		{address: ":70000", err: true},
+		{address: "::1:8080", err: true},
+		{address: "unix:", err: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			network, addr, err := parseAddress(tt.address)
+			if tt.err {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.network, network)
+			assert.Equal(t, tt.addr, addr)
+		})
+	}
+}
+
+func TestApp_Setup_ServerURL(t *testing.T) {
+	tests := []struct {
+		config []Config
+		url    string
+	}{
+		{config: nil, url: "http://localhost:9000"},
+		{config: []Config{SetAddress("0.0.0.0:8080")}, url: "http://localhost:8080"},
+		{config: []Config{SetAddress("[::]:8080")}, url: "http://localhost:8080"},
+		{config: []Config{SetAddress("127.0.0.1:8080")}, url: "http://127.0.0.1:8080"},
+		{config: []Config{SetAddress("[::1]:8443"), SetTLS("cert.pem", "key.pem")}, url: "https://[::1]:8443"},
+		{config: []Config{SetAddress("unix:/run/app.sock")}, url: "http://localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			app := New(append([]Config{SetDisableSwagger(true)}, tt.config...)...)
+
+			require.NoError(t, app.setup())
+
+			assert.Equal(t, tt.url, app.serverURL)
+			assert.Equal(t, tt.url, app.openAPISpec.Servers[0].URL)
+		})
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "app.sock")
+
+	// a socket left by a previous run is replaced
+	stale, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	app := newListenerApp(SetAddress(unixAddressPrefix + socket))
+
+	ran := make(chan error, 1)
+
+	go func() {
+		ran <- app.Run()
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	t.Cleanup(client.CloseIdleConnections)
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://localhost/ping")
+		if err != nil {
+			return false
+		}
+
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, 5*time.Second, 10*time.Millisecond)
+
+	client.CloseIdleConnections()
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+
+	_, err = os.Stat(socket)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestListen_NotASocket(t *testing.T) {
+	file := writePEM(t, "app.sock", "DATA", nil)
+
+	err := newListenerApp(SetAddress(unixAddressPrefix + file)).Run()
+
+	assert.ErrorContains(t, err, "is not a socket")
+}
+
+func TestListen_TLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	setCertificateCheckInterval(t, 0)
+
+	app := newListenerApp(SetTLS(certFile, keyFile))
+	address, ran := runApp(t, app)
+
+	client := &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.pool, MinVersion: tls.VersionTLS12},
+		DisableKeepAlives: true,
+	}}
+
+	serial := func() int64 {
+		resp, err := client.Get("https://" + address + "/ping")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "pong", string(body))
+
+		return resp.TLS.PeerCertificates[0].SerialNumber.Int64()
+	}
+
+	assert.Equal(t, int64(2), serial())
+
+	// the rotated certificate is served by the next connections
+	rotatedCert, rotatedKey := ca.issue(t, "server", 3, x509.ExtKeyUsageServerAuth)
+
+	for _, file := range [][2]string{{rotatedCert, certFile}, {rotatedKey, keyFile}} {
+		data, err := os.ReadFile(file[0])
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file[1], data, 0o600))
+
+		modTime := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(file[1], modTime, modTime))
+	}
+
+	assert.Equal(t, int64(3), serial())
+
+	// a broken certificate file keeps the previous certificate
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+	assert.Equal(t, int64(3), serial())
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+}
+
+func TestListen_MutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 2, x509.ExtKeyUsageServerAuth)
+	clientCertFile, clientKeyFile := ca.issue(t, "client", 3, x509.ExtKeyUsageClientAuth)
+
+	app := newListenerApp(SetTLS(certFile, keyFile), SetClientCA(ca.file))
+
+	Get(app, "/whoami", func(c *ContextNoRequest) (string, error) {
+		return c.RequestContext().TLSConnectionState().PeerCertificates[0].Subject.CommonName, nil
+	})
+
+	address, ran := runApp(t, app)
+
+	get := func(certificates ...tls.Certificate) (*http.Response, error) {
+		client := &http.Client{Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:      ca.pool,
+				Certificates: certificates,
+				MinVersion:   tls.VersionTLS12,
+			},
+			DisableKeepAlives: true,
+		}}
+
+		return client.Get("https://" + address + "/whoami")
+	}
+
+	_, err := get()
+	assert.Error(t, err)
+
+	clientCertificate, err := tls.LoadX509KeyPair(clientCertFile, clientKeyFile)
+	require.NoError(t, err)
+
+	resp, err := get(clientCertificate)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "client", string(body))
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+}
+
+func TestListen_TLSError(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	port, err := getFreePort()
+	require.NoError(t, err)
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+
+	err = newListenerApp(SetAddress(address), SetTLS(certFile, "missing.pem")).Run()
+	assert.ErrorContains(t, err, "failed to load the TLS certificate")
+
+	err = newListenerApp(SetAddress(address), SetTLS(certFile, keyFile), SetClientCA(keyFile)).Run()
+	assert.ErrorContains(t, err, "failed to read the client CA")
+
+	// the client CA is not silently ignored without TLS
+	err = newListenerApp(SetAddress(address), SetClientCA(ca.file)).Run()
+	assert.ErrorContains(t, err, "the client CA requires a TLS certificate")
+}
+
+func TestCertificateReloader(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	setCertificateCheckInterval(t, 0)
+
+	var logs bytes.Buffer
+
+	reloader, err := newCertificateReloader(certFile, keyFile, slog.New(slog.NewTextHandler(&logs, nil)))
+	require.NoError(t, err)
+
+	serial := func() int64 {
+		certificate, err := reloader.getCertificate(nil)
+		require.NoError(t, err)
+
+		parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+		require.NoError(t, err)
+
+		return parsed.SerialNumber.Int64()
+	}
+
+	// a half-finished rotation is logged once
+	rotatedCert, _ := ca.issue(t, "server", 3, x509.ExtKeyUsageServerAuth)
+
+	data, err := os.ReadFile(rotatedCert)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(certFile, data, 0o600))
+
+	modTime := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+
+	for range 3 {
+		assert.Equal(t, int64(2), serial())
+	}
+
+	assert.Equal(t, 1, strings.Count(logs.String(), "failed to reload the TLS certificate"))
+
+	// a new failed reload is logged again
+	modTime = modTime.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, modTime, modTime))
+
+	assert.Equal(t, int64(2), serial())
+	assert.Equal(t, 2, strings.Count(logs.String(), "failed to reload the TLS certificate"))
+
+	// the files are not checked again within the interval
+	certificateCheckInterval = time.Hour
+
+	assert.Equal(t, int64(2), serial())
+	require.NoError(t, os.WriteFile(certFile, []byte("broken"), 0o600))
+
+	assert.Equal(t, int64(2), serial())
+	assert.Equal(t, int64(2), serial())
+	assert.Equal(t, 2, strings.Count(logs.String(), "failed to reload the TLS certificate"))
+}
+
+// setCertificateCheckInterval sets the interval of the checks of the certificate files for the test.
+func setCertificateCheckInterval(t *testing.T, interval time.Duration) {
+	t.Helper()
+
+	previous := certificateCheckInterval
+	certificateCheckInterval = interval
+
+	t.Cleanup(func() { certificateCheckInterval = previous })
+}
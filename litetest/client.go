@@ -0,0 +1,397 @@
+// Package litetest calls the routes of a lite app in-process, without opening a socket.
+//
+// The requests are typed values of the route Request type, encoded according to their lite tags,
+// and the responses are decoded into the route ResponseBody type, or into a lite.HTTPError:
+//
+//	client := litetest.NewClient(t, app)
+//
+//	litetest.Call(client, getUser, GetUserRequest{ID: 42}).
+//		AssertStatus(http.StatusOK).
+//		AssertHeader("Content-Type", "application/json")
+package litetest
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-lite/lite"
+)
+
+// Client calls the routes of an app in-process.
+type Client struct {
+	tb     testing.TB
+	app    *lite.App
+	header http.Header
+}
+
+// NewClient returns a client calling the routes of the app. The app is set up on the first call, like in Run.
+func NewClient(tb testing.TB, app *lite.App) *Client {
+	return &Client{
+		tb:     tb,
+		app:    app,
+		header: make(http.Header),
+	}
+}
+
+// WithHeader returns a copy of the client sending the header with every request, e.g. an Authorization header.
+func (c *Client) WithHeader(key, value string) *Client {
+	client := *c
+	client.header = c.header.Clone()
+	client.header.Set(key, value)
+
+	return &client
+}
+
+// Call calls the route with the request, encoded according to its lite tags: the params, query and header fields
+// are set in the path, query and headers, and the req=body field is encoded in the first content type of its tag,
+// JSON by default. A string request is sent as text/plain, a []byte request as application/octet-stream.
+func Call[ResponseBody, Request any](
+	c *Client,
+	route lite.Route[ResponseBody, Request],
+	req Request,
+) *Response[ResponseBody] {
+	c.tb.Helper()
+
+	encoded := &request{
+		path:   route.Path(),
+		query:  make(url.Values),
+		header: c.header.Clone(),
+	}
+
+	if err := encoded.encode(reflect.ValueOf(&req).Elem()); err != nil {
+		c.tb.Fatalf("litetest: failed to encode the request of %s %s: %v", route.Method(), route.Path(), err)
+	}
+
+	target := encoded.path
+	if len(encoded.query) > 0 {
+		target += "?" + encoded.query.Encode()
+	}
+
+	httpRequest := httptest.NewRequest(route.Method(), target, bytes.NewReader(encoded.body))
+	httpRequest.Header = encoded.header
+
+	recorder := httptest.NewRecorder()
+	c.app.ServeHTTP(recorder, httpRequest)
+
+	return newResponse[ResponseBody](c.tb, recorder.Result())
+}
+
+// request is an encoded request.
+type request struct {
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+// encode encodes the request value.
+func (r *request) encode(value reflect.Value) error {
+	if value.Kind() == reflect.Interface {
+		value = value.Elem()
+	}
+
+	switch {
+	case !value.IsValid():
+		return nil
+	case value.Kind() == reflect.String:
+		return r.encodeBody(value, "text/plain")
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		return r.encodeBody(value, "application/octet-stream")
+	case value.Kind() == reflect.Struct:
+		return r.encodeFields(value)
+	default:
+		return fmt.Errorf("unsupported request type %s", value.Type())
+	}
+}
+
+// encodeFields encodes the fields of a request struct according to their lite tags.
+func (r *request) encodeFields(value reflect.Value) error {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+		tag := field.Tag.Get("lite")
+
+		if tag == "" {
+			if fieldValue.Kind() == reflect.Struct {
+				if err := r.encodeFields(fieldValue); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		name, options := parseTag(tag)
+
+		switch name {
+		case "req":
+			contentType := "application/json"
+			if len(options) > 0 {
+				contentType = options[0]
+			}
+
+			if err := r.encodeBody(fieldValue, contentType); err != nil {
+				return err
+			}
+		case "params":
+			values := formatValues(fieldValue)
+			if len(values) > 0 {
+				r.path = setPathParam(r.path, tagValue(tag, "params"), url.PathEscape(values[0]))
+			}
+		case "query":
+			for _, v := range formatValues(fieldValue) {
+				r.query.Add(tagValue(tag, "query"), v)
+			}
+		case "header":
+			key := tagValue(tag, "header")
+			if tagValue(tag, "type") == "apiKey" {
+				key = tagValue(tag, "name")
+			}
+
+			for _, v := range formatValues(fieldValue) {
+				r.header.Add(key, v)
+			}
+		}
+	}
+
+	return nil
+}
+
+// encodeBody encodes the request body in the content type.
+func (r *request) encodeBody(value reflect.Value, contentType string) error {
+	var err error
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		r.body, err = json.Marshal(value.Interface())
+	case strings.Contains(contentType, "xml"):
+		r.body, err = xml.Marshal(value.Interface())
+	case contentType == "application/x-www-form-urlencoded":
+		r.body = []byte(formValues(value).Encode())
+	case contentType == "multipart/form-data":
+		return r.encodeMultipart(value)
+	case value.Kind() == reflect.String:
+		r.body = []byte(value.String())
+	case value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8:
+		r.body = value.Bytes()
+	default:
+		return fmt.Errorf("unsupported body type %s for %s", value.Type(), contentType)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// a wildcard content type, e.g. image/*, is replaced by the detected one
+	if strings.Contains(contentType, "*") {
+		contentType = http.DetectContentType(r.body)
+	}
+
+	r.header.Set("Content-Type", contentType)
+
+	return nil
+}
+
+// encodeMultipart encodes the fields of the body struct in a multipart form, the *multipart.FileHeader fields
+// being sent as files, see File.
+func (r *request) encodeMultipart(value reflect.Value) error {
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		key := formKey(field)
+
+		switch files := value.Field(i).Interface().(type) {
+		case *multipart.FileHeader:
+			if err := writeFile(writer, key, files); err != nil {
+				return err
+			}
+		case []*multipart.FileHeader:
+			for _, file := range files {
+				if err := writeFile(writer, key, file); err != nil {
+					return err
+				}
+			}
+		default:
+			for _, v := range formatValues(value.Field(i)) {
+				if err := writer.WriteField(key, v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return err
+	}
+
+	r.body = body.Bytes()
+	r.header.Set("Content-Type", writer.FormDataContentType())
+
+	return nil
+}
+
+// writeFile writes the file in the multipart form.
+func writeFile(writer *multipart.Writer, key string, file *multipart.FileHeader) error {
+	if file == nil {
+		return nil
+	}
+
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{
+		fmt.Sprintf(`form-data; name=%q; filename=%q`, key, file.Filename),
+	}
+	header["Content-Type"] = []string{file.Header.Get("Content-Type")}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		return err
+	}
+
+	defer content.Close()
+
+	_, err = io.Copy(part, content)
+
+	return err
+}
+
+// File returns a multipart file with the content, to set the *multipart.FileHeader fields of the
+// multipart/form-data request bodies. The content type of the file is detected from its content.
+func File(filename string, content []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+
+	writer := multipart.NewWriter(&body)
+
+	header := make(map[string][]string)
+	header["Content-Disposition"] = []string{fmt.Sprintf(`form-data; name="file"; filename=%q`, filename)}
+	header["Content-Type"] = []string{http.DetectContentType(content)}
+
+	part, err := writer.CreatePart(header)
+	if err == nil {
+		_, err = part.Write(content)
+	}
+
+	if err == nil {
+		err = writer.Close()
+	}
+
+	var form *multipart.Form
+	if err == nil {
+		form, err = multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(len(content)) + 1)
+	}
+
+	if err != nil {
+		panic(fmt.Sprintf("litetest: failed to create the file %s: %v", filename, err))
+	}
+
+	return form.File["file"][0]
+}
+
+// formValues returns the url-encoded form of the body struct.
+func formValues(value reflect.Value) url.Values {
+	values := make(url.Values)
+
+	for i := range value.NumField() {
+		for _, v := range formatValues(value.Field(i)) {
+			values.Add(formKey(value.Type().Field(i)), v)
+		}
+	}
+
+	return values
+}
+
+// formKey returns the form key of a body field: its form tag, or its name.
+func formKey(field reflect.StructField) string {
+	if key := field.Tag.Get("form"); key != "" {
+		return key
+	}
+
+	return field.Name
+}
+
+// formatValues returns the string values of a field, none if it is zero, one per element for a slice.
+func formatValues(value reflect.Value) []string {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() || value.IsZero() {
+		return nil
+	}
+
+	if marshaler, ok := value.Interface().(encoding.TextMarshaler); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return nil
+		}
+
+		return []string{string(text)}
+	}
+
+	if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+		var values []string
+
+		for i := range value.Len() {
+			values = append(values, formatValues(value.Index(i))...)
+		}
+
+		return values
+	}
+
+	return []string{fmt.Sprint(value.Interface())}
+}
+
+// setPathParam replaces the :name parameter of the route path with the value.
+func setPathParam(path, name, value string) string {
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		if segment == ":"+name || segment == ":"+name+"?" {
+			segments[i] = value
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// parseTag returns the kind of a lite tag (req, params, query or header) and its options, e.g. the content types
+// of a req=body tag.
+func parseTag(tag string) (name string, options []string) {
+	parts := strings.Split(tag, ",")
+	name, _, _ = strings.Cut(parts[0], "=")
+
+	return name, parts[1:]
+}
+
+// tagValue returns the value of the key in a lite tag.
+func tagValue(tag, key string) string {
+	for _, part := range strings.Split(tag, ",") {
+		if k, v, ok := strings.Cut(part, "="); ok && k == key {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,214 @@
+package litetest
+
+import (
+	"mime/multipart"
+	"net/http"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type user struct {
+	ID     uint64 `json:"id" xml:"id"`
+	Name   string `json:"name" xml:"name" validate:"required,min=3"`
+	Expand string `json:"expand" xml:"expand"`
+	Tenant string `json:"tenant" xml:"tenant"`
+}
+
+type getUserRequest struct {
+	ID     uint64 `lite:"params=id"`
+	Expand string `lite:"query=expand"`
+	Tenant string `lite:"header=X-Tenant"`
+}
+
+type createUserRequest struct {
+	Tenant string `lite:"header=X-Tenant"`
+	Body   user   `lite:"req=body"`
+}
+
+type updateUserRequest struct {
+	ID   uint64 `lite:"params=id"`
+	Body user   `lite:"req=body,application/xml"`
+}
+
+type searchForm struct {
+	Name  string   `form:"name"`
+	Roles []string `form:"roles"`
+}
+
+type searchRequest struct {
+	Body searchForm `lite:"req=body,application/x-www-form-urlencoded"`
+}
+
+type avatarForm struct {
+	Name   string                `form:"name"`
+	Avatar *multipart.FileHeader `form:"avatar"`
+}
+
+type avatarRequest struct {
+	Body avatarForm `lite:"req=body,multipart/form-data"`
+}
+
+type avatarResponse struct {
+	Name     string `json:"name"`
+	Filename string `json:"filename"`
+	Size     int64  `json:"size"`
+}
+
+type routes struct {
+	getUser    lite.Route[user, getUserRequest]
+	createUser lite.Route[user, createUserRequest]
+	updateUser lite.Route[user, updateUserRequest]
+	search     lite.Route[[]string, searchRequest]
+	avatar     lite.Route[avatarResponse, avatarRequest]
+	echo       lite.Route[string, string]
+	ping       lite.Route[string, any]
+}
+
+func newApp(config ...lite.Config) (*lite.App, routes) {
+	app := lite.New(append([]lite.Config{lite.SetDisableSwagger(true)}, config...)...)
+	users := lite.Group(app, "/users")
+
+	return app, routes{
+		getUser: lite.Get(users, "/:id", func(c *lite.ContextWithRequest[getUserRequest]) (user, error) {
+			req, err := c.Requests()
+			if err != nil {
+				return user{}, err
+			}
+
+			if req.ID == 404 {
+				return user{}, lite.NewNotFoundError("User not found")
+			}
+
+			return user{ID: req.ID, Name: "alice", Expand: req.Expand, Tenant: req.Tenant}, nil
+		}),
+		createUser: lite.Post(users, "", func(c *lite.ContextWithRequest[createUserRequest]) (user, error) {
+			req, err := c.Requests()
+			if err != nil {
+				return user{}, err
+			}
+
+			req.Body.Tenant = req.Tenant
+
+			return req.Body, nil
+		}),
+		updateUser: lite.Put(users, "/:id", func(c *lite.ContextWithRequest[updateUserRequest]) (user, error) {
+			req, err := c.Requests()
+			if err != nil {
+				return user{}, err
+			}
+
+			req.Body.ID = req.ID
+
+			return req.Body, nil
+		}),
+		search: lite.Post(app, "/search", func(c *lite.ContextWithRequest[searchRequest]) ([]string, error) {
+			req, err := c.Requests()
+			if err != nil {
+				return nil, err
+			}
+
+			return append([]string{req.Body.Name}, req.Body.Roles...), nil
+		}),
+		avatar: lite.Post(app, "/avatars", func(c *lite.ContextWithRequest[avatarRequest]) (avatarResponse, error) {
+			req, err := c.Requests()
+			if err != nil {
+				return avatarResponse{}, err
+			}
+
+			if req.Body.Avatar == nil {
+				return avatarResponse{}, lite.NewBadRequestError("The avatar is required")
+			}
+
+			return avatarResponse{
+				Name:     req.Body.Name,
+				Filename: req.Body.Avatar.Filename,
+				Size:     req.Body.Avatar.Size,
+			}, nil
+		}),
+		echo: lite.Post(app, "/echo", func(c *lite.ContextWithRequest[string]) (string, error) {
+			return c.Requests()
+		}).SetResponseContentType("text/plain"),
+		ping: lite.Get(app, "/ping", func(c *lite.ContextNoRequest) (string, error) {
+			c.Set("X-Pong", "true")
+
+			return "pong", nil
+		}).SetResponseContentType("text/plain"),
+	}
+}
+
+func TestCall(t *testing.T) {
+	app, routes := newApp()
+	client := NewClient(t, app)
+
+	Call(client, routes.getUser, getUserRequest{ID: 42, Expand: "roles", Tenant: "acme"}).
+		AssertStatus(http.StatusOK).
+		AssertHeader("Content-Type", "application/json").
+		AssertBody(user{ID: 42, Name: "alice", Expand: "roles", Tenant: "acme"})
+
+	Call(client.WithHeader("X-Tenant", "acme"), routes.createUser, createUserRequest{Body: user{Name: "bob"}}).
+		AssertStatus(http.StatusCreated).
+		AssertBody(user{Name: "bob", Tenant: "acme"})
+
+	Call(client, routes.updateUser, updateUserRequest{ID: 7, Body: user{Name: "carol"}}).
+		AssertStatus(http.StatusOK).
+		AssertBody(user{ID: 7, Name: "carol"})
+
+	Call(client, routes.search, searchRequest{Body: searchForm{Name: "dave", Roles: []string{"admin", "ops"}}}).
+		AssertStatus(http.StatusCreated).
+		AssertBody([]string{"dave", "admin", "ops"})
+
+	Call(client, routes.echo, "hello").
+		AssertStatus(http.StatusCreated).
+		AssertHeader("Content-Type", "text/plain").
+		AssertBody("hello")
+
+	Call(client, routes.ping, nil).
+		AssertStatus(http.StatusOK).
+		AssertHeader("X-Pong", "true").
+		AssertBody("pong")
+}
+
+func TestCall_Multipart(t *testing.T) {
+	app, routes := newApp()
+
+	png := []byte("\x89PNG\r\n\x1a\n" + "image")
+	avatar := File("avatar.png", png)
+	assert.Equal(t, "image/png", avatar.Header.Get("Content-Type"))
+
+	Call(NewClient(t, app), routes.avatar, avatarRequest{Body: avatarForm{Name: "eve", Avatar: avatar}}).
+		AssertStatus(http.StatusCreated).
+		AssertBody(avatarResponse{Name: "eve", Filename: "avatar.png", Size: int64(len(png))})
+}
+
+func TestCall_Error(t *testing.T) {
+	app, routes := newApp()
+	client := NewClient(t, app)
+
+	response := Call(client, routes.getUser, getUserRequest{ID: 404}).
+		AssertStatus(http.StatusNotFound).
+		AssertNoViolations()
+
+	require.NotNil(t, response.Error)
+	assert.Equal(t, "User not found", response.Error.Description)
+	assert.Equal(t, user{}, response.Body)
+
+	Call(client, routes.createUser, createUserRequest{Body: user{Name: "al"}}).
+		AssertStatus(http.StatusBadRequest).
+		AssertViolation("Name").
+		AssertViolation("Name", "Key: 'createUserRequest.Body.Name' Error:Field validation for 'Name' failed on the 'min' tag")
+}
+
+func TestCall_ProblemDetails(t *testing.T) {
+	app, routes := newApp(lite.SetErrorFormat(lite.ErrorFormatProblem))
+
+	response := Call(NewClient(t, app), routes.createUser, createUserRequest{}).
+		AssertStatus(http.StatusBadRequest).
+		AssertHeader("Content-Type", "application/problem+json").
+		AssertViolation("Name")
+
+	assert.Equal(t, http.StatusBadRequest, response.Error.Status)
+	assert.NotEmpty(t, response.Error.Title)
+}
@@ -0,0 +1,360 @@
+package litetest
+
+import (
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/go-lite/lite/mime"
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// fiberApp returns the app acquiring the fiber contexts backing the fake contexts, created on the first fake context.
+var fiberApp = sync.OnceValue(func() *fiber.App {
+	return fiber.New()
+})
+
+var (
+	_ lite.Context[string] = &Context[string]{}
+	_ lite.Context[any]    = &Context[any]{}
+)
+
+// Context is a fake lite.Context to unit test the handlers taking a lite.Context, without running the app.
+// Requests returns the preset request, and the calls writing the response (Set, Status, Cookie, SetContentType...)
+// are recorded for the assertions.
+type Context[Request any] struct {
+	request    Request
+	requestErr error
+
+	ctx         context.Context
+	app         *lite.App
+	ip          string
+	ips         []string
+	requestCtx  *fasthttp.RequestCtx
+	fiberCtx    *fiber.Ctx
+	statusCode  int
+	contentType string
+	cookies     []*fiber.Cookie
+}
+
+// contextConfig is the configuration of a fake context, set by the ContextOption.
+type contextConfig struct {
+	ctx        context.Context
+	app        *lite.App
+	method     string
+	url        string
+	header     http.Header
+	ip         string
+	ips        []string
+	requestErr error
+}
+
+// ContextOption configures a fake context.
+type ContextOption func(*contextConfig)
+
+// WithUserContext sets the context returned by Context, e.g. with a deadline or the values set by a middleware.
+func WithUserContext(ctx context.Context) ContextOption {
+	return func(c *contextConfig) {
+		c.ctx = ctx
+	}
+}
+
+// WithApp sets the app returned by App.
+func WithApp(app *lite.App) ContextOption {
+	return func(c *contextConfig) {
+		c.app = app
+	}
+}
+
+// WithRequest sets the method and the URL of the request, relative or absolute. Default is GET /.
+func WithRequest(method, target string) ContextOption {
+	return func(c *contextConfig) {
+		c.method = method
+		c.url = target
+	}
+}
+
+// WithHeader adds a request header, returned by Get.
+func WithHeader(key, value string) ContextOption {
+	return func(c *contextConfig) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithIP sets the client IP returned by IP.
+func WithIP(ip string) ContextOption {
+	return func(c *contextConfig) {
+		c.ip = ip
+	}
+}
+
+// WithIPs sets the client IPs returned by IPs, e.g. from X-Forwarded-For.
+func WithIPs(ips ...string) ContextOption {
+	return func(c *contextConfig) {
+		c.ips = ips
+	}
+}
+
+// WithRequestError sets the error returned by Requests, e.g. a lite.BadRequestError.
+func WithRequestError(err error) ContextOption {
+	return func(c *contextConfig) {
+		c.requestErr = err
+	}
+}
+
+// NewContext returns a fake context whose Requests method returns the request.
+// Its fiber context is released once the test is done.
+func NewContext[Request any](tb testing.TB, req Request, options ...ContextOption) *Context[Request] {
+	tb.Helper()
+
+	config := &contextConfig{
+		ctx:    context.Background(),
+		method: http.MethodGet,
+		url:    "/",
+		header: make(http.Header),
+		ip:     "127.0.0.1",
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	requestCtx := &fasthttp.RequestCtx{}
+	requestCtx.Request.Header.SetMethod(config.method)
+
+	// an absolute URL sets the host of the request
+	target := config.url
+	if uri, err := url.Parse(target); err == nil && uri.Host != "" {
+		requestCtx.Request.Header.SetHost(uri.Host)
+		target = uri.RequestURI()
+	}
+
+	requestCtx.Request.SetRequestURI(target)
+
+	for key, values := range config.header {
+		for _, value := range values {
+			requestCtx.Request.Header.Add(key, value)
+		}
+	}
+
+	ips := config.ips
+	if len(ips) == 0 {
+		ips = []string{config.ip}
+	}
+
+	app := fiberApp()
+	fiberCtx := app.AcquireCtx(requestCtx)
+
+	tb.Cleanup(func() {
+		app.ReleaseCtx(fiberCtx)
+	})
+
+	return &Context[Request]{
+		request:    req,
+		requestErr: config.requestErr,
+		ctx:        config.ctx,
+		app:        config.app,
+		ip:         config.ip,
+		ips:        ips,
+		requestCtx: requestCtx,
+		fiberCtx:   fiberCtx,
+		statusCode: http.StatusOK,
+	}
+}
+
+// StatusCode returns the status code set with Status, 200 by default.
+func (c *Context[Request]) StatusCode() int {
+	return c.statusCode
+}
+
+// ContentType returns the content type set with SetContentType.
+func (c *Context[Request]) ContentType() string {
+	return c.contentType
+}
+
+// Header returns a response header set with Set or Append.
+func (c *Context[Request]) Header(key string) string {
+	return string(c.requestCtx.Response.Header.Peek(key))
+}
+
+// SetCookies returns the cookies set with Cookie and ClearCookie, in order.
+func (c *Context[Request]) SetCookies() []*fiber.Cookie {
+	return c.cookies
+}
+
+// Context returns the user context.
+func (c *Context[Request]) Context() context.Context {
+	return c.ctx
+}
+
+// SetUserContext sets the user context.
+func (c *Context[Request]) SetUserContext(ctx context.Context) {
+	c.ctx = ctx
+}
+
+// Requests returns the preset request.
+func (c *Context[Request]) Requests() (Request, error) {
+	return c.request, c.requestErr
+}
+
+func (c *Context[Request]) Accepts(offers ...string) string {
+	return c.fiberCtx.Accepts(offers...)
+}
+
+func (c *Context[Request]) AcceptsCharsets(offers ...string) string {
+	return c.fiberCtx.AcceptsCharsets(offers...)
+}
+
+func (c *Context[Request]) AcceptsEncodings(offers ...string) string {
+	return c.fiberCtx.AcceptsEncodings(offers...)
+}
+
+func (c *Context[Request]) AcceptsLanguages(offers ...string) string {
+	return c.fiberCtx.AcceptsLanguages(offers...)
+}
+
+// App returns the app set with WithApp.
+func (c *Context[Request]) App() *lite.App {
+	return c.app
+}
+
+// Append appends the values to the response header.
+func (c *Context[Request]) Append(field string, values ...string) {
+	c.fiberCtx.Append(field, values...)
+}
+
+// Attachment sets the Content-Disposition response header of an attachment.
+func (c *Context[Request]) Attachment(filename ...string) {
+	c.fiberCtx.Attachment(filename...)
+}
+
+// BaseURL returns the base URL of the request.
+func (c *Context[Request]) BaseURL() string {
+	return c.fiberCtx.BaseURL()
+}
+
+// BodyRaw returns the raw request body.
+func (c *Context[Request]) BodyRaw() []byte {
+	return c.requestCtx.Request.Body()
+}
+
+// ClearCookie expires the cookies, recorded in SetCookies.
+func (c *Context[Request]) ClearCookie(key ...string) {
+	for _, name := range key {
+		c.cookies = append(c.cookies, &fiber.Cookie{Name: name, Expires: fasthttp.CookieExpireDelete})
+	}
+
+	c.fiberCtx.ClearCookie(key...)
+}
+
+// RequestContext returns the fasthttp request context backing the fake context.
+func (c *Context[Request]) RequestContext() *fasthttp.RequestCtx {
+	return c.requestCtx
+}
+
+// Cookie sets a response cookie, recorded in SetCookies.
+func (c *Context[Request]) Cookie(cookie *fiber.Cookie) {
+	c.cookies = append(c.cookies, cookie)
+	c.fiberCtx.Cookie(cookie)
+}
+
+// Cookies returns the value of a request cookie, set with WithHeader("Cookie", ...).
+func (c *Context[Request]) Cookies(key string, defaultValue ...string) string {
+	return c.fiberCtx.Cookies(key, defaultValue...)
+}
+
+// Download sends the file as an attachment.
+func (c *Context[Request]) Download(file string, filename ...string) error {
+	return c.fiberCtx.Download(file, filename...)
+}
+
+// Request returns the fasthttp request.
+func (c *Context[Request]) Request() *fasthttp.Request {
+	return &c.requestCtx.Request
+}
+
+// Response returns the fasthttp response, with the headers and the cookies which have been set.
+func (c *Context[Request]) Response() *fasthttp.Response {
+	return &c.requestCtx.Response
+}
+
+// Get returns a request header.
+func (c *Context[Request]) Get(key string) string {
+	return c.fiberCtx.Get(key)
+}
+
+// Format writes the body in the format accepted by the request.
+func (c *Context[Request]) Format(body interface{}) error {
+	return c.fiberCtx.Format(body)
+}
+
+// Hostname returns the host of the request URL.
+func (c *Context[Request]) Hostname() string {
+	return c.fiberCtx.Hostname()
+}
+
+// Port returns the port of the request.
+func (c *Context[Request]) Port() string {
+	return c.fiberCtx.Port()
+}
+
+// IP returns the client IP set with WithIP, 127.0.0.1 by default.
+func (c *Context[Request]) IP() string {
+	return c.ip
+}
+
+// IPs returns the client IPs set with WithIPs, the client IP by default.
+func (c *Context[Request]) IPs() []string {
+	return c.ips
+}
+
+// Is returns whether the request content type matches the extension.
+func (c *Context[Request]) Is(extension string) bool {
+	return c.fiberCtx.Is(extension)
+}
+
+// Links sets the Link response header.
+func (c *Context[Request]) Links(link ...string) {
+	c.fiberCtx.Links(link...)
+}
+
+// Method returns the request method, set with WithRequest.
+func (c *Context[Request]) Method(override ...string) string {
+	return c.fiberCtx.Method(override...)
+}
+
+// OriginalURL returns the request URL, set with WithRequest.
+func (c *Context[Request]) OriginalURL() string {
+	return c.fiberCtx.OriginalURL()
+}
+
+// SaveFile saves the multipart file to the path.
+func (c *Context[Request]) SaveFile(fileheader *multipart.FileHeader, path string) error {
+	return c.fiberCtx.SaveFile(fileheader, path)
+}
+
+// Set sets a response header, returned by Header.
+func (c *Context[Request]) Set(key string, val string) {
+	c.fiberCtx.Set(key, val)
+}
+
+// Status sets the status code, returned by StatusCode.
+func (c *Context[Request]) Status(status int) lite.Context[Request] {
+	c.statusCode = status
+	c.fiberCtx.Status(status)
+
+	return c
+}
+
+// SetContentType sets the content type, returned by ContentType.
+func (c *Context[Request]) SetContentType(extension mime.Mime, charset ...string) lite.Context[Request] {
+	c.fiberCtx.Type(extension, charset...)
+	c.contentType = string(c.requestCtx.Response.Header.ContentType())
+
+	return c
+}
@@ -0,0 +1,119 @@
+package litetest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/go-lite/lite/mime"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+// getUser is a handler taking the Context interface, so that it can be unit tested with a fake context.
+func getUser(c lite.Context[getUserRequest]) (user, error) {
+	req, err := c.Requests()
+	if err != nil {
+		return user{}, err
+	}
+
+	if c.Get("Authorization") == "" {
+		return user{}, lite.NewUnauthorizedError()
+	}
+
+	tenant, _ := c.Context().Value(tenantKey{}).(string)
+
+	c.Set("X-Client-IP", c.IP())
+	c.Cookie(&fiber.Cookie{Name: "tenant", Value: tenant})
+	c.Status(http.StatusAccepted).SetContentType(mime.ApplicationJSON)
+
+	return user{ID: req.ID, Name: "alice", Tenant: tenant}, nil
+}
+
+func TestNewContext(t *testing.T) {
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+
+	c := NewContext(t, getUserRequest{ID: 42},
+		WithUserContext(ctx),
+		WithHeader("Authorization", "Bearer token"),
+		WithIP("10.0.0.1"),
+	)
+
+	response, err := getUser(c)
+	require.NoError(t, err)
+
+	assert.Equal(t, user{ID: 42, Name: "alice", Tenant: "acme"}, response)
+	assert.Equal(t, http.StatusAccepted, c.StatusCode())
+	assert.Equal(t, "application/json", c.ContentType())
+	assert.Equal(t, "10.0.0.1", c.Header("X-Client-IP"))
+	require.Len(t, c.SetCookies(), 1)
+	assert.Equal(t, "acme", c.SetCookies()[0].Value)
+}
+
+func TestNewContext_Errors(t *testing.T) {
+	_, err := getUser(NewContext(t, getUserRequest{ID: 42}))
+
+	var httpError lite.HTTPError
+	require.ErrorAs(t, err, &httpError)
+	assert.Equal(t, http.StatusUnauthorized, httpError.Status)
+
+	errInvalid := errors.New("invalid request")
+
+	_, err = getUser(NewContext(t, getUserRequest{}, WithRequestError(errInvalid)))
+	assert.ErrorIs(t, err, errInvalid)
+}
+
+func TestNewContext_Request(t *testing.T) {
+	app := lite.New()
+
+	c := NewContext[any](t, nil,
+		WithApp(app),
+		WithRequest(http.MethodPost, "http://example.com:8080/users?page=2"),
+		WithHeader("Accept", "application/xml"),
+		WithHeader("Cookie", "session=abc"),
+		WithIPs("10.0.0.1", "10.0.0.2"),
+	)
+
+	assert.Same(t, app, c.App())
+	assert.Equal(t, http.MethodPost, c.Method())
+	assert.Equal(t, "/users?page=2", c.OriginalURL())
+	assert.Equal(t, "example.com:8080", c.Hostname())
+	assert.Equal(t, "application/xml", c.Accepts("application/json", "application/xml"))
+	assert.Equal(t, "abc", c.Cookies("session"))
+	assert.Equal(t, "127.0.0.1", c.IP())
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, c.IPs())
+
+	request, err := c.Requests()
+	require.NoError(t, err)
+	assert.Nil(t, request)
+
+	c.ClearCookie("session")
+	require.Len(t, c.SetCookies(), 1)
+	assert.Equal(t, "session", c.SetCookies()[0].Name)
+}
+
+func TestCall_ContextInterface(t *testing.T) {
+	app := lite.New(lite.SetDisableSwagger(true))
+	route := lite.Get(app, "/users/:id", getUser)
+
+	Call(NewClient(t, app).WithHeader("Authorization", "Bearer token"), route, getUserRequest{ID: 42}).
+		AssertStatus(http.StatusAccepted).
+		AssertBody(user{ID: 42, Name: "alice"})
+}
+
+func TestNewContext_Release(t *testing.T) {
+	var c *Context[any]
+
+	t.Run("handler", func(t *testing.T) {
+		c = NewContext[any](t, nil)
+		assert.NotNil(t, c.fiberCtx.Context())
+	})
+
+	// the fiber context is released once the test is done
+	assert.Nil(t, c.fiberCtx.Context())
+}
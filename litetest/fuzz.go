@@ -0,0 +1,1046 @@
+package litetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-lite/lite"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultFuzzRuns is the default number of valid requests generated for each operation.
+	defaultFuzzRuns = 10
+	// maxFuzzDepth is the maximum depth of the generated values, for the recursive schemas.
+	maxFuzzDepth = 5
+	// maxDumpSize is the maximum size of the request and response bodies printed with a failure.
+	maxDumpSize = 512
+)
+
+// fuzzConfig is the configuration of Fuzz, set by the FuzzOption.
+type fuzzConfig struct {
+	seed      int64
+	runs      int
+	bodyLimit int
+	skip      map[string]bool
+}
+
+// FuzzOption configures Fuzz.
+type FuzzOption func(*fuzzConfig)
+
+// WithSeed sets the seed of the generated requests, printed with the failures to reproduce them. Default is random.
+func WithSeed(seed int64) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.seed = seed
+	}
+}
+
+// WithRuns sets the number of valid requests generated for each operation, each one being followed by its invalid
+// variants. Default is 10.
+func WithRuns(runs int) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.runs = runs
+	}
+}
+
+// WithBodyLimit sets the body limit of the app, set with lite.SetBodyLimit, to send the oversized bodies.
+// Default is 4 MB. The limit of a route, set with Route.BodyLimit, is read from the spec.
+func WithBodyLimit(limit int) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.bodyLimit = limit
+	}
+}
+
+// WithSkip skips an operation, its path being in the OpenAPI format, e.g. /users/{id}.
+func WithSkip(method, path string) FuzzOption {
+	return func(c *fuzzConfig) {
+		c.skip[method+" "+path] = true
+	}
+}
+
+// Fuzz calls every operation of the OpenAPI spec of the app with requests generated from its schemas: valid requests,
+// each one followed by invalid variants with missing required fields, wrong types, out of range numbers, files
+// in place of the text fields, malformed and oversized bodies. It fails the test if a request panics, if an invalid
+// request gets a 5xx response, or if a response does not conform to the documented status codes, content types
+// and schemas:
+//
+//	litetest.Fuzz(litetest.NewClient(t, app).WithHeader("Authorization", "Bearer "+token), litetest.WithRuns(50))
+//
+// A request panics when the app answers it with the 500 HTTPError of the panics, which is also the one of the errors
+// mapped to no HTTPError. The failures print the value of the panics recorded by RecordPanics, set as the panic hook
+// of the app. Each failure is printed with its request and the seed reproducing it. The operations streaming their response,
+// the WebSocket and text/event-stream ones, are skipped.
+func Fuzz(c *Client, options ...FuzzOption) {
+	c.tb.Helper()
+
+	config := &fuzzConfig{
+		seed:      time.Now().UnixNano(),
+		runs:      defaultFuzzRuns,
+		bodyLimit: fiber.DefaultBodyLimit,
+		skip:      make(map[string]bool),
+	}
+
+	for _, option := range options {
+		option(config)
+	}
+
+	// the refs are resolved in the copy of the spec, the served one being left as is
+	doc, err := c.app.OpenAPISpec()
+	if err != nil {
+		c.tb.Fatalf("litetest: failed to build the OpenAPI spec: %v", err)
+	}
+
+	f := &fuzzer{
+		client: c,
+		rnd:    rand.New(rand.NewSource(config.seed)), //nolint:gosec
+		seed:   config.seed,
+		limit:  config.bodyLimit,
+	}
+
+	for _, route := range fuzzRoutes(doc, config.skip) {
+		f.fuzz(route, config.runs)
+	}
+
+	c.tb.Logf("litetest: fuzzed %d requests with the seed %d", f.requests, config.seed)
+}
+
+// panicRecorderKey is the context key of the panicRecorder of the requests sent by Fuzz.
+type panicRecorderKey struct{}
+
+// panicRecorder records the panics recovered by the app while handling a request, which may be handled
+// in another goroutine, e.g. with a timeout.
+type panicRecorder struct {
+	mu     sync.Mutex
+	panics []any
+}
+
+func (r *panicRecorder) record(value any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.panics = append(r.panics, value)
+}
+
+func (r *panicRecorder) recorded() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.panics
+}
+
+// RecordPanics is a hook of lite.SetPanicHook recording the panics of the requests sent by Fuzz, so that its failures
+// print the value of the panics. The panics of the other requests are ignored. An app with its own hook calls
+// RecordPanics from it:
+//
+//	app := lite.New(lite.SetPanicHook(litetest.RecordPanics))
+func RecordPanics(ctx context.Context, report lite.PanicReport) {
+	if recorder, ok := ctx.Value(panicRecorderKey{}).(*panicRecorder); ok {
+		recorder.record(report.Value)
+	}
+}
+
+// panicError returns the description of the response if it is the 500 HTTPError answered by the app to a panic,
+// in the JSON or in the Problem Details format. The description is the panic value in dev mode only.
+func panicError(status int, body []byte) (string, bool) {
+	if status != http.StatusInternalServerError {
+		return "", false
+	}
+
+	var httpError struct {
+		Type        string `json:"@type"`
+		Description string `json:"description"`
+		ProblemType string `json:"type"`
+		Detail      string `json:"detail"`
+	}
+
+	if err := json.Unmarshal(body, &httpError); err != nil {
+		return "", false
+	}
+
+	expected := lite.NewInternalServerError()
+
+	switch {
+	case httpError.Type == expected.Type:
+	case strings.HasSuffix(httpError.ProblemType, "/"+expected.Type):
+		httpError.Description = httpError.Detail
+	default:
+		return "", false
+	}
+
+	if httpError.Description != expected.Description && !strings.HasPrefix(httpError.Description, "panic: ") {
+		return "", false
+	}
+
+	return httpError.Description, true
+}
+
+// fuzzRoutes returns the operations of the spec to fuzz, sorted by path and method.
+func fuzzRoutes(doc *openapi3.T, skip map[string]bool) []*routers.Route {
+	var routes []*routers.Route
+
+	paths := doc.Paths.InMatchingOrder()
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		item := doc.Paths.Value(path)
+		operations := item.Operations()
+
+		methods := make([]string, 0, len(operations))
+		for method := range operations {
+			methods = append(methods, method)
+		}
+
+		slices.Sort(methods)
+
+		for _, method := range methods {
+			operation := operations[method]
+			if skip[method+" "+path] || isStreaming(operation) {
+				continue
+			}
+
+			routes = append(routes, &routers.Route{
+				Spec:      doc,
+				Path:      path,
+				PathItem:  item,
+				Method:    method,
+				Operation: operation,
+			})
+		}
+	}
+
+	return routes
+}
+
+// isStreaming returns whether the operation streams its response, a WebSocket or text/event-stream one.
+func isStreaming(operation *openapi3.Operation) bool {
+	if _, ok := operation.Extensions["x-websocket"]; ok {
+		return true
+	}
+
+	for _, response := range operation.Responses.Map() {
+		if response.Value != nil && response.Value.Content.Get("text/event-stream") != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fuzzer generates the requests of the operations and checks their responses.
+type fuzzer struct {
+	client   *Client
+	rnd      *rand.Rand
+	seed     int64
+	limit    int
+	requests int
+}
+
+// fuzzRequest is a generated request, its body being made of map[string]any, []any, string, json.Number, bool
+// and fuzzFile values.
+type fuzzRequest struct {
+	description string
+	invalid     bool
+	params      map[string]string
+	query       url.Values
+	header      http.Header
+	mediaType   string
+	body        any
+	raw         []byte
+}
+
+// fuzzFile is a file of a multipart body, the value of the binary properties.
+type fuzzFile []byte
+
+// fuzz sends the valid requests of the operation, each one followed by its invalid variants, until a request fails.
+func (f *fuzzer) fuzz(route *routers.Route, runs int) {
+	f.client.tb.Helper()
+
+	for run := range runs {
+		valid := f.generate(route)
+		requests := append([]*fuzzRequest{valid}, f.mutate(route, valid)...)
+
+		if run == 0 && valid.mediaType != "" {
+			requests = append(requests, f.oversized(route, valid))
+		}
+
+		for _, req := range requests {
+			if !f.check(route, req) {
+				return
+			}
+		}
+	}
+}
+
+// parameters returns the parameters of the operation, and the ones of its path.
+func parameters(route *routers.Route) []*openapi3.Parameter {
+	var params []*openapi3.Parameter
+
+	for _, ref := range append(route.PathItem.Parameters, route.Operation.Parameters...) {
+		if ref.Value != nil {
+			params = append(params, ref.Value)
+		}
+	}
+
+	return params
+}
+
+// bodySchema returns the schema of the request body in the media type.
+func bodySchema(route *routers.Route, mediaType string) *openapi3.Schema {
+	content := route.Operation.RequestBody.Value.Content[mediaType]
+	if content == nil || content.Schema == nil {
+		return nil
+	}
+
+	return content.Schema.Value
+}
+
+// generate returns a valid request of the operation.
+func (f *fuzzer) generate(route *routers.Route) *fuzzRequest {
+	req := &fuzzRequest{
+		description: "valid request",
+		params:      make(map[string]string),
+		query:       make(url.Values),
+		header:      make(http.Header),
+	}
+
+	for _, param := range parameters(route) {
+		if param.In == openapi3.ParameterInPath || param.Required || f.rnd.Intn(2) == 0 {
+			req.set(param, formatValue(f.value(schemaOf(param), 0)))
+		}
+	}
+
+	if body := route.Operation.RequestBody; body != nil && body.Value != nil && len(body.Value.Content) > 0 {
+		mediaTypes := make([]string, 0, len(body.Value.Content))
+		for mediaType := range body.Value.Content {
+			mediaTypes = append(mediaTypes, mediaType)
+		}
+
+		slices.Sort(mediaTypes)
+
+		req.mediaType = mediaTypes[f.rnd.Intn(len(mediaTypes))]
+		req.body = f.value(bodySchema(route, req.mediaType), 0)
+	}
+
+	return req
+}
+
+// mutate returns the invalid variants of the valid request.
+func (f *fuzzer) mutate(route *routers.Route, valid *fuzzRequest) []*fuzzRequest {
+	var requests []*fuzzRequest
+
+	for _, param := range parameters(route) {
+		if param.Required && param.In != openapi3.ParameterInPath {
+			req := valid.clone(fmt.Sprintf("missing required %s parameter %s", param.In, param.Name))
+			req.del(param)
+			requests = append(requests, req)
+		}
+
+		for _, value := range f.invalidValues(schemaOf(param), false) {
+			formatted := formatValue(value)
+
+			req := valid.clone(fmt.Sprintf("invalid %s parameter %s=%s", param.In, param.Name, formatted))
+			req.set(param, formatted)
+			requests = append(requests, req)
+		}
+	}
+
+	if valid.mediaType == "" {
+		return requests
+	}
+
+	schema := bodySchema(route, valid.mediaType)
+	typed := strings.Contains(valid.mediaType, "json")
+
+	if properties, ok := valid.body.(map[string]any); ok && schema != nil {
+		for _, name := range schema.Required {
+			req := valid.clone("missing required property " + name)
+			delete(req.body.(map[string]any), name)
+			requests = append(requests, req)
+		}
+
+		for _, name := range sortedKeys(schema.Properties) {
+			property := schema.Properties[name].Value
+
+			values := f.invalidValues(property, typed)
+			if valid.mediaType == "multipart/form-data" {
+				// the files are sent as text fields, and the text fields as files
+				if _, isFile := properties[name].(fuzzFile); isFile || property.Format == "binary" {
+					values = append(values, "text")
+				} else {
+					values = append(values, fuzzFile("file"))
+				}
+			}
+
+			for _, value := range values {
+				req := valid.clone(fmt.Sprintf("invalid property %s=%s", name, truncate(formatValue(value))))
+				req.body.(map[string]any)[name] = value
+				requests = append(requests, req)
+			}
+		}
+	}
+
+	for _, value := range f.invalidValues(schema, typed) {
+		req := valid.clone("invalid body " + truncate(formatValue(value)))
+		req.body = value
+		requests = append(requests, req)
+	}
+
+	if typed || strings.Contains(valid.mediaType, "xml") {
+		if encoded, _, err := valid.encode(); err == nil {
+			req := valid.clone("malformed body")
+			req.raw = append([]byte{}, encoded[:len(encoded)/2]...)
+			requests = append(requests, req)
+		}
+	}
+
+	return requests
+}
+
+// oversized returns a variant of the valid request with a body over the limit of the operation.
+func (f *fuzzer) oversized(route *routers.Route, valid *fuzzRequest) *fuzzRequest {
+	limit := f.limit
+
+	switch value := route.Operation.Extensions["x-body-limit"].(type) {
+	case float64:
+		limit = int(value)
+	case json.Number:
+		if n, err := value.Int64(); err == nil {
+			limit = int(n)
+		}
+	}
+
+	req := valid.clone(fmt.Sprintf("body over the limit of %d bytes", limit))
+	req.raw = bytes.Repeat([]byte{'a'}, limit+1)
+
+	return req
+}
+
+// clone returns an invalid copy of the request.
+func (r *fuzzRequest) clone(description string) *fuzzRequest {
+	params := make(map[string]string, len(r.params))
+	for key, value := range r.params {
+		params[key] = value
+	}
+
+	return &fuzzRequest{
+		description: description,
+		invalid:     true,
+		params:      params,
+		query:       cloneValues(r.query),
+		header:      r.header.Clone(),
+		mediaType:   r.mediaType,
+		body:        cloneValue(r.body),
+	}
+}
+
+// set sets the value of the parameter.
+func (r *fuzzRequest) set(param *openapi3.Parameter, value string) {
+	switch param.In {
+	case openapi3.ParameterInPath:
+		r.params[param.Name] = value
+	case openapi3.ParameterInQuery:
+		r.query.Set(param.Name, value)
+	case openapi3.ParameterInHeader:
+		r.header.Set(param.Name, value)
+	case openapi3.ParameterInCookie:
+		r.header.Add("Cookie", (&http.Cookie{Name: param.Name, Value: url.QueryEscape(value)}).String())
+	}
+}
+
+// del removes the parameter.
+func (r *fuzzRequest) del(param *openapi3.Parameter) {
+	switch param.In {
+	case openapi3.ParameterInQuery:
+		r.query.Del(param.Name)
+	case openapi3.ParameterInHeader:
+		r.header.Del(param.Name)
+	}
+}
+
+// target returns the path and the query of the request.
+func (r *fuzzRequest) target(path string) string {
+	for name, value := range r.params {
+		path = strings.ReplaceAll(path, "{"+name+"}", url.PathEscape(value))
+	}
+
+	if len(r.query) > 0 {
+		path += "?" + r.query.Encode()
+	}
+
+	return path
+}
+
+// encode returns the body of the request encoded in its media type, and its content type.
+func (r *fuzzRequest) encode() ([]byte, string, error) {
+	contentType := r.mediaType
+
+	switch {
+	case contentType == "*/*":
+		contentType = "application/octet-stream"
+	case strings.HasSuffix(contentType, "/*"):
+		// a wildcard content type, e.g. image/*, is sent as a concrete one
+		contentType = strings.TrimSuffix(contentType, "*") + "octet-stream"
+	}
+
+	if r.raw != nil {
+		return r.raw, contentType, nil
+	}
+
+	switch {
+	case strings.Contains(contentType, "json"):
+		body, err := json.Marshal(r.body)
+
+		return body, contentType, err
+	case strings.Contains(contentType, "xml"):
+		var body bytes.Buffer
+
+		err := encodeXML(&body, "request", r.body)
+
+		return body.Bytes(), contentType, err
+	case contentType == "application/x-www-form-urlencoded":
+		values := make(url.Values)
+
+		if properties, ok := r.body.(map[string]any); ok {
+			for _, key := range sortedKeys(properties) {
+				for _, value := range elements(properties[key]) {
+					values.Add(key, formatValue(value))
+				}
+			}
+		}
+
+		return []byte(values.Encode()), contentType, nil
+	case contentType == "multipart/form-data":
+		return encodeMultipart(r.body)
+	default:
+		return []byte(formatValue(r.body)), contentType, nil
+	}
+}
+
+// encodeXML encodes the value in an element of the name, the arrays being encoded as repeated elements.
+func encodeXML(buf *bytes.Buffer, name string, value any) error {
+	switch v := value.(type) {
+	case []any:
+		for _, item := range v {
+			if err := encodeXML(buf, name, item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case map[string]any:
+		buf.WriteString("<" + name + ">")
+
+		for _, key := range sortedKeys(v) {
+			if err := encodeXML(buf, key, v[key]); err != nil {
+				return err
+			}
+		}
+
+		buf.WriteString("</" + name + ">")
+
+		return nil
+	default:
+		buf.WriteString("<" + name + ">")
+
+		if err := xml.EscapeText(buf, []byte(formatValue(v))); err != nil {
+			return err
+		}
+
+		buf.WriteString("</" + name + ">")
+
+		return nil
+	}
+}
+
+// encodeMultipart encodes the properties of the body in a multipart form, the fuzzFile values being sent as files.
+func encodeMultipart(body any) ([]byte, string, error) {
+	var buf bytes.Buffer
+
+	writer := multipart.NewWriter(&buf)
+
+	properties, _ := body.(map[string]any)
+
+	for _, key := range sortedKeys(properties) {
+		for _, value := range elements(properties[key]) {
+			var err error
+
+			if file, ok := value.(fuzzFile); ok {
+				var part io.Writer
+
+				if part, err = writer.CreateFormFile(key, key+".bin"); err == nil {
+					_, err = part.Write(file)
+				}
+			} else {
+				err = writer.WriteField(key, formatValue(value))
+			}
+
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+
+	return buf.Bytes(), writer.FormDataContentType(), nil
+}
+
+// check sends the request and checks the response, and returns whether it passed.
+func (f *fuzzer) check(route *routers.Route, req *fuzzRequest) bool {
+	f.client.tb.Helper()
+
+	f.requests++
+
+	var (
+		body        []byte
+		contentType string
+	)
+
+	if req.mediaType != "" {
+		var err error
+		if body, contentType, err = req.encode(); err != nil {
+			f.client.tb.Fatalf("litetest: failed to encode the request of %s %s: %v", route.Method, route.Path, err)
+		}
+	}
+
+	httpRequest := httptest.NewRequest(route.Method, req.target(route.Path), bytes.NewReader(body))
+	httpRequest.Header = f.client.header.Clone()
+
+	for key, values := range req.header {
+		for _, value := range values {
+			httpRequest.Header.Add(key, value)
+		}
+	}
+
+	if contentType != "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+
+	recorder := httptest.NewRecorder()
+
+	panics := f.serve(recorder, httpRequest)
+	result := recorder.Result()
+	responseBody := recorder.Body.Bytes()
+
+	var failure string
+
+	description, panicked := panicError(result.StatusCode, responseBody)
+
+	switch {
+	case len(panics) > 0:
+		failure = fmt.Sprintf("panicked: %v", panics[0])
+	case panicked && strings.HasPrefix(description, "panic: "):
+		failure = "panicked: " + strings.TrimPrefix(description, "panic: ")
+	case panicked:
+		failure = "got the 500 HTTPError of a panic or of an unmapped error, set litetest.RecordPanics " +
+			"as the panic hook of the app to print the panics"
+	case req.invalid && result.StatusCode >= http.StatusInternalServerError:
+		failure = fmt.Sprintf("got a %d response to an invalid request", result.StatusCode)
+	default:
+		if err := lite.ValidateResponse(context.Background(), route, httpRequest, result, responseBody); err != nil {
+			failure = "the response does not conform to the OpenAPI spec: " + err.Error()
+		}
+	}
+
+	if failure == "" {
+		return true
+	}
+
+	f.client.tb.Errorf("litetest: %s %s, %s: %s\nrequest: %s %s\n%s\nresponse: %d %s\n%s\n"+
+		"rerun with litetest.WithSeed(%d) to reproduce",
+		route.Method, route.Path, req.description, failure,
+		httpRequest.Method, httpRequest.URL.RequestURI(), truncate(string(body)),
+		result.StatusCode, result.Header.Get("Content-Type"), truncate(string(responseBody)),
+		f.seed)
+
+	return false
+}
+
+// serve serves the request, and returns the panics recorded by RecordPanics or recovered by serve.
+func (f *fuzzer) serve(w http.ResponseWriter, r *http.Request) (panics []any) {
+	recorder := &panicRecorder{}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			panics = append(recorder.recorded(), recovered)
+		}
+	}()
+
+	f.client.app.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), panicRecorderKey{}, recorder)))
+
+	return recorder.recorded()
+}
+
+// schemaOf returns the schema of the parameter.
+func schemaOf(param *openapi3.Parameter) *openapi3.Schema {
+	if param.Schema == nil {
+		return nil
+	}
+
+	return param.Schema.Value
+}
+
+// value returns a random value valid against the schema.
+func (f *fuzzer) value(schema *openapi3.Schema, depth int) any {
+	if schema == nil || depth > maxFuzzDepth {
+		return "x"
+	}
+
+	if len(schema.Enum) > 0 {
+		return schema.Enum[f.rnd.Intn(len(schema.Enum))]
+	}
+
+	for _, alternatives := range []openapi3.SchemaRefs{schema.OneOf, schema.AnyOf, schema.AllOf} {
+		if len(alternatives) > 0 {
+			return f.value(alternatives[f.rnd.Intn(len(alternatives))].Value, depth+1)
+		}
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger):
+		return f.integer(schema)
+	case schema.Type.Is(openapi3.TypeNumber):
+		return f.number(schema)
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return f.rnd.Intn(2) == 0
+	case schema.Type.Is(openapi3.TypeArray):
+		items := make([]any, int(schema.MinItems)+f.rnd.Intn(3))
+		for i := range items {
+			items[i] = f.value(itemsOf(schema), depth+1)
+		}
+
+		return items
+	case schema.Type.Is(openapi3.TypeObject), len(schema.Properties) > 0:
+		properties := make(map[string]any)
+
+		for _, name := range sortedKeys(schema.Properties) {
+			if slices.Contains(schema.Required, name) || f.rnd.Intn(4) > 0 {
+				properties[name] = f.value(schema.Properties[name].Value, depth+1)
+			}
+		}
+
+		return properties
+	default:
+		return f.string(schema)
+	}
+}
+
+// integer returns a random integer within the bounds of the schema, often one of its bounds.
+func (f *fuzzer) integer(schema *openapi3.Schema) json.Number {
+	low, high := integerBounds(schema)
+
+	candidates := []*big.Int{low, high}
+	if low.Sign() <= 0 && high.Sign() >= 0 {
+		candidates = append(candidates, big.NewInt(0))
+	}
+
+	// a small value, within the bounds
+	from := maxInt(low, big.NewInt(-1000))
+	to := minInt(high, big.NewInt(1000))
+
+	if from.Cmp(to) <= 0 {
+		span := new(big.Int).Sub(to, from).Int64() + 1
+		candidates = append(candidates, new(big.Int).Add(from, big.NewInt(f.rnd.Int63n(span))))
+	}
+
+	return json.Number(candidates[f.rnd.Intn(len(candidates))].String())
+}
+
+// integerBounds returns the bounds of an integer schema, the ones of an int64 by default.
+func integerBounds(schema *openapi3.Schema) (low, high *big.Int) {
+	low = big.NewInt(math.MinInt64)
+	high = big.NewInt(math.MaxInt64)
+
+	if schema.Min != nil {
+		low = integerBound(*schema.Min)
+		if schema.ExclusiveMin {
+			low.Add(low, big.NewInt(1))
+		}
+	}
+
+	if schema.Max != nil {
+		high = integerBound(*schema.Max)
+		if schema.ExclusiveMax {
+			high.Sub(high, big.NewInt(1))
+		}
+	}
+
+	return low, high
+}
+
+// integerBound returns the integer of a bound. The bounds of the 64-bit integers are rounded up to a power of two
+// in a float64, e.g. 2^64 for a uint64, and are brought back to the largest integer.
+func integerBound(bound float64) *big.Int {
+	value, _ := big.NewFloat(bound).Int(nil)
+
+	if bound == math.Pow(2, 63) || bound == math.Pow(2, 64) {
+		value.Sub(value, big.NewInt(1))
+	}
+
+	return value
+}
+
+// number returns a random number within the bounds of the schema, often one of its bounds.
+func (f *fuzzer) number(schema *openapi3.Schema) json.Number {
+	candidates := []float64{f.rnd.Float64() * 1000}
+
+	if schema.Min != nil && !schema.ExclusiveMin {
+		candidates = append(candidates, *schema.Min)
+	}
+
+	if schema.Max != nil && !schema.ExclusiveMax {
+		candidates = append(candidates, *schema.Max)
+	}
+
+	if (schema.Min == nil || *schema.Min < 0) && (schema.Max == nil || *schema.Max > 0) {
+		candidates = append(candidates, 0)
+	}
+
+	value := candidates[f.rnd.Intn(len(candidates))]
+	if schema.Min != nil && value < *schema.Min || schema.Max != nil && value > *schema.Max {
+		value = *schema.Min
+	}
+
+	return json.Number(strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// string returns a random string valid against the format and the length of the schema. A binary string is a file.
+func (f *fuzzer) string(schema *openapi3.Schema) any {
+	switch schema.Format {
+	case "binary":
+		return fuzzFile(f.text(1 + f.rnd.Intn(64)))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(f.text(1 + f.rnd.Intn(16))))
+	case "date-time":
+		return time.Unix(f.rnd.Int63n(1<<32), 0).UTC().Format(time.RFC3339)
+	case "date":
+		return time.Unix(f.rnd.Int63n(1<<32), 0).UTC().Format(time.DateOnly)
+	case "uuid":
+		return fmt.Sprintf("%08x-%04x-4%03x-8%03x-%012x",
+			f.rnd.Uint32(), f.rnd.Intn(1<<16), f.rnd.Intn(1<<12), f.rnd.Intn(1<<12), f.rnd.Int63n(1<<48))
+	case "email":
+		return f.text(1+f.rnd.Intn(8)) + "@example.com"
+	case "uri", "url":
+		return "https://example.com/" + f.text(1+f.rnd.Intn(8))
+	}
+
+	// the empty strings are left out of the parameters, as if they were missing
+	length := max(int(schema.MinLength), 1)
+
+	maxLength := length + 16
+	if schema.MaxLength != nil {
+		maxLength = min(maxLength, int(*schema.MaxLength))
+	}
+
+	if maxLength > length {
+		length += f.rnd.Intn(maxLength - length + 1)
+	}
+
+	return f.text(length)
+}
+
+// text returns random alphanumeric text of the length.
+func (f *fuzzer) text(length int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	text := make([]byte, length)
+	for i := range text {
+		text[i] = alphabet[f.rnd.Intn(len(alphabet))]
+	}
+
+	return string(text)
+}
+
+// invalidValues returns values violating the schema. The values of the wrong type which are still valid as text,
+// e.g. a number for a string, are only returned for the typed formats, e.g. JSON.
+func (f *fuzzer) invalidValues(schema *openapi3.Schema, typed bool) []any {
+	if schema == nil {
+		return nil
+	}
+
+	var values []any
+
+	if len(schema.Enum) > 0 {
+		values = append(values, "not-"+f.text(8))
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeInteger):
+		low, high := integerBounds(schema)
+
+		values = append(values,
+			json.Number(new(big.Int).Sub(low, big.NewInt(1)).String()),
+			json.Number(new(big.Int).Add(high, big.NewInt(1)).String()),
+			json.Number("18446744073709551616"),
+			json.Number("1.5"),
+			"abc",
+		)
+	case schema.Type.Is(openapi3.TypeNumber):
+		values = append(values, json.Number("1e400"), "abc")
+
+		if schema.Min != nil {
+			values = append(values, json.Number(strconv.FormatFloat(*schema.Min-1, 'g', -1, 64)))
+		}
+
+		if schema.Max != nil {
+			values = append(values, json.Number(strconv.FormatFloat(*schema.Max+1, 'g', -1, 64)))
+		}
+	case schema.Type.Is(openapi3.TypeBoolean):
+		values = append(values, "maybe")
+	case schema.Type.Is(openapi3.TypeString):
+		if schema.MaxLength != nil {
+			values = append(values, f.text(int(*schema.MaxLength)+1))
+		}
+
+		if schema.MinLength > 1 {
+			values = append(values, f.text(int(schema.MinLength)-1))
+		}
+
+		if typed && schema.Format != "binary" {
+			values = append(values, json.Number("42"))
+		}
+	case schema.Type.Is(openapi3.TypeArray), schema.Type.Is(openapi3.TypeObject):
+		if typed {
+			values = append(values, "x", json.Number("1"))
+		}
+	}
+
+	return values
+}
+
+// itemsOf returns the schema of the items of an array schema.
+func itemsOf(schema *openapi3.Schema) *openapi3.Schema {
+	if schema.Items == nil {
+		return nil
+	}
+
+	return schema.Items.Value
+}
+
+// formatValue returns the text of a generated value, the arrays being joined with commas.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return string(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case fuzzFile:
+		return string(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+
+		return strings.Join(items, ",")
+	default:
+		encoded, _ := json.Marshal(v)
+
+		return string(encoded)
+	}
+}
+
+// elements returns the items of an array value, or the value itself.
+func elements(value any) []any {
+	if items, ok := value.([]any); ok {
+		return items
+	}
+
+	return []any{value}
+}
+
+// cloneValue returns a deep copy of a generated value.
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		clone := make(map[string]any, len(v))
+		for key, item := range v {
+			clone[key] = cloneValue(item)
+		}
+
+		return clone
+	case []any:
+		clone := make([]any, len(v))
+		for i, item := range v {
+			clone[i] = cloneValue(item)
+		}
+
+		return clone
+	default:
+		return v
+	}
+}
+
+// cloneValues returns a copy of the query values.
+func cloneValues(values url.Values) url.Values {
+	clone := make(url.Values, len(values))
+	for key, value := range values {
+		clone[key] = slices.Clone(value)
+	}
+
+	return clone
+}
+
+// sortedKeys returns the keys of the map in order, for the requests to be reproducible from their seed.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+// truncate truncates the text printed with a failure.
+func truncate(text string) string {
+	if len(text) <= maxDumpSize {
+		return text
+	}
+
+	return text[:maxDumpSize] + fmt.Sprintf("... (%d bytes)", len(text))
+}
+
+func maxInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+
+	return b
+}
+
+func minInt(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,203 @@
+package litetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/rand"
+	"net/http"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-lite/lite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type counterRequest struct {
+	ID   int64   `lite:"params=id"`
+	Body counter `lite:"req=body"`
+}
+
+type createCounterRequest struct {
+	Body counter `lite:"req=body"`
+}
+
+type counter struct {
+	Count int32 `json:"count"`
+}
+
+func TestFuzz(t *testing.T) {
+	app, _ := newApp()
+	tb := &recorder{TB: t}
+
+	Fuzz(NewClient(tb, app), WithSeed(1), WithRuns(20))
+
+	assert.Empty(t, tb.errors)
+}
+
+func TestFuzz_Panic(t *testing.T) {
+	var hooked int
+
+	app := lite.New(lite.SetDisableSwagger(true), lite.SetPanicHook(func(ctx context.Context, report lite.PanicReport) {
+		hooked++
+
+		RecordPanics(ctx, report)
+	}))
+	lite.Get(app, "/panic/:id", func(c *lite.ContextWithRequest[counterRequest]) (counter, error) {
+		panic("boom")
+	})
+
+	tb := &recorder{TB: t}
+	Fuzz(NewClient(tb, app), WithSeed(1))
+
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "GET /panic/{id}, valid request: panicked: boom")
+	assert.Contains(t, tb.errors[0], "rerun with litetest.WithSeed(1) to reproduce")
+
+	// the hook of the app is still called
+	assert.Equal(t, 1, hooked)
+
+	tb = &recorder{TB: t}
+	Fuzz(NewClient(tb, app), WithSkip(http.MethodGet, "/panic/{id}"))
+
+	assert.Empty(t, tb.errors)
+}
+
+func TestFuzz_PanicError(t *testing.T) {
+	for name, test := range map[string]struct {
+		config  []lite.Config
+		failure string
+	}{
+		"json":    {nil, "got the 500 HTTPError of a panic or of an unmapped error"},
+		"problem": {[]lite.Config{lite.SetErrorFormat(lite.ErrorFormatProblem)}, "got the 500 HTTPError of a panic"},
+		"dev": {
+			[]lite.Config{lite.SetEnvironment(lite.EnvironmentDevelopment), lite.SetDevMode(true)}, "panicked: boom",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			app := lite.New(append(test.config, lite.SetDisableSwagger(true))...)
+			lite.Get(app, "/panic/:id", func(c *lite.ContextWithRequest[counterRequest]) (counter, error) {
+				panic("boom")
+			})
+
+			tb := &recorder{TB: t}
+			Fuzz(NewClient(tb, app), WithSeed(1))
+
+			require.Len(t, tb.errors, 1)
+			assert.Contains(t, tb.errors[0], "GET /panic/{id}, valid request: "+test.failure)
+		})
+	}
+}
+
+func TestPanicError(t *testing.T) {
+	_, ok := panicError(http.StatusInternalServerError, []byte(`{"@type":"InternalServerError","description":"db"}`))
+	assert.False(t, ok)
+
+	_, ok = panicError(http.StatusBadRequest, []byte(`{"@type":"InternalServerError"}`))
+	assert.False(t, ok)
+
+	description, ok := panicError(http.StatusInternalServerError,
+		[]byte(`{"type":"/api/contexts/InternalServerError","detail":"panic: boom"}`))
+	assert.True(t, ok)
+	assert.Equal(t, "panic: boom", description)
+}
+
+func TestFuzz_ServerError(t *testing.T) {
+	app := lite.New(lite.SetDisableSwagger(true))
+	lite.Put(app, "/counters/:id", func(c *lite.ContextWithRequest[counterRequest]) (counter, error) {
+		req, err := c.Requests()
+		if err != nil {
+			return counter{}, lite.NewInternalServerError(err.Error())
+		}
+
+		return req.Body, nil
+	})
+
+	tb := &recorder{TB: t}
+	Fuzz(NewClient(tb, app), WithSeed(1))
+
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "PUT /counters/{id}, invalid path parameter id=")
+	assert.Contains(t, tb.errors[0], "got a 500 response to an invalid request")
+}
+
+func TestFuzz_Conformance(t *testing.T) {
+	app := lite.New(lite.SetDisableSwagger(true))
+	lite.Get(app, "/teapot", func(c *lite.ContextNoRequest) (counter, error) {
+		c.Status(http.StatusTeapot)
+
+		return counter{}, nil
+	})
+
+	tb := &recorder{TB: t}
+	Fuzz(NewClient(tb, app), WithSeed(1))
+
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "GET /teapot, valid request: the response does not conform to the OpenAPI spec")
+	assert.Contains(t, tb.errors[0], "status is not supported")
+}
+
+func TestFuzz_BodyLimit(t *testing.T) {
+	app := lite.New(lite.SetDisableSwagger(true), lite.SetBodyLimit(64), lite.SetStreamRequestBody(true))
+	lite.Post(app, "/counters", func(c *lite.ContextWithRequest[createCounterRequest]) (counter, error) {
+		req, err := c.Requests()
+
+		return req.Body, err
+	})
+	lite.Post(app, "/uploads", func(c *lite.ContextWithRequest[string]) (int, error) {
+		req, err := c.Requests()
+
+		return len(req), err
+	}).BodyLimit(1024)
+
+	tb := &recorder{TB: t}
+	Fuzz(NewClient(tb, app), WithSeed(1), WithBodyLimit(64))
+
+	assert.Empty(t, tb.errors)
+}
+
+func TestFuzzer_Values(t *testing.T) {
+	f := &fuzzer{rnd: rand.New(rand.NewSource(1))} //nolint:gosec
+
+	uint64Schema := openapi3.NewIntegerSchema().WithMin(0).WithMax(18446744073709551615)
+	low, high := integerBounds(uint64Schema)
+	assert.Equal(t, "0", low.String())
+	assert.Equal(t, "18446744073709551615", high.String())
+
+	assert.Contains(t, f.invalidValues(uint64Schema, false), json.Number("-1"))
+	assert.Contains(t, f.invalidValues(uint64Schema, false), json.Number("18446744073709551616"))
+
+	// a number is a valid text, it is only a wrong type in JSON
+	assert.Empty(t, f.invalidValues(openapi3.NewStringSchema(), false))
+	assert.Equal(t, []any{json.Number("42")}, f.invalidValues(openapi3.NewStringSchema(), true))
+
+	schema := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema().WithMinLength(3).WithMaxLength(5)).
+		WithProperty("role", openapi3.NewStringSchema().WithEnum("admin", "user")).
+		WithProperty("avatar", openapi3.NewStringSchema().WithFormat("binary"))
+	schema.Required = []string{"name", "role", "avatar"}
+
+	for range 20 {
+		value, ok := f.value(schema, 0).(map[string]any)
+		require.True(t, ok)
+		assert.Len(t, value["name"], len(value["name"].(string)))
+		assert.GreaterOrEqual(t, len(value["name"].(string)), 3)
+		assert.LessOrEqual(t, len(value["name"].(string)), 5)
+		assert.Contains(t, []any{"admin", "user"}, value["role"])
+		assert.IsType(t, fuzzFile{}, value["avatar"])
+	}
+}
+
+func TestEncodeXML(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := encodeXML(&buf, "request", map[string]any{
+		"name":  "a<b",
+		"roles": []any{"admin", "user"},
+		"id":    json.Number("42"),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "<request><id>42</id><name>a&lt;b</name><roles>admin</roles><roles>user</roles></request>", buf.String())
+}
@@ -0,0 +1,149 @@
+package litetest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/invopop/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	// updateFlag is the test flag rewriting the golden files, the usual Go idiom.
+	updateFlag = "update"
+	// updateEnv is the environment variable rewriting the golden files, e.g. when the flag is not parsed.
+	updateEnv = "LITETEST_UPDATE"
+)
+
+// init registers the -update flag, unless a package imported by the test binary already has one, which is then
+// shared. The test packages importing litetest use this flag rather than defining their own.
+func init() {
+	if flag.Lookup(updateFlag) == nil {
+		flag.Bool(updateFlag, false, "rewrite the golden files of litetest.AssertOpenAPISpec")
+	}
+}
+
+// update returns whether the golden files are rewritten, with the -update flag or the LITETEST_UPDATE=1 environment
+// variable.
+func update() bool {
+	if f := flag.Lookup(updateFlag); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			if value, ok := getter.Get().(bool); ok && value {
+				return true
+			}
+		}
+	}
+
+	return os.Getenv(updateEnv) == "1"
+}
+
+// AssertOpenAPISpec compares the OpenAPI spec of the app with the golden file, in YAML if its extension is
+// .yaml or .yml, in JSON otherwise, and prints the diff on failure. The golden file is rewritten when the tests
+// run with the -update flag, or with the LITETEST_UPDATE=1 environment variable:
+//
+//	go test ./... -run TestOpenAPISpec -update
+func AssertOpenAPISpec(tb testing.TB, app *lite.App, golden string) {
+	tb.Helper()
+
+	spec, err := app.OpenAPISpec()
+	if err != nil {
+		tb.Fatalf("litetest: failed to build the OpenAPI spec: %v", err)
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		tb.Fatalf("litetest: failed to marshal the OpenAPI spec: %v", err)
+	}
+
+	actual, err := normalizeSpec(data, isYAML(golden))
+	if err != nil {
+		tb.Fatalf("litetest: failed to normalize the OpenAPI spec: %v", err)
+	}
+
+	if update() {
+		if err = os.MkdirAll(filepath.Dir(golden), 0o750); err == nil {
+			err = os.WriteFile(golden, actual, 0o600)
+		}
+
+		if err != nil {
+			tb.Fatalf("litetest: failed to update the golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(golden)
+	if errors.Is(err, fs.ErrNotExist) {
+		tb.Fatalf("litetest: the golden file %s does not exist, run the test with -update to create it", golden)
+	}
+
+	if err != nil {
+		tb.Fatalf("litetest: failed to read the golden file: %v", err)
+	}
+
+	// the golden file may have been edited or formatted by hand
+	if expected, err = normalizeSpec(expected, isYAML(golden)); err != nil {
+		tb.Fatalf("litetest: failed to parse the golden file %s: %v", golden, err)
+	}
+
+	if bytes.Equal(expected, actual) {
+		return
+	}
+
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(expected)),
+		B:        difflib.SplitLines(string(actual)),
+		FromFile: golden,
+		ToFile:   "OpenAPI spec",
+		Context:  3,
+	})
+
+	tb.Errorf("litetest: the OpenAPI spec differs from %s, run the test with -update "+
+		"if the change is intended:\n%s", golden, diff)
+}
+
+// normalizeSpec returns the spec in JSON or YAML with its keys sorted, so that the golden files
+// are compared regardless of their formatting.
+func normalizeSpec(data []byte, toYAML bool) ([]byte, error) {
+	var spec any
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+
+	if toYAML {
+		return yaml.Marshal(spec)
+	}
+
+	normalized, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(normalized, '\n'), nil
+}
+
+// isYAML returns whether the golden file is in YAML.
+func isYAML(golden string) bool {
+	extension := strings.ToLower(filepath.Ext(golden))
+
+	return extension == ".yaml" || extension == ".yml"
+}
+
+// FailInvalidResponses returns a hook of lite.SetResponseValidation failing the test on the responses which do not
+// conform to the OpenAPI spec, with the path of the invalid value:
+//
+//	app := lite.New(lite.SetResponseValidation(litetest.FailInvalidResponses(t)))
+func FailInvalidResponses(tb testing.TB) func(ctx context.Context, err *lite.ResponseValidationError) {
+	return func(_ context.Context, err *lite.ResponseValidationError) {
+		tb.Errorf("litetest: %v", err)
+	}
+}
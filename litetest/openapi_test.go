@@ -0,0 +1,99 @@
+package litetest
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssertOpenAPISpec(t *testing.T) {
+	app, _ := newApp()
+
+	AssertOpenAPISpec(t, app, "testdata/openapi.yaml")
+}
+
+func TestAssertOpenAPISpec_Update(t *testing.T) {
+	require.NoError(t, flag.Set(updateFlag, "true"))
+	t.Cleanup(func() {
+		require.NoError(t, flag.Set(updateFlag, "false"))
+	})
+
+	assertUpdated(t)
+}
+
+func TestAssertOpenAPISpec_UpdateEnv(t *testing.T) {
+	t.Setenv(updateEnv, "1")
+
+	assertUpdated(t)
+}
+
+func assertUpdated(t *testing.T) {
+	t.Helper()
+
+	for _, golden := range []string{"openapi.json", "openapi.yaml"} {
+		t.Run(golden, func(t *testing.T) {
+			app, _ := newApp()
+			path := filepath.Join(t.TempDir(), "specs", golden)
+
+			AssertOpenAPISpec(t, app, path)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Contains(t, string(data), "/users/{id}")
+		})
+	}
+}
+
+func TestAssertOpenAPISpec_Diff(t *testing.T) {
+	golden := filepath.Join(t.TempDir(), "openapi.json")
+
+	app, _ := newApp()
+
+	// the golden file is compared regardless of its formatting
+	require.NoError(t, os.WriteFile(golden, []byte(`{"openapi":"3.0.3","paths":{}}`), 0o600))
+
+	tb := &recorder{TB: t}
+	AssertOpenAPISpec(tb, app, golden)
+
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "-update")
+	assert.Contains(t, tb.errors[0], "--- "+golden)
+	assert.Contains(t, tb.errors[0], "+++ OpenAPI spec")
+	assert.Contains(t, tb.errors[0], `+    "/users/{id}": {`)
+	assert.Contains(t, tb.errors[0], `   "openapi": "3.0.3",`)
+}
+
+func TestNormalizeSpec(t *testing.T) {
+	normalized, err := normalizeSpec([]byte("info:\n    title: API\nopenapi: 3.0.3\n"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"info\": {\n    \"title\": \"API\"\n  },\n  \"openapi\": \"3.0.3\"\n}\n", string(normalized))
+
+	normalized, err = normalizeSpec([]byte(`{"openapi":"3.0.3","info":{"title":"API"}}`), true)
+	require.NoError(t, err)
+	assert.Equal(t, "info:\n    title: API\nopenapi: 3.0.3\n", string(normalized))
+
+	_, err = normalizeSpec([]byte("{"), false)
+	assert.Error(t, err)
+}
+
+func TestFailInvalidResponses(t *testing.T) {
+	tb := &recorder{TB: t}
+
+	app := lite.New(lite.SetDisableSwagger(true), lite.SetResponseValidation(FailInvalidResponses(tb)))
+	ping := lite.Get(app, "/ping", func(c *lite.ContextNoRequest) (string, error) {
+		c.Status(http.StatusAccepted)
+
+		return "pong", nil
+	}).SetResponseContentType("text/plain")
+
+	Call(NewClient(t, app), ping, nil).AssertStatus(http.StatusAccepted)
+
+	require.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "litetest: GET /ping: the 202 response does not conform to the OpenAPI spec")
+}
@@ -0,0 +1,195 @@
+package litetest
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/stretchr/testify/assert"
+)
+
+// Response is the response of a route call.
+type Response[ResponseBody any] struct {
+	StatusCode int
+	Header     http.Header
+	RawBody    []byte
+
+	// Body is the decoded response body of a successful call.
+	Body ResponseBody
+	// Error is the decoded error of a failed call, with a status code of 400 or more.
+	Error *lite.HTTPError
+
+	tb testing.TB
+}
+
+// problem is a problem details error, with its violations.
+type problem struct {
+	Type       string           `json:"type" xml:"type"`
+	Title      string           `json:"title" xml:"title"`
+	Status     int              `json:"status" xml:"status"`
+	Detail     string           `json:"detail" xml:"detail"`
+	Violations []lite.Violation `json:"violations" xml:"-"`
+}
+
+func newResponse[ResponseBody any](tb testing.TB, resp *http.Response) *Response[ResponseBody] {
+	tb.Helper()
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		tb.Fatalf("litetest: failed to read the response: %v", err)
+	}
+
+	response := &Response[ResponseBody]{
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		RawBody:    body,
+		tb:         tb,
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		response.Error = decodeError(resp.StatusCode, mediaType, body)
+	} else if err = decodeBody(mediaType, body, &response.Body); err != nil {
+		tb.Errorf("litetest: failed to decode the %s response: %v", mediaType, err)
+	}
+
+	return response
+}
+
+// decodeBody decodes the response body in the content type.
+func decodeBody(mediaType string, body []byte, dst any) error {
+	if len(body) == 0 {
+		return nil
+	}
+
+	switch target := dst.(type) {
+	case *string:
+		*target = string(body)
+
+		return nil
+	case *[]byte:
+		*target = body
+
+		return nil
+	}
+
+	switch {
+	case strings.Contains(mediaType, "json"):
+		return json.Unmarshal(body, dst)
+	case strings.Contains(mediaType, "xml"):
+		return xml.Unmarshal(body, dst)
+	default:
+		// the other responses, e.g. files, are read from RawBody
+		return nil
+	}
+}
+
+// decodeError decodes the error response, in the lite format or in the problem details format.
+func decodeError(status int, mediaType string, body []byte) *lite.HTTPError {
+	httpError := &lite.HTTPError{Status: status}
+
+	var err error
+
+	switch {
+	case strings.HasPrefix(mediaType, "application/problem+"):
+		var details problem
+
+		if strings.HasSuffix(mediaType, "json") {
+			err = json.Unmarshal(body, &details)
+		} else {
+			err = xml.Unmarshal(body, &details)
+		}
+
+		httpError.Context = details.Type
+		httpError.Title = details.Title
+		httpError.Description = details.Detail
+		httpError.Violations = details.Violations
+	case strings.Contains(mediaType, "json"):
+		err = json.Unmarshal(body, httpError)
+	case strings.Contains(mediaType, "xml"):
+		err = xml.Unmarshal(body, httpError)
+	default:
+		httpError.Description = string(body)
+	}
+
+	if err != nil {
+		httpError.Description = string(body)
+	}
+
+	return httpError
+}
+
+// AssertStatus asserts the status code of the response.
+func (r *Response[ResponseBody]) AssertStatus(status int) *Response[ResponseBody] {
+	r.tb.Helper()
+
+	if !assert.Equal(r.tb, status, r.StatusCode, "status code") && r.Error != nil {
+		r.tb.Logf("error: %v", *r.Error)
+	}
+
+	return r
+}
+
+// AssertHeader asserts the value of a response header. The Content-Type is compared without its parameters.
+func (r *Response[ResponseBody]) AssertHeader(key, value string) *Response[ResponseBody] {
+	r.tb.Helper()
+
+	actual := r.Header.Get(key)
+	if http.CanonicalHeaderKey(key) == "Content-Type" && !strings.Contains(value, ";") {
+		actual, _, _ = strings.Cut(actual, ";")
+	}
+
+	assert.Equal(r.tb, value, strings.TrimSpace(actual), "header %s", key)
+
+	return r
+}
+
+// AssertBody asserts the decoded response body.
+func (r *Response[ResponseBody]) AssertBody(body ResponseBody) *Response[ResponseBody] {
+	r.tb.Helper()
+
+	assert.Equal(r.tb, body, r.Body, "response body")
+
+	return r
+}
+
+// AssertViolation asserts that the error response has a violation of the property path,
+// with the message if one is given.
+func (r *Response[ResponseBody]) AssertViolation(propertyPath string, message ...string) *Response[ResponseBody] {
+	r.tb.Helper()
+
+	if r.Error == nil {
+		r.tb.Errorf("expected a violation of %s, got a %d response", propertyPath, r.StatusCode)
+
+		return r
+	}
+
+	for _, violation := range r.Error.Violations {
+		if violation.PropertyPath == propertyPath && (len(message) == 0 || violation.Message == message[0]) {
+			return r
+		}
+	}
+
+	r.tb.Errorf("expected a violation of %s %v, got %v", propertyPath, message, r.Error.Violations)
+
+	return r
+}
+
+// AssertNoViolations asserts that the response has no violations.
+func (r *Response[ResponseBody]) AssertNoViolations() *Response[ResponseBody] {
+	r.tb.Helper()
+
+	if r.Error != nil && len(r.Error.Violations) > 0 {
+		r.tb.Errorf("expected no violations, got %v", r.Error.Violations)
+	}
+
+	return r
+}
@@ -0,0 +1,88 @@
+package litetest
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-lite/lite"
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder records the failures of the assertions.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestDecodeError(t *testing.T) {
+	tests := []struct {
+		name      string
+		mediaType string
+		body      string
+		expected  lite.HTTPError
+	}{
+		{
+			name:      "json",
+			mediaType: "application/json",
+			body:      `{"@type":"NotFound","status":404,"description":"User not found"}`,
+			expected:  lite.HTTPError{Type: "NotFound", Status: 404, Description: "User not found"},
+		},
+		{
+			name:      "problem json",
+			mediaType: "application/problem+json",
+			body:      `{"type":"/errors/NotFound","title":"Not found","status":404,"detail":"User not found"}`,
+			expected:  lite.HTTPError{Context: "/errors/NotFound", Title: "Not found", Status: 404, Description: "User not found"},
+		},
+		{
+			name:      "problem xml",
+			mediaType: "application/problem+xml",
+			body:      `<problem xmlns="urn:ietf:rfc:7807"><title>Not found</title><detail>User not found</detail></problem>`,
+			expected:  lite.HTTPError{Title: "Not found", Status: 404, Description: "User not found"},
+		},
+		{
+			name:      "html",
+			mediaType: "text/html",
+			body:      "<h1>Not found</h1>",
+			expected:  lite.HTTPError{Status: 404, Description: "<h1>Not found</h1>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpError := decodeError(http.StatusNotFound, tt.mediaType, []byte(tt.body))
+			assert.Equal(t, tt.expected, *httpError)
+		})
+	}
+}
+
+func TestResponse_Assertions(t *testing.T) {
+	tb := &recorder{TB: t}
+
+	response := &Response[string]{
+		StatusCode: http.StatusBadRequest,
+		Header:     http.Header{"Content-Type": []string{"application/json; charset=utf-8"}},
+		Error: &lite.HTTPError{
+			Status:     http.StatusBadRequest,
+			Violations: []lite.Violation{{PropertyPath: "Name", Message: "required"}},
+		},
+		tb: tb,
+	}
+
+	response.
+		AssertHeader("Content-Type", "application/json").
+		AssertViolation("Name").
+		AssertViolation("Name", "required")
+	assert.Empty(t, tb.errors)
+
+	response.AssertViolation("Email").AssertNoViolations()
+	assert.Len(t, tb.errors, 2)
+
+	response = &Response[string]{StatusCode: http.StatusOK, Body: "pong", tb: tb}
+	response.AssertViolation("Name")
+	assert.Len(t, tb.errors, 3)
+}
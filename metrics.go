@@ -0,0 +1,162 @@
+package lite
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// metricsPath is the path of the metrics of the routes on the admin listener.
+const metricsPath = "/metrics"
+
+// latencyBuckets are the upper bounds in seconds of the buckets of the latency histograms, like the Prometheus defaults.
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// labelEscaper escapes the label values of the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// routeMetrics counts the requests and measures their latency per route of the app.
+type routeMetrics struct {
+	mu     sync.Mutex
+	routes map[routeMetricsKey]*routeStats
+}
+
+type routeMetricsKey struct {
+	method string
+	route  string
+}
+
+// routeStats are the metrics of a route: the requests per status and the histogram of their latency.
+type routeStats struct {
+	statuses map[int]uint64
+	buckets  []uint64 // requests per bucket of latencyBuckets, not cumulated
+	count    uint64
+	sum      float64 // total latency in seconds
+}
+
+func newRouteMetrics() *routeMetrics {
+	return &routeMetrics{routes: make(map[routeMetricsKey]*routeStats)}
+}
+
+// metricsMiddleware records the metrics of the requests matching a route of the app, the errors being rendered first.
+func (s *App) metricsMiddleware(c *fiber.Ctx) error {
+	start := time.Now()
+
+	if err := c.Next(); err != nil {
+		if err = s.errorHandler(c, err); err != nil {
+			return err
+		}
+	}
+
+	if route, ok := matchedRoute(c); ok {
+		s.metrics.record(c.Method(), route, c.Response().StatusCode(), time.Since(start))
+	}
+
+	return nil
+}
+
+func (m *routeMetrics) record(method, route string, status int, latency time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := routeMetricsKey{method: method, route: route}
+
+	stats, ok := m.routes[key]
+	if !ok {
+		stats = &routeStats{
+			statuses: make(map[int]uint64),
+			buckets:  make([]uint64, len(latencyBuckets)),
+		}
+		m.routes[key] = stats
+	}
+
+	seconds := latency.Seconds()
+
+	stats.statuses[status]++
+	stats.count++
+	stats.sum += seconds
+
+	if i, _ := slices.BinarySearch(latencyBuckets, seconds); i < len(latencyBuckets) {
+		stats.buckets[i]++
+	}
+}
+
+// handler serves the metrics in the Prometheus text format.
+func (m *routeMetrics) handler(c *fiber.Ctx) error {
+	c.Set(HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+
+	return c.SendString(m.String())
+}
+
+// String returns the metrics in the Prometheus text format, sorted by route and method.
+func (m *routeMetrics) String() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]routeMetricsKey, 0, len(m.routes))
+	for key := range m.routes {
+		keys = append(keys, key)
+	}
+
+	slices.SortFunc(keys, func(a, b routeMetricsKey) int {
+		if c := strings.Compare(a.route, b.route); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.method, b.method)
+	})
+
+	var b strings.Builder
+
+	b.WriteString("# HELP lite_requests_total Number of requests per route and status.\n")
+	b.WriteString("# TYPE lite_requests_total counter\n")
+
+	for _, key := range keys {
+		stats := m.routes[key]
+
+		statuses := make([]int, 0, len(stats.statuses))
+		for status := range stats.statuses {
+			statuses = append(statuses, status)
+		}
+
+		slices.Sort(statuses)
+
+		for _, status := range statuses {
+			fmt.Fprintf(&b, "lite_requests_total{%s,status=\"%d\"} %d\n", key.labels(), status, stats.statuses[status])
+		}
+	}
+
+	b.WriteString("# HELP lite_request_duration_seconds Latency of the requests per route.\n")
+	b.WriteString("# TYPE lite_request_duration_seconds histogram\n")
+
+	for _, key := range keys {
+		m.routes[key].write(&b, key.labels())
+	}
+
+	return b.String()
+}
+
+func (k routeMetricsKey) labels() string {
+	return `method="` + labelEscaper.Replace(k.method) + `",route="` + labelEscaper.Replace(k.route) + `"`
+}
+
+// write writes the latency histogram of the route, whose buckets are cumulated.
+func (s *routeStats) write(w io.Writer, labels string) {
+	var cumulated uint64
+
+	for i, bound := range latencyBuckets {
+		cumulated += s.buckets[i]
+		fmt.Fprintf(w, "lite_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n",
+			labels, strconv.FormatFloat(bound, 'g', -1, 64), cumulated)
+	}
+
+	fmt.Fprintf(w, "lite_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.count)
+	fmt.Fprintf(w, "lite_request_duration_seconds_sum{%s} %s\n", labels, strconv.FormatFloat(s.sum, 'g', -1, 64))
+	fmt.Fprintf(w, "lite_request_duration_seconds_count{%s} %d\n", labels, s.count)
+}
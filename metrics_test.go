@@ -0,0 +1,81 @@
+package lite
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	app := New(SetAdminAddress("127.0.0.1:0"))
+
+	Get(app, "/users/:id", func(c *ContextNoRequest) (string, error) {
+		if strings.HasSuffix(c.OriginalURL(), "/0") {
+			return "", NewNotFoundError("user not found")
+		}
+
+		return "bob", nil
+	})
+
+	Get(app, "/panic", func(c *ContextNoRequest) (string, error) {
+		panic(errors.New("boom"))
+	})
+
+	for _, target := range []string{"/users/1", "/users/2", "/users/0", "/panic", "/unknown"} {
+		_, err := app.app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		require.NoError(t, err)
+	}
+
+	metrics := app.metrics.String()
+
+	assert.Contains(t, metrics, `lite_requests_total{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, metrics, `lite_requests_total{method="GET",route="/users/:id",status="404"} 1`)
+	assert.Contains(t, metrics, `lite_requests_total{method="GET",route="/panic",status="500"} 1`)
+	assert.Contains(t, metrics, `lite_request_duration_seconds_count{method="GET",route="/users/:id"} 3`)
+	assert.Contains(t, metrics, `lite_request_duration_seconds_bucket{method="GET",route="/users/:id",le="+Inf"} 3`)
+
+	// the unknown routes are not recorded
+	assert.NotContains(t, metrics, `route="/unknown"`)
+	assert.NotContains(t, metrics, `route="/"`)
+
+	// the app has no metrics without admin listener
+	assert.Nil(t, New().metrics)
+}
+
+func TestRouteMetrics_String(t *testing.T) {
+	metrics := newRouteMetrics()
+
+	metrics.record(http.MethodPost, "/users", http.StatusCreated, 20*time.Millisecond)
+	metrics.record(http.MethodGet, "/users", http.StatusOK, 5*time.Millisecond)
+	metrics.record(http.MethodGet, "/users", http.StatusOK, time.Minute)
+	metrics.record(http.MethodGet, `/a"b`, http.StatusOK, time.Millisecond)
+
+	expected := `# HELP lite_requests_total Number of requests per route and status.
+# TYPE lite_requests_total counter
+lite_requests_total{method="GET",route="/a\"b",status="200"} 1
+lite_requests_total{method="GET",route="/users",status="200"} 2
+lite_requests_total{method="POST",route="/users",status="201"} 1
+# HELP lite_request_duration_seconds Latency of the requests per route.
+# TYPE lite_request_duration_seconds histogram
+`
+
+	assert.Contains(t, metrics.String(), expected)
+
+	for _, line := range []string{
+		`lite_request_duration_seconds_bucket{method="GET",route="/users",le="0.005"} 1`,
+		`lite_request_duration_seconds_bucket{method="GET",route="/users",le="10"} 1`,
+		`lite_request_duration_seconds_bucket{method="GET",route="/users",le="+Inf"} 2`,
+		`lite_request_duration_seconds_sum{method="GET",route="/users"} 60.005`,
+		`lite_request_duration_seconds_count{method="GET",route="/users"} 2`,
+		`lite_request_duration_seconds_bucket{method="POST",route="/users",le="0.01"} 0`,
+		`lite_request_duration_seconds_bucket{method="POST",route="/users",le="0.025"} 1`,
+	} {
+		assert.Contains(t, metrics.String(), line+"\n")
+	}
+}
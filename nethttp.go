@@ -0,0 +1,316 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// Backend is the HTTP server which serves the app in Run.
+type Backend int
+
+const (
+	// BackendFiber serves the app with fiber and fasthttp. It is the default backend.
+	BackendFiber Backend = iota
+	// BackendNetHTTP serves the app with net/http, with HTTP/2 over TLS and the net/http middlewares,
+	// through the net/http adapter of ServeHTTP, with its limits.
+	BackendNetHTTP
+)
+
+// requestContextKey is the user value of the fasthttp request holding the context of the net/http request.
+type requestContextKey struct{}
+
+// httpBackend holds the state of the app served with net/http, shared by its groups.
+type httpBackend struct {
+	once    sync.Once
+	handler fasthttp.RequestHandler
+	err     error
+
+	middleware []func(http.Handler) http.Handler
+	server     atomic.Pointer[http.Server]
+}
+
+// SetBackend sets the HTTP server which serves the app in Run. Default is BackendFiber.
+func SetBackend(backend Backend) Config {
+	return func(s *App) {
+		s.backend = backend
+	}
+}
+
+// SetHTTPMiddleware sets the net/http middlewares wrapping the app served with BackendNetHTTP,
+// the first one being the outermost.
+func SetHTTPMiddleware(middleware ...func(http.Handler) http.Handler) Config {
+	return func(s *App) {
+		s.http.middleware = append(s.http.middleware, middleware...)
+	}
+}
+
+// init sets the app up on the first request, unless Run has set it up. The specs are not saved to files.
+func (b *httpBackend) init(setup func() error, app *fiber.App) error {
+	b.once.Do(func() {
+		if setup != nil {
+			b.err = setup()
+		}
+
+		b.handler = app.Handler()
+	})
+
+	return b.err
+}
+
+// ServeHTTP is a net/http adapter of the app, so that it can be used as an http.Handler, with httptest or mounted
+// on a ServeMux. It is not a net/http implementation of the app: the request is copied to a fasthttp request, which
+// goes through the same fiber routes, middlewares and error handling, and the fasthttp response is copied back.
+// The adapter has the limits of the copies:
+//   - the request body is read in memory up to the body limit, even with SetStreamRequestBody, so a MultipartStream
+//     reads its parts from memory rather than from the connection;
+//   - the response is written once the handlers return: the body of a File is then copied from its reader, and
+//     the response is neither flushed while the handler runs nor hijacked;
+//   - the trailers of the request and of the response are dropped;
+//   - the WebSocket routes are not supported;
+//   - the handlers still get the fiber and fasthttp types, e.g. Context.RequestContext, and the context of the
+//     net/http request only through Context.Context.
+//
+// The app is set up on the first request, without saving the specs to files.
+func (s *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := s.http.init(s.setupInMemory, s.app); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to setup", slog.Any("error", err))
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	var ctx fasthttp.RequestCtx
+
+	ctx.Init(&fasthttp.Request{}, remoteAddr(r), nil)
+	ctx.SetUserValue(requestContextKey{}, r.Context())
+
+	if err := copyRequest(&ctx.Request, r, s.app.Server().MaxRequestBodySize); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+
+		return
+	}
+
+	s.http.handler(&ctx)
+
+	ctx.Response.Header.VisitAll(func(key, value []byte) {
+		switch string(key) {
+		case fiber.HeaderConnection, fiber.HeaderTransferEncoding:
+		default:
+			w.Header().Add(string(key), string(value))
+		}
+	})
+
+	w.WriteHeader(ctx.Response.StatusCode())
+
+	if err := ctx.Response.BodyWriteTo(w); err != nil {
+		s.logger.ErrorContext(r.Context(), "failed to write the response", slog.Any("error", err))
+	}
+}
+
+// copyRequest copies the net/http request to the fasthttp request. The body is read up to one byte over the limit,
+// so that the route rejects it with a 413 HTTPError.
+func copyRequest(req *fasthttp.Request, r *http.Request, bodyLimit int) error {
+	req.Header.SetMethod(r.Method)
+	req.SetRequestURI(r.URL.RequestURI())
+	req.SetHost(r.Host)
+
+	for key, values := range r.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, int64(bodyLimit)+1))
+	if err != nil {
+		return err
+	}
+
+	req.SetBody(body)
+
+	return nil
+}
+
+// setupInMemory sets the app up for the net/http adapter, without saving the specs to files.
+func (s *App) setupInMemory() error {
+	return s.setupApp(false)
+}
+
+// remoteAddr returns the address of the client of the net/http request.
+func remoteAddr(r *http.Request) net.Addr {
+	addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	if err != nil {
+		return &net.TCPAddr{IP: net.ParseIP(r.RemoteAddr)}
+	}
+
+	return addr
+}
+
+// httpRequestContext returns the context of the net/http request served by ServeHTTP, canceled when the client
+// disconnects, or false if the request is served by fiber.
+func httpRequestContext(c *fiber.Ctx) (context.Context, bool) {
+	ctx, ok := c.Context().UserValue(requestContextKey{}).(context.Context)
+
+	return ctx, ok
+}
+
+// RegisterHandlers registers the routes of the app on the ServeMux, with the Go 1.22 patterns, e.g.
+// "GET /users/{id}" for the route GET /users/:id. The app must be fully registered.
+// The routes whose pattern conflicts with another one are skipped: register the app on "/" to serve them.
+func (s *App) RegisterHandlers(mux *http.ServeMux) error {
+	if err := s.http.init(s.setupInMemory, s.app); err != nil {
+		return err
+	}
+
+	registered := make(map[string]bool)
+
+	for _, route := range s.app.GetRoutes(true) {
+		pattern := muxPattern(route.Method, route.Path)
+		if registered[pattern] {
+			continue
+		}
+
+		registered[pattern] = true
+
+		if err := handleMux(mux, pattern, s); err != nil {
+			s.logger.Warn("route not registered on the ServeMux", slog.String("pattern", pattern), slog.Any("error", err))
+		}
+	}
+
+	return nil
+}
+
+// handleMux registers the handler on the ServeMux, returning the conflicts between patterns as errors.
+func handleMux(mux *http.ServeMux, pattern string, handler http.Handler) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%v", recovered)
+		}
+	}()
+
+	mux.Handle(pattern, handler)
+
+	return nil
+}
+
+// muxPattern returns the ServeMux pattern of a fiber route. The segments which are not a single parameter
+// (optional parameters, wildcards...) match the rest of the path.
+func muxPattern(method, path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+
+	for i, segment := range segments {
+		name, isParameter := strings.CutPrefix(segment, ":")
+
+		switch {
+		case isParameter && isIdentifier(name):
+			segments[i] = "{" + name + "}"
+		case strings.ContainsAny(segment, ":*+?"):
+			segments[i] = fmt.Sprintf("{path%d...}", i)
+			segments = segments[:i+1]
+
+			return method + " /" + strings.Join(segments, "/")
+		}
+	}
+
+	pattern := "/" + strings.Join(segments, "/")
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "{$}"
+	}
+
+	return method + " " + pattern
+}
+
+// isIdentifier returns whether the name is a Go identifier, as required for the ServeMux wildcards.
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	for i, r := range name {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// httpHandler returns the handler of the app served with BackendNetHTTP: a ServeMux with the routes of the app,
+// the other requests being served by the app to render the 404 and 405 errors, wrapped by the middlewares.
+func (s *App) httpHandler() (http.Handler, error) {
+	mux := http.NewServeMux()
+
+	if err := s.RegisterHandlers(mux); err != nil {
+		return nil, err
+	}
+
+	if err := handleMux(mux, "/", s); err != nil {
+		return nil, err
+	}
+
+	var handler http.Handler = mux
+	for i := len(s.http.middleware) - 1; i >= 0; i-- {
+		handler = s.http.middleware[i](handler)
+	}
+
+	return handler, nil
+}
+
+// serve serves the app on the listener with its backend, until it is shut down.
+func (s *App) serve(ln net.Listener) error {
+	if s.backend != BackendNetHTTP {
+		return s.app.Listener(ln)
+	}
+
+	// the app has been set up by Run
+	if err := s.http.init(nil, s.app); err != nil {
+		return err
+	}
+
+	handler, err := s.httpHandler()
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Handler:        handler,
+		ReadTimeout:    s.serverConfig.ReadTimeout,
+		WriteTimeout:   s.serverConfig.WriteTimeout,
+		IdleTimeout:    s.serverConfig.IdleTimeout,
+		MaxHeaderBytes: s.serverConfig.ReadBufferSize,
+	}
+	s.http.server.Store(server)
+
+	s.logger.Info("listening", slog.String("address", ln.Addr().String()), slog.String("backend", "net/http"))
+
+	if err = server.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// shutdownHTTP shuts down the net/http server of the app, if it is served with BackendNetHTTP.
+func (s *App) shutdownHTTP(ctx context.Context) error {
+	server := s.http.server.Load()
+	if server == nil {
+		return nil
+	}
+
+	return server.Shutdown(ctx)
+}
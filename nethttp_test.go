@@ -0,0 +1,222 @@
+package lite
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantKey struct{}
+
+type getOrderRequest struct {
+	ID     uint64 `lite:"params=id"`
+	Expand string `lite:"query=expand"`
+}
+
+type orderResponse struct {
+	ID     uint64 `json:"id"`
+	Expand string `json:"expand"`
+	Tenant string `json:"tenant"`
+}
+
+func newHTTPApp(config ...Config) *App {
+	app := newListenerApp(config...)
+
+	Get(app, "/orders/:id", func(c *ContextWithRequest[getOrderRequest]) (orderResponse, error) {
+		req, err := c.Requests()
+		if err != nil {
+			return orderResponse{}, err
+		}
+
+		tenant, _ := c.Context().Value(tenantKey{}).(string)
+
+		return orderResponse{ID: req.ID, Expand: req.Expand, Tenant: tenant}, nil
+	})
+
+	Post(app, "/notes", echoBody)
+
+	return app
+}
+
+func withTenant(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Tenant", "acme")
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), tenantKey{}, "acme")))
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	app := newHTTPApp(SetBodyLimit(16))
+	handler := withTenant(app)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/orders/42?expand=items", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "acme", recorder.Header().Get("X-Tenant"))
+	assert.JSONEq(t, `{"id":42,"expand":"items","tenant":"acme"}`, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader("hello"))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Equal(t, "hello", recorder.Body.String())
+
+	for target, status := range map[string]int{
+		"/orders/abc": http.StatusBadRequest,
+		"/missing":    http.StatusNotFound,
+	} {
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, status, recorder.Code, target)
+
+		var document map[string]any
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document), target)
+		assert.EqualValues(t, status, document["status"], target)
+	}
+
+	recorder = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/notes", strings.NewReader(strings.Repeat("a", 32)))
+	req.Header.Set("Content-Type", "text/plain")
+	handler.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
+
+func TestServeHTTP_Docs(t *testing.T) {
+	app := New(SetOpenAPIPath("/docs/nethttp.yaml"))
+
+	Get(app, "/ping", func(c *ContextNoRequest) (string, error) {
+		return "pong", nil
+	})
+
+	rec := httptest.NewRecorder()
+	app.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/docs/nethttp.yaml", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/ping:")
+
+	// the spec is served from memory, the adapter writes no files
+	_, err := os.Stat("docs/nethttp.yaml")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMuxPattern(t *testing.T) {
+	tests := map[string]string{
+		"/":                   "GET /{$}",
+		"/orders":             "GET /orders",
+		"/orders/":            "GET /orders/{$}",
+		"/orders/:id":         "GET /orders/{id}",
+		"/orders/:id/items":   "GET /orders/{id}/items",
+		"/orders/:id?":        "GET /orders/{path1...}",
+		"/files/*":            "GET /files/{path1...}",
+		"/files/:name.:ext/x": "GET /files/{path1...}",
+		"/users/:user-id":     "GET /users/{path1...}",
+	}
+
+	for path, pattern := range tests {
+		assert.Equal(t, pattern, muxPattern(http.MethodGet, path), path)
+	}
+}
+
+func TestRegisterHandlers(t *testing.T) {
+	app := newHTTPApp()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "metrics")
+	})
+	require.NoError(t, app.RegisterHandlers(mux))
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	for target, body := range map[string]string{
+		"/metrics":   "metrics",
+		"/orders/42": `{"id":42,"expand":"","tenant":""}`,
+		"/ping":      "pong",
+	} {
+		resp, err := server.Client().Get(server.URL + target)
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, target)
+		assert.Equal(t, body, strings.TrimSpace(string(data)), target)
+	}
+
+	// the routes which are not registered are not served by the app
+	resp, err := server.Client().Get(server.URL + "/missing")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestBackendNetHTTP(t *testing.T) {
+	app := newHTTPApp(SetBackend(BackendNetHTTP), SetHTTPMiddleware(withTenant))
+	address, ran := runApp(t, app)
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Get("http://" + address + "/orders/7")
+	require.NoError(t, err)
+
+	var order orderResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&order))
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, orderResponse{ID: 7, Tenant: "acme"}, order)
+
+	// the requests which match no route are rendered by the app
+	resp, err = client.Post("http://"+address+"/orders/7", "text/plain", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+}
+
+func TestBackendNetHTTP_HTTP2(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue(t, "server", 2, x509.ExtKeyUsageServerAuth)
+
+	app := newListenerApp(SetBackend(BackendNetHTTP), SetTLS(certFile, keyFile))
+	address, ran := runApp(t, app)
+
+	transport := &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: ca.pool, MinVersion: tls.VersionTLS12},
+		ForceAttemptHTTP2: true,
+	}
+	t.Cleanup(transport.CloseIdleConnections)
+
+	resp, err := (&http.Client{Transport: transport}).Get("https://" + address + "/ping")
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	transport.CloseIdleConnections()
+
+	require.NoError(t, app.Shutdown())
+	require.NoError(t, <-ran)
+}
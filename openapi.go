@@ -3,6 +3,7 @@ package lite
 import (
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"mime/multipart"
 	"reflect"
@@ -62,13 +63,17 @@ func registerOpenAPIOperation[ResponseBody, RequestBody any](
 	tag := tagFromType(*new(ResponseBody))
 	fieldType := reflect.TypeOf(*new(ResponseBody))
 
-	err = setResponseSchema(s, operation, tag, resContentType, statusCode, fieldType)
-	if err != nil {
-		return nil, err
+	if fieldType == fileType {
+		setFileResponseSchema(s, operation, resContentType, statusCode)
+	} else {
+		err = setResponseSchema(s, operation, tag, resContentType, statusCode, fieldType)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	// Add error responses
-	responses, _ := s.createDefaultErrorResponses()
+	responses, _ := s.createDefaultErrorResponses(resContentType)
 
 	for code, resp := range responses {
 		operation.AddResponse(code, resp)
@@ -78,6 +83,7 @@ func registerOpenAPIOperation[ResponseBody, RequestBody any](
 	operation.Responses.Delete("default")
 
 	s.openAPISpec.AddOperation(routePath, method, operation)
+	s.specChanged()
 
 	return operation, nil
 }
@@ -163,7 +169,12 @@ func setEnums(fieldType reflect.Type, tag reflect.StructTag, schema *openapi3.Sc
 }
 
 func updateKey(properties openapi3.Schemas, key string, newKey string) {
-	schema := properties[key]
+	schema, ok := properties[key]
+	if !ok {
+		// already renamed, the generator caches the schemas by type
+		return
+	}
+
 	properties[newKey] = schema
 
 	if key != newKey {
@@ -322,6 +333,12 @@ func register(s *App, operation *openapi3.Operation, dstVal reflect.Value) error
 				}
 			}
 
+			// Streamed multipart bodies are documented with the fields of their form type
+			if binder, ok := reflect.New(fieldType).Interface().(multipartStreamBinder); ok {
+				fieldType = binder.streamType()
+				kind = fieldType.Kind()
+			}
+
 			fieldName := field.Name
 
 			if kind == reflect.Struct {
@@ -333,6 +350,8 @@ func register(s *App, operation *openapi3.Operation, dstVal reflect.Value) error
 				return err
 			}
 
+			setFileConstraintsSchema(s, operation, fieldType, contentType)
+
 			continue
 		} else {
 			return InternalServerError{
@@ -408,7 +427,7 @@ func setResponseSchema(
 			}
 		}
 
-		newSchemaContent := fmt.Sprintf("%v", newSchema.Value)
+		newSchemaContent := schemaContent(newSchema.Value)
 
 		if !reflect.DeepEqual(newSchema.Value, responseSchema.Value) {
 			hash := computeHash(newSchemaContent)
@@ -443,18 +462,16 @@ func setBodySchema(
 	fieldName string,
 	contentType string,
 ) error {
-	existingSchema, exists := s.openAPISpec.Components.Schemas[fieldName]
-	if !exists {
-		var err error
-
-		if fieldType == reflect.TypeOf(multipart.FileHeader{}) || fieldType == reflect.TypeOf(&multipart.FileHeader{}) {
-			fieldType = reflect.TypeOf([]byte{})
-		}
+	if fieldType == reflect.TypeOf(multipart.FileHeader{}) || fieldType == reflect.TypeOf(&multipart.FileHeader{}) {
+		fieldType = reflect.TypeOf([]byte{})
+	}
 
-		if kind == reflect.Struct {
-			fieldType = updateFileHeaderFieldType(fieldType)
-		}
+	if kind == reflect.Struct {
+		fieldType = updateFileHeaderFieldType(fieldType)
+	}
 
+	existingSchema, exists := s.openAPISpec.Components.Schemas[fieldName]
+	if !exists {
 		tp := reflect.New(fieldType).Elem().Interface()
 
 		bodySchema, err := generatorNewSchemaRefForValue(tp, s.openAPISpec.Components.Schemas)
@@ -495,7 +512,7 @@ func setBodySchema(
 			}
 		}
 
-		newSchemaContent := fmt.Sprintf("%v", newBodySchema.Value)
+		newSchemaContent := schemaContent(newBodySchema.Value)
 
 		if !reflect.DeepEqual(newBodySchema.Value, existingSchema.Value) {
 			hash := computeHash(newSchemaContent)
@@ -598,7 +615,7 @@ func setHeaderScheme(
 
 		headerSchema.Value.Nullable = !isRequired
 
-		newSchemaContent := fmt.Sprintf("%v", headerSchema.Value)
+		newSchemaContent := schemaContent(headerSchema.Value)
 
 		if !reflect.DeepEqual(headerSchema.Value, existingSchema.Value) {
 			hash := computeHash(newSchemaContent)
@@ -667,6 +684,18 @@ func setSecurityScheme(
 	s.openAPISpec.Components.SecuritySchemes[headerKey] = securitySchemes[headerKey]
 }
 
+// schemaContent returns the JSON encoding of the schema, which is stable across runs unlike its %v format
+// printing the pointers, so that the hashed schema names are the same in every build of the spec.
+// The names differ from the ones hashed from the %v format, a breaking change documented in the README.
+func schemaContent(schema *openapi3.Schema) string {
+	content, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Sprintf("%v", schema)
+	}
+
+	return string(content)
+}
+
 // computeHash generates a SHA256 hash for the given input and returns the first 4 characters.
 func computeHash(input string) string {
 	hash := sha256.Sum256([]byte(input))
@@ -728,7 +757,7 @@ func setParamSchema(
 
 		paramSchema.Value.Nullable = !isRequired
 
-		newSchemaContent := fmt.Sprintf("%v", paramSchema.Value)
+		newSchemaContent := schemaContent(paramSchema.Value)
 
 		if !reflect.DeepEqual(paramSchema.Value, existingSchema.Value) {
 			hash := computeHash(newSchemaContent)
@@ -1078,3 +1078,13 @@ func TestExtractBaseName(t *testing.T) {
 		})
 	}
 }
+
+func TestSchemaContent(t *testing.T) {
+	newSchema := func() *openapi3.Schema {
+		return openapi3.NewObjectSchema().WithProperty("name", openapi3.NewStringSchema())
+	}
+
+	// the content, and so the hashed schema names, do not depend on the pointers
+	assert.Equal(t, schemaContent(newSchema()), schemaContent(newSchema()))
+	assert.NotEqual(t, schemaContent(newSchema()), schemaContent(openapi3.NewStringSchema()))
+}
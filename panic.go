@@ -0,0 +1,100 @@
+package lite
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PanicReport describes a panic recovered while handling a request.
+type PanicReport struct {
+	Value     any    // Value passed to panic
+	Stack     []byte // Stack trace of the goroutine which panicked
+	Method    string // Method of the request
+	Route     string // Path of the matched route, e.g. /users/:id, empty when the request matches no route of the app
+	RequestID string // ID of the request, from the X-Request-ID header
+}
+
+// SetPanicHook sets a hook called with the panics recovered while handling the requests, e.g. to report them
+// to an error tracker. The panics are logged and answered with a 500 HTTPError whether a hook is set or not.
+func SetPanicHook(hook func(ctx context.Context, report PanicReport)) Config {
+	return func(s *App) {
+		s.panicHook = hook
+	}
+}
+
+// recoverMiddleware recovers from the panics of the next handlers, the lite handlers as well as the middlewares,
+// and writes a 500 HTTPError instead.
+func (s *App) recoverMiddleware(c *fiber.Ctx) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered == nil {
+			return
+		}
+
+		err = s.handlePanic(c, recovered, debug.Stack())
+	}()
+
+	return c.Next()
+}
+
+func (s *App) handlePanic(c *fiber.Ctx, recovered any, stack []byte) error {
+	route, _ := matchedRoute(c)
+
+	report := PanicReport{
+		Value:     recovered,
+		Stack:     stack,
+		Method:    c.Method(),
+		Route:     route,
+		RequestID: requestID(c),
+	}
+
+	s.logger.ErrorContext(c.Context(), "panic recovered",
+		slog.Any("panic", recovered),
+		slog.String("method", report.Method),
+		slog.String("route", report.Route),
+		slog.String("requestId", report.RequestID),
+		slog.String("stack", string(stack)),
+	)
+
+	if s.panicHook != nil {
+		s.panicHook(c.UserContext(), report)
+	}
+
+	s.setDebugError(c, fmt.Errorf("panic: %v", recovered))
+
+	httpError := NewInternalServerError()
+	if s.devMode {
+		httpError.Description = fmt.Sprintf("panic: %v", recovered)
+		httpError.trace = stackTrace(stack)
+	}
+
+	// discard the part of the response which may have been written before the panic
+	c.Response().ResetBody()
+
+	return s.renderError(c, httpError)
+}
+
+// requestID returns the ID of the request, set in the response by the requestid middleware or sent by the client.
+func requestID(c *fiber.Ctx) string {
+	if id := c.GetRespHeader(fiber.HeaderXRequestID); id != "" {
+		return id
+	}
+
+	return c.Get(fiber.HeaderXRequestID)
+}
+
+// stackTrace splits a stack trace into its lines.
+func stackTrace(stack []byte) []string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+
+	return lines
+}
@@ -0,0 +1,140 @@
+package lite
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPanicApp(config ...Config) *App {
+	app := New(config...)
+
+	Get(app, "/users/:id", func(c *ContextNoRequest) (string, error) {
+		var users map[string]string
+		users["1"] = "john"
+
+		return "", nil
+	})
+
+	return app
+}
+
+func getPanic(t *testing.T, app *App, target string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set(fiber.HeaderXRequestID, "7f5c2e")
+
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(body, &document), string(body))
+
+	return resp, document
+}
+
+func TestRecover(t *testing.T) {
+	var logs bytes.Buffer
+
+	var reports []PanicReport
+
+	app := newPanicApp(
+		SetLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+		SetPanicHook(func(ctx context.Context, report PanicReport) {
+			reports = append(reports, report)
+		}),
+	)
+
+	resp, document := getPanic(t, app, "/users/1")
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, string(ContentTypeJSON), resp.Header.Get(HeaderContentType))
+	assert.Equal(t, "/api/contexts/InternalServerError", document["@context"])
+	assert.Equal(t, "Internal server error", document["description"])
+	assert.NotContains(t, document, "trace")
+
+	require.Len(t, reports, 1)
+	assert.Equal(t, http.MethodGet, reports[0].Method)
+	assert.Equal(t, "/users/:id", reports[0].Route)
+	assert.Equal(t, "7f5c2e", reports[0].RequestID)
+	assert.Contains(t, string(reports[0].Stack), "panic_test.go")
+
+	_, ok := reports[0].Value.(error)
+	assert.True(t, ok)
+
+	assert.Contains(t, logs.String(), `"msg":"panic recovered"`)
+	assert.Contains(t, logs.String(), `"route":"/users/:id"`)
+	assert.Contains(t, logs.String(), `"requestId":"7f5c2e"`)
+	assert.Contains(t, logs.String(), `"stack":"goroutine`)
+}
+
+func TestRecover_Middleware(t *testing.T) {
+	var reports []PanicReport
+
+	app := New(SetPanicHook(func(ctx context.Context, report PanicReport) {
+		reports = append(reports, report)
+	}))
+
+	Use(app, func(c *fiber.Ctx) error {
+		if c.Path() == "/users" {
+			panic("middleware")
+		}
+
+		return c.Next()
+	})
+
+	Get(app, "/users", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+
+	Get(app, "/orders/:id", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	}, func(c *fiber.Ctx) error {
+		panic("route middleware")
+	})
+
+	resp, document := getPanic(t, app, "/users")
+
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Equal(t, "InternalServerError", document["@type"])
+
+	resp, _ = getPanic(t, app, "/orders/1")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	// the middlewares of the app run before the route is matched, unlike the middlewares of the route
+	require.Len(t, reports, 2)
+	assert.Empty(t, reports[0].Route)
+	assert.Equal(t, "/orders/:id", reports[1].Route)
+}
+
+func TestRecover_DevMode(t *testing.T) {
+	app := newPanicApp(SetEnvironment(EnvironmentDevelopment), SetDevMode(true))
+
+	_, document := getPanic(t, app, "/users/1")
+
+	assert.Equal(t, "panic: assignment to entry in nil map", document["description"])
+
+	trace, ok := document["trace"].([]any)
+	require.True(t, ok)
+	assert.Equal(t, "goroutine", trace[0].(string)[:9])
+
+	app = newPanicApp(SetEnvironment(EnvironmentDevelopment), SetDevMode(true), SetErrorFormat(ErrorFormatProblem))
+
+	_, document = getPanic(t, app, "/users/1")
+
+	assert.Equal(t, "/users/1", document["instance"])
+	assert.NotEmpty(t, document["trace"])
+}
@@ -0,0 +1,171 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/valyala/fasthttp/reuseport"
+)
+
+// preforkChildEnv is the environment variable set in the processes started by the prefork master.
+const preforkChildEnv = "LITE_PREFORK_CHILD"
+
+// preforkMasterCheckInterval is the interval at which the prefork children check that their master is running.
+const preforkMasterCheckInterval = 500 * time.Millisecond
+
+// preforkCommand returns the command starting a prefork child, which runs the same program with the same arguments.
+var preforkCommand = func() *exec.Cmd {
+	cmd := exec.Command(os.Args[0], os.Args[1:]...) //nolint:gosec // the program starts itself
+
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd
+}
+
+// SetPrefork sets whether Run starts one child process per CPU (GOMAXPROCS), all serving the app address
+// with SO_REUSEPORT. The master process forwards SIGTERM and SIGINT to the children and returns their errors.
+// The start and shutdown hooks are called in each child. Prefork is not supported on unix sockets. Default is false.
+func SetPrefork(prefork bool) Config {
+	return func(s *App) {
+		s.prefork = prefork
+	}
+}
+
+// preforkChild returns whether the app runs in a child process started by the prefork master.
+func (s *App) preforkChild() bool {
+	return s.prefork && os.Getenv(preforkChildEnv) != ""
+}
+
+// runPrefork starts the prefork children and waits for them to exit. The children are shut down
+// when the master receives SIGTERM or SIGINT, or when one of them exits.
+//
+// The Prefork of fiber is not used, as it does not shut down gracefully: its master kills the children when it exits,
+// and its children call os.Exit once the master is gone, so the requests are not drained nor the shutdown hooks
+// called. It is also only run by fiber.App.Listen and ListenTLS with a static TLS config, so it cannot serve the
+// listeners of the app, e.g. with the reloaded certificates of SetTLS or the net/http backend.
+func (s *App) runPrefork() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	children := runtime.GOMAXPROCS(0)
+	processes := make([]*os.Process, 0, children)
+	exited := make(chan error, children)
+
+	var err error
+
+	for range children {
+		cmd := preforkCommand()
+		cmd.Env = append(os.Environ(), preforkChildEnv+"=1")
+		setProcessGroup(cmd)
+
+		if err = cmd.Start(); err != nil {
+			err = fmt.Errorf("failed to start prefork child: %w", err)
+
+			break
+		}
+
+		processes = append(processes, cmd.Process)
+
+		go func() {
+			if waitErr := cmd.Wait(); waitErr != nil {
+				exited <- fmt.Errorf("prefork child %d: %w", cmd.Process.Pid, waitErr)
+
+				return
+			}
+
+			exited <- nil
+		}()
+	}
+
+	running := len(processes)
+
+	if err == nil {
+		s.logger.InfoContext(ctx, "prefork started", slog.Int("children", len(processes)))
+
+		select {
+		case <-ctx.Done():
+			s.logger.InfoContext(ctx, "shutting down prefork children")
+		case err = <-exited:
+			running--
+		}
+	}
+
+	for _, process := range processes {
+		// the processes are killed on the platforms which cannot send signals, the exited processes ignore both
+		if signalErr := process.Signal(syscall.SIGTERM); signalErr != nil {
+			_ = process.Kill()
+		}
+	}
+
+	errs := []error{err}
+	for range running {
+		// a child terminated by the signal before handling it, e.g. while it starts, has been shut down
+		if childErr := <-exited; !terminatedBySignal(childErr) {
+			errs = append(errs, childErr)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// terminatedBySignal returns whether the error is the exit of a child process terminated by a signal.
+func terminatedBySignal(err error) bool {
+	var exitErr *exec.ExitError
+
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == -1
+}
+
+// watchPreforkMaster cancels the returned context when the prefork master exits, so that the child shuts down.
+func watchPreforkMaster(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	master := os.Getppid()
+
+	go func() {
+		ticker := time.NewTicker(preforkMasterCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if os.Getppid() != master {
+					cancel()
+
+					return
+				}
+			}
+		}
+	}()
+
+	return ctx, cancel
+}
+
+// listenReusePort returns a listener with SO_REUSEPORT, shared by the prefork children.
+func listenReusePort(network, addr string) (net.Listener, error) {
+	if network == "unix" {
+		return nil, errors.New("prefork is not supported on unix sockets")
+	}
+
+	network = "tcp4"
+	if host, _, _ := net.SplitHostPort(addr); net.ParseIP(host) != nil && net.ParseIP(host).To4() == nil {
+		network = "tcp6"
+	}
+
+	ln, err := reuseport.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	return ln, nil
+}
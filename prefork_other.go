@@ -0,0 +1,8 @@
+//go:build !unix
+
+package lite
+
+import "os/exec"
+
+// setProcessGroup does nothing on this platform: the prefork children are in the process group of the master.
+func setProcessGroup(*exec.Cmd) {}
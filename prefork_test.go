@@ -0,0 +1,133 @@
+package lite
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// preforkTestAddressEnv is the address served by the prefork children started by TestPrefork_Master.
+const preforkTestAddressEnv = "LITE_PREFORK_TEST_ADDRESS"
+
+func TestPrefork_Listen(t *testing.T) {
+	t.Setenv(preforkChildEnv, "1")
+
+	app := newListenerApp(SetPrefork(true))
+
+	port, err := getFreePort()
+	require.NoError(t, err)
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+
+	// the children share the address
+	first, err := app.listenAddress(address)
+	require.NoError(t, err)
+
+	defer first.Close()
+
+	second, err := app.listenAddress(address)
+	require.NoError(t, err)
+
+	defer second.Close()
+
+	_, err = app.listenAddress("unix:" + t.TempDir() + "/lite.sock")
+	require.Error(t, err)
+
+	// without prefork, the address is not shared
+	_, err = newListenerApp().listenAddress(address)
+	require.Error(t, err)
+}
+
+// TestPrefork_Child is the prefork child started by TestPrefork_Master.
+func TestPrefork_Child(t *testing.T) {
+	address := os.Getenv(preforkTestAddressEnv)
+	if address == "" || os.Getenv(preforkChildEnv) == "" {
+		t.Skip("started by TestPrefork_Master")
+	}
+
+	assert.NoError(t, newListenerApp(SetPrefork(true)).Listen(address))
+}
+
+func TestPrefork_Master(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the signals can not be sent on windows")
+	}
+
+	port, err := getFreePort()
+	require.NoError(t, err)
+
+	address := fmt.Sprintf("127.0.0.1:%d", port)
+	t.Setenv(preforkTestAddressEnv, address)
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	command := preforkCommand
+	preforkCommand = func() *exec.Cmd {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestPrefork_Child$") //nolint:gosec // the test binary
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		return cmd
+	}
+
+	defer func() { preforkCommand = command }()
+
+	app := newListenerApp(SetPrefork(true))
+	ran := make(chan error, 1)
+
+	go func() {
+		ran <- app.Listen(address)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	require.Eventually(t, func() bool {
+		resp, err := client.Get("http://" + address + "/ping")
+		if err != nil {
+			return false
+		}
+
+		_ = resp.Body.Close()
+
+		return resp.StatusCode == http.StatusOK
+	}, 10*time.Second, 20*time.Millisecond)
+
+	// the master shuts the children down gracefully
+	process, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, process.Signal(syscall.SIGTERM))
+
+	select {
+	case err = <-ran:
+		require.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the prefork master did not stop")
+	}
+}
+
+func TestTerminatedBySignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the signals can not be sent on windows")
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^$") //nolint:gosec // the test binary
+	require.NoError(t, cmd.Start())
+	require.NoError(t, cmd.Process.Signal(syscall.SIGKILL))
+
+	err := cmd.Wait()
+	assert.True(t, terminatedBySignal(fmt.Errorf("prefork child: %w", err)))
+
+	// an exit code is not a termination by a signal
+	err = exec.Command(os.Args[0], "-test.unknown").Run() //nolint:gosec // the test binary
+	assert.Error(t, err)
+	assert.False(t, terminatedBySignal(err))
+	assert.False(t, terminatedBySignal(nil))
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package lite
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the prefork child in its own process group, so that a SIGINT sent to the terminal
+// reaches the master only, which shuts the children down.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
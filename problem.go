@@ -0,0 +1,348 @@
+package lite
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/invopop/yaml"
+)
+
+// ErrorFormat is the format of the error response bodies.
+type ErrorFormat int
+
+const (
+	// ErrorFormatJSONLD renders the errors as HTTPError, with JSON-LD @context and @type keys.
+	ErrorFormatJSONLD ErrorFormat = iota
+	// ErrorFormatProblem renders the errors as RFC 9457 Problem Details, served as application/problem+json.
+	ErrorFormatProblem
+)
+
+// SetErrorFormat sets the format of the error response bodies and of their OpenAPI schema. Default is ErrorFormatJSONLD.
+func SetErrorFormat(format ErrorFormat) Config {
+	return func(s *App) {
+		s.errorFormat = format
+	}
+}
+
+// ProblemDetails is an RFC 9457 Problem Details object.
+// The Extensions members are serialized next to the standard members in JSON and YAML, and omitted in XML.
+type ProblemDetails struct {
+	XMLName    xml.Name       `json:"-" xml:"urn:ietf:rfc:7807 problem"`
+	Type       string         `json:"type,omitempty" xml:"type,omitempty"`
+	Title      string         `json:"title,omitempty" xml:"title,omitempty"`
+	Status     int            `json:"status,omitempty" xml:"status,omitempty"`
+	Detail     string         `json:"detail,omitempty" xml:"detail,omitempty"`
+	Instance   string         `json:"instance,omitempty" xml:"instance,omitempty"`
+	Extensions map[string]any `json:"-" xml:"-"`
+}
+
+type problemDetails ProblemDetails
+
+// MarshalJSON marshals the standard members and the extension members in a single object.
+func (p ProblemDetails) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(problemDetails(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	members := make(map[string]any, len(p.Extensions)+5)
+	for key, value := range p.Extensions {
+		members[key] = value
+	}
+
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON unmarshals the standard members, and the other members into Extensions.
+func (p *ProblemDetails) UnmarshalJSON(data []byte) error {
+	var standard problemDetails
+	if err := json.Unmarshal(data, &standard); err != nil {
+		return err
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+
+	*p = ProblemDetails(standard)
+
+	for key, raw := range members {
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return err
+		}
+
+		if p.Extensions == nil {
+			p.Extensions = make(map[string]any, len(members))
+		}
+
+		p.Extensions[key] = value
+	}
+
+	return nil
+}
+
+// ProblemDetails converts the error into Problem Details for the given instance.
+// The problem type is the JSON-LD context of the error, and the violations and the stack trace are extension members.
+func (e HTTPError) ProblemDetails(instance string) ProblemDetails {
+	problemType := e.Context
+	if problemType == "" {
+		problemType = "about:blank"
+	}
+
+	title := e.Title
+	if title == "" {
+		title = http.StatusText(e.StatusCode())
+	}
+
+	problem := ProblemDetails{
+		Type:     problemType,
+		Title:    title,
+		Status:   e.StatusCode(),
+		Detail:   e.Description,
+		Instance: instance,
+	}
+
+	if len(e.Violations) > 0 || len(e.trace) > 0 {
+		problem.Extensions = make(map[string]any, 2)
+	}
+
+	if len(e.Violations) > 0 {
+		problem.Extensions["violations"] = e.Violations
+	}
+
+	if len(e.trace) > 0 {
+		problem.Extensions["trace"] = e.trace
+	}
+
+	return problem
+}
+
+// renderError writes the error response in the error format of the app and in the negotiated content type.
+func (s *App) renderError(c *fiber.Ctx, httpError HTTPError) error {
+	httpError.Context = s.errorContextURL(httpError.Context)
+
+	c.Status(httpError.StatusCode())
+
+	var info *debugInfo
+	if s.devMode {
+		info = s.debugInfo(c, httpError)
+
+		if acceptsHTML(c) {
+			return s.renderDebugPage(c, httpError, info)
+		}
+	}
+
+	contentType := s.negotiateErrorContentType(c)
+
+	var body any = httpError
+
+	switch {
+	case contentType == ContentTypeProblemJSON || contentType == ContentTypeProblemXML || s.errorFormat == ErrorFormatProblem:
+		problem := httpError.ProblemDetails(c.Path())
+		if info != nil {
+			if problem.Extensions == nil {
+				problem.Extensions = make(map[string]any, 1)
+			}
+
+			problem.Extensions["debug"] = info
+		}
+
+		body = problem
+	case info != nil:
+		body = debugHTTPError{HTTPError: httpError, Trace: httpError.trace, Debug: info}
+	}
+
+	return writeErrorBody(c, contentType, body)
+}
+
+// renderTypedError writes the body of a TypedError as is, in the syntax of the negotiated content type:
+// JSON, XML or YAML, as the body is not Problem Details.
+func (s *App) renderTypedError(c *fiber.Ctx, typed typedError) error {
+	c.Status(typed.StatusCode())
+
+	syntax, _ := errorSyntax(string(s.negotiateErrorContentType(c)))
+
+	return writeErrorBody(c, syntax, typed.errorBody())
+}
+
+// writeErrorBody writes the body of an error response in a content type of the errors.
+func writeErrorBody(c *fiber.Ctx, contentType ContentType, body any) error {
+	switch contentType {
+	case ContentTypeXML, ContentTypeProblemXML:
+		data, err := xml.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		c.Set(HeaderContentType, string(contentType))
+
+		return c.Send(append([]byte(xml.Header), data...))
+	case ContentTypeYAML:
+		data, err := yaml.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		c.Set(HeaderContentType, string(contentType))
+
+		return c.Send(data)
+	default:
+		return c.JSON(body, string(contentType))
+	}
+}
+
+// errorSyntax returns the syntax of a content type in which the errors can be written, JSON, XML or YAML,
+// or false if the errors can not be written in this content type.
+func errorSyntax(contentType string) (ContentType, bool) {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+
+	switch strings.TrimSpace(strings.ToLower(mediaType)) {
+	case string(ContentTypeJSON), string(ContentTypeProblemJSON), string(ContentTypeJSONLD):
+		return ContentTypeJSON, true
+	case string(ContentTypeXML), "text/xml", string(ContentTypeProblemXML):
+		return ContentTypeXML, true
+	case string(ContentTypeYAML), "application/x-yaml", "text/yaml":
+		return ContentTypeYAML, true
+	default:
+		return "", false
+	}
+}
+
+// errorContentType returns the content type of the errors written in a syntax, in the error format of the app.
+func (s *App) errorContentType(syntax ContentType) ContentType {
+	if s.errorFormat != ErrorFormatProblem {
+		return syntax
+	}
+
+	switch syntax {
+	case ContentTypeXML:
+		return ContentTypeProblemXML
+	case ContentTypeYAML:
+		return ContentTypeYAML
+	default:
+		return ContentTypeProblemJSON
+	}
+}
+
+// negotiateErrorContentType returns the content type of an error response.
+// The content type of the response set by the route is preferred, JSON otherwise,
+// unless the Accept header of the request asks for another one of JSON, XML, YAML, problem+json and problem+xml.
+func (s *App) negotiateErrorContentType(c *fiber.Ctx) ContentType {
+	preferred := s.errorContentType(ContentTypeJSON)
+	if syntax, ok := errorSyntax(string(c.Response().Header.ContentType())); ok {
+		preferred = s.errorContentType(syntax)
+	}
+
+	offers := []string{
+		string(preferred),
+		string(ContentTypeJSON),
+		string(ContentTypeXML),
+		string(ContentTypeYAML),
+		string(ContentTypeProblemJSON),
+		string(ContentTypeProblemXML),
+	}
+
+	switch accepted := ContentType(c.Accepts(offers...)); accepted {
+	case "":
+		return preferred
+	case ContentTypeProblemJSON, ContentTypeProblemXML:
+		return accepted
+	default:
+		syntax, _ := errorSyntax(string(accepted))
+
+		return s.errorContentType(syntax)
+	}
+}
+
+// errorContentTypes returns the content types of the error responses documented in the OpenAPI spec
+// for a route responding in the given content type: JSON, and the content type of the route if the errors can be written in it.
+func (s *App) errorContentTypes(responseContentType string) []string {
+	contentTypes := []string{string(s.errorContentType(ContentTypeJSON))}
+
+	if syntax, ok := errorSyntax(responseContentType); ok && syntax != ContentTypeJSON {
+		contentTypes = append(contentTypes, string(s.errorContentType(syntax)))
+	}
+
+	return contentTypes
+}
+
+// typedErrorContentTypes returns the content types of the TypedError responses documented in the OpenAPI spec
+// for a route responding in the given content type: JSON, and the syntax of the content type of the route if the
+// errors can be written in it.
+func typedErrorContentTypes(responseContentType string) []ContentType {
+	contentTypes := []ContentType{ContentTypeJSON}
+
+	if syntax, ok := errorSyntax(responseContentType); ok && syntax != ContentTypeJSON {
+		contentTypes = append(contentTypes, syntax)
+	}
+
+	return contentTypes
+}
+
+// errorSchema returns the httpGenericError schema of the error response bodies, generated once.
+func (s *App) errorSchema() (*openapi3.SchemaRef, error) {
+	if schema, ok := s.openAPISpec.Components.Schemas["httpGenericError"]; ok {
+		return schema, nil
+	}
+
+	var value any = new(HTTPError)
+	if s.errorFormat == ErrorFormatProblem {
+		value = new(ProblemDetails)
+	}
+
+	schema, err := generatorNewSchemaRefForValue(value, s.openAPISpec.Components.Schemas)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.errorFormat == ErrorFormatProblem {
+		violations, err := generatorNewSchemaRefForValue(new([]Violation), s.openAPISpec.Components.Schemas)
+		if err != nil {
+			return nil, err
+		}
+
+		// the generator caches the schemas by type, so the generated schemas are copied before being changed
+		value := *schema.Value
+		value.Properties = make(openapi3.Schemas, len(schema.Value.Properties)+1)
+
+		for name, property := range schema.Value.Properties {
+			value.Properties[name] = property
+		}
+
+		value.Properties["violations"] = violations
+		value.Properties["type"] = openapi3.NewSchemaRef("", openapi3.NewStringSchema().
+			WithDefault("about:blank").
+			WithFormat("uri-reference"))
+
+		schema = openapi3.NewSchemaRef("", &value)
+	}
+
+	s.openAPISpec.Components.Schemas["httpGenericError"] = schema
+
+	return schema, nil
+}
+
+// errorResponse returns the documentation of an error response with the httpGenericError schema,
+// for a route responding in the given content type.
+func (s *App) errorResponse(statusCode int, responseContentType string) *openapi3.Response {
+	return openapi3.NewResponse().
+		WithDescription(StatusMessage(statusCode)).
+		WithContent(openapi3.NewContentWithSchemaRef(
+			openapi3.NewSchemaRef("#/components/schemas/httpGenericError", &openapi3.Schema{}),
+			s.errorContentTypes(responseContentType),
+		))
+}
@@ -0,0 +1,322 @@
+package lite
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/invopop/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type problemRequest struct {
+	Name string `lite:"query=name" validate:"required"`
+}
+
+func newProblemApp(format ErrorFormat) *App {
+	app := New(SetErrorFormat(format))
+	users := Group(app, "/users")
+
+	Get(users, "/", func(c *ContextWithRequest[problemRequest]) (string, error) {
+		_, err := c.Requests()
+		if err != nil {
+			return "", err
+		}
+
+		return "", NewNotFoundError("The user does not exist")
+	})
+
+	return app
+}
+
+func TestProblemDetails_JSON(t *testing.T) {
+	problem := ProblemDetails{
+		Type:     "/api/contexts/NotFound",
+		Title:    "Resource not found",
+		Status:   http.StatusNotFound,
+		Detail:   "The user does not exist",
+		Instance: "/users/1",
+		Extensions: map[string]any{
+			"balance": float64(30),
+		},
+	}
+
+	data, err := json.Marshal(problem)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "/api/contexts/NotFound",
+		"title": "Resource not found",
+		"status": 404,
+		"detail": "The user does not exist",
+		"instance": "/users/1",
+		"balance": 30
+	}`, string(data))
+
+	var decoded ProblemDetails
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, problem, decoded)
+
+	data, err = json.Marshal(ProblemDetails{Type: "about:blank", Status: http.StatusBadRequest})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type": "about:blank", "status": 400}`, string(data))
+}
+
+func TestHTTPError_ProblemDetails(t *testing.T) {
+	problem := NewNotFoundError("missing").ProblemDetails("/users/1")
+
+	assert.Equal(t, ProblemDetails{
+		Type:     "/api/contexts/NotFound",
+		Title:    "Resource not found",
+		Status:   http.StatusNotFound,
+		Detail:   "missing",
+		Instance: "/users/1",
+	}, problem)
+
+	problem = HTTPError{
+		Status:     http.StatusBadRequest,
+		Violations: []Violation{{PropertyPath: "name", Message: "name is required"}},
+	}.ProblemDetails("")
+
+	assert.Equal(t, "about:blank", problem.Type)
+	assert.Equal(t, "Bad Request", problem.Title)
+	assert.Equal(t, []Violation{{PropertyPath: "name", Message: "name is required"}}, problem.Extensions["violations"])
+
+	assert.Equal(t, http.StatusInternalServerError, HTTPError{}.ProblemDetails("").Status)
+}
+
+func TestErrorFormat_Problem(t *testing.T) {
+	app := newProblemApp(ErrorFormatProblem)
+
+	tests := []struct {
+		name       string
+		target     string
+		status     int
+		violations bool
+	}{
+		{name: "handler error", target: "/users?name=john", status: http.StatusNotFound},
+		{name: "validation error", target: "/users", status: http.StatusBadRequest, violations: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, tt.target, nil))
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, string(ContentTypeProblemJSON), resp.Header.Get(HeaderContentType))
+
+			var problem ProblemDetails
+			require.NoError(t, json.Unmarshal(body, &problem))
+
+			assert.Equal(t, tt.status, problem.Status)
+			assert.Equal(t, "/users", problem.Instance)
+			assert.NotEmpty(t, problem.Type)
+			assert.NotEmpty(t, problem.Detail)
+			assert.Equal(t, tt.violations, problem.Extensions["violations"] != nil)
+		})
+	}
+}
+
+func TestErrorFormat_JSONLD(t *testing.T) {
+	app := newProblemApp(ErrorFormatJSONLD)
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/users?name=john", nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, string(ContentTypeJSON), resp.Header.Get(HeaderContentType))
+	assert.JSONEq(t, `{
+		"@context": "/api/contexts/NotFound",
+		"@type": "NotFound",
+		"status": 404,
+		"title": "Resource not found",
+		"description": "The user does not exist"
+	}`, string(body))
+}
+
+func TestErrorFormat_OpenAPI(t *testing.T) {
+	app := newProblemApp(ErrorFormatProblem)
+
+	schema := app.openAPISpec.Components.Schemas["httpGenericError"]
+	require.NotNil(t, schema)
+
+	for _, property := range []string{"type", "title", "status", "detail", "instance", "violations"} {
+		assert.Contains(t, schema.Value.Properties, property)
+	}
+
+	assert.NotContains(t, schema.Value.Properties, "@context")
+
+	operation := app.openAPISpec.Paths.Find("/users/").Get
+	require.NotNil(t, operation)
+
+	response := operation.Responses.Value("400")
+	require.NotNil(t, response)
+	assert.NotNil(t, response.Value.Content.Get(string(ContentTypeProblemJSON)))
+	assert.Nil(t, response.Value.Content.Get("application/json"))
+}
+
+func mediaTypes(content openapi3.Content) []string {
+	var contentTypes []string
+	for contentType := range content {
+		contentTypes = append(contentTypes, contentType)
+	}
+
+	sort.Strings(contentTypes)
+
+	return contentTypes
+}
+
+func newNegotiationApp(format ErrorFormat) *App {
+	app := New(SetErrorFormat(format))
+
+	Get(app, "/users", func(c *ContextNoRequest) (string, error) {
+		return "", NewNotFoundError("The user does not exist")
+	})
+
+	Get(app, "/feeds", func(c *ContextNoRequest) (string, error) {
+		c.Response().Header.SetContentType(string(ContentTypeXML))
+
+		return "", NewErrorResponse(http.StatusBadRequest, "/api/contexts/ConstraintViolationList",
+			"A constraint violation occurred", "ConstraintViolation", "name is required",
+			[]Violation{{PropertyPath: "name", Message: "name is required"}})
+	}).SetResponseContentType(ContentTypeXML)
+
+	return app
+}
+
+func TestRenderError_Negotiation(t *testing.T) {
+	app := newNegotiationApp(ErrorFormatJSONLD)
+
+	tests := []struct {
+		name        string
+		target      string
+		accept      string
+		contentType ContentType
+	}{
+		{name: "json route", target: "/users", contentType: ContentTypeJSON},
+		{name: "xml route", target: "/feeds", contentType: ContentTypeXML},
+		{name: "xml route accepting anything", target: "/feeds", accept: "*/*", contentType: ContentTypeXML},
+		{name: "xml accepted", target: "/users", accept: "application/xml", contentType: ContentTypeXML},
+		{name: "yaml accepted", target: "/users", accept: "text/html, application/yaml;q=0.9", contentType: ContentTypeYAML},
+		{name: "json accepted", target: "/feeds", accept: "application/json", contentType: ContentTypeJSON},
+		{name: "problem accepted", target: "/users", accept: "application/problem+json", contentType: ContentTypeProblemJSON},
+		{name: "unsupported accepted", target: "/users", accept: "text/html", contentType: ContentTypeJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set(HeaderAccept, tt.accept)
+			}
+
+			resp, err := app.app.Test(req)
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(tt.contentType), resp.Header.Get(HeaderContentType))
+
+			var httpError HTTPError
+
+			switch tt.contentType {
+			case ContentTypeXML:
+				require.NoError(t, xml.Unmarshal(body, &httpError), string(body))
+			case ContentTypeYAML:
+				require.NoError(t, yaml.Unmarshal(body, &httpError), string(body))
+			case ContentTypeProblemJSON:
+				var problem ProblemDetails
+				require.NoError(t, json.Unmarshal(body, &problem), string(body))
+
+				httpError.Status = problem.Status
+				httpError.Context = problem.Type
+			default:
+				require.NoError(t, json.Unmarshal(body, &httpError), string(body))
+			}
+
+			assert.Equal(t, resp.StatusCode, httpError.Status)
+			assert.NotEmpty(t, httpError.Context)
+		})
+	}
+}
+
+func TestRenderError_XML(t *testing.T) {
+	app := newNegotiationApp(ErrorFormatJSONLD)
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/feeds", nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	assert.Equal(t, xml.Header+`<error context="/api/contexts/ConstraintViolationList" type="ConstraintViolation">`+
+		`<status>400</status><title>A constraint violation occurred</title><description>name is required</description>`+
+		`<violations><violation><propertyPath>name</propertyPath><message>name is required</message></violation></violations>`+
+		`</error>`, string(body))
+}
+
+func TestRenderError_ProblemXML(t *testing.T) {
+	app := newNegotiationApp(ErrorFormatProblem)
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set(HeaderAccept, "application/xml")
+
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, string(ContentTypeProblemXML), resp.Header.Get(HeaderContentType))
+
+	var problem ProblemDetails
+	require.NoError(t, xml.Unmarshal(body, &problem), string(body))
+
+	assert.Equal(t, "urn:ietf:rfc:7807", problem.XMLName.Space)
+	assert.Equal(t, http.StatusNotFound, problem.Status)
+	assert.Equal(t, "/users", problem.Instance)
+
+	resp, err = app.app.Test(httptest.NewRequest(http.MethodGet, "/feeds", nil))
+	require.NoError(t, err)
+	assert.Equal(t, string(ContentTypeProblemXML), resp.Header.Get(HeaderContentType))
+}
+
+func TestErrorContentTypes_OpenAPI(t *testing.T) {
+	app := newNegotiationApp(ErrorFormatJSONLD)
+
+	users := app.openAPISpec.Paths.Find("/users").Get
+	assert.Equal(t, []string{"application/json"}, mediaTypes(users.Responses.Value("400").Value.Content))
+
+	feeds := app.openAPISpec.Paths.Find("/feeds").Get
+	assert.Equal(t, []string{"application/json", "application/xml"}, mediaTypes(feeds.Responses.Value("400").Value.Content))
+	assert.Equal(t, []string{"application/json", "application/xml"}, mediaTypes(feeds.Responses.Value("500").Value.Content))
+
+	route := Get(app, "/reports", func(c *ContextNoRequest) ([]byte, error) {
+		return nil, nil
+	}).SetResponseContentType(ContentTypePDF).AddErrorResponse(http.StatusNotFound)
+	assert.Equal(t, []string{"application/json"}, mediaTypes(route.operation.Responses.Value("404").Value.Content))
+
+	route = route.AddErrorResponse(http.StatusConflict, ContentTypeXML, ContentTypeJSON)
+	assert.Equal(t, []string{"application/json", "application/xml"}, mediaTypes(route.operation.Responses.Value("409").Value.Content))
+
+	problemApp := newNegotiationApp(ErrorFormatProblem)
+
+	feeds = problemApp.openAPISpec.Paths.Find("/feeds").Get
+	assert.Equal(t, []string{"application/problem+json", "application/problem+xml"},
+		mediaTypes(feeds.Responses.Value("400").Value.Content))
+}
@@ -0,0 +1,225 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResponseValidationError describes a response which does not conform to its operation in the OpenAPI spec.
+type ResponseValidationError struct {
+	Method string // Method of the request
+	Route  string // Path of the matched route, e.g. /users/:id
+	Status int    // Status code of the response
+	Path   string // JSON pointer of the invalid value in the body, e.g. /items/0/name, empty if the body is not at fault
+	Err    error  // Mismatch reported by openapi3filter
+}
+
+func (e *ResponseValidationError) Error() string {
+	message := fmt.Sprintf("%s %s: the %d response does not conform to the OpenAPI spec", e.Method, e.Route, e.Status)
+	if e.Path != "" {
+		message += " at " + e.Path
+	}
+
+	return message + ": " + e.Err.Error()
+}
+
+func (e *ResponseValidationError) Unwrap() error {
+	return e.Err
+}
+
+// SetResponseValidation validates the responses against their operation in the OpenAPI spec: their status code,
+// their content type, their headers and their body. The mismatches are logged and passed to the hooks, e.g. to fail
+// the tests with litetest.FailInvalidResponses. The responses are left as is.
+// Meant for the dev mode and the tests, as it decodes every response, it is off by default.
+func SetResponseValidation(hooks ...func(ctx context.Context, err *ResponseValidationError)) Config {
+	return func(s *App) {
+		s.responseValidator = &responseValidator{hooks: hooks, stale: true}
+	}
+}
+
+// responseValidator validates the responses against a copy of the spec whose refs are resolved.
+type responseValidator struct {
+	hooks []func(ctx context.Context, err *ResponseValidationError)
+
+	mu    sync.Mutex
+	stale bool // the spec has changed since the copy was loaded
+	doc   *openapi3.T
+	err   error
+}
+
+// load loads the copy of the spec on the first response, and again once the spec has changed,
+// e.g. when a route is registered after the first response.
+func (v *responseValidator) load(spec *openapi3.T) (*openapi3.T, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.stale {
+		v.doc, v.err = copyOpenAPISpec(spec)
+		v.stale = false
+	}
+
+	return v.doc, v.err
+}
+
+// specChanged marks the copy of the spec validating the responses as stale, once the spec has changed.
+func (s *App) specChanged() {
+	if s == nil || s.responseValidator == nil {
+		return
+	}
+
+	s.responseValidator.mu.Lock()
+	s.responseValidator.stale = true
+	s.responseValidator.mu.Unlock()
+}
+
+// responseValidationMiddleware validates the responses of the next handlers, the errors being rendered first.
+func (s *App) responseValidationMiddleware(c *fiber.Ctx) error {
+	if err := c.Next(); err != nil {
+		if err = s.errorHandler(c, err); err != nil {
+			return err
+		}
+	}
+
+	doc, err := s.responseValidator.load(&s.openAPISpec)
+	if err != nil {
+		s.logger.ErrorContext(c.UserContext(), "failed to load the OpenAPI spec to validate the responses",
+			slog.Any("error", err))
+
+		return nil
+	}
+
+	validationError := validateResponse(c, doc)
+	if validationError == nil {
+		return nil
+	}
+
+	s.logger.ErrorContext(c.UserContext(), "invalid response",
+		slog.String("method", validationError.Method),
+		slog.String("route", validationError.Route),
+		slog.Int("status", validationError.Status),
+		slog.String("path", validationError.Path),
+		slog.Any("error", validationError.Err),
+	)
+
+	for _, hook := range s.responseValidator.hooks {
+		hook(c.UserContext(), validationError)
+	}
+
+	return nil
+}
+
+// validateResponse validates the response against the operation of the matched route. The responses of the unknown
+// routes, of the routes registered on the fiber app directly and the streamed ones are not validated.
+func validateResponse(c *fiber.Ctx, doc *openapi3.T) *ResponseValidationError {
+	routePath, ok := matchedRoute(c)
+	if !ok || c.Response().IsBodyStream() || c.Response().StatusCode() == StatusSwitchingProtocols {
+		return nil
+	}
+
+	openAPIPath, _ := parseRoutePath(routePath)
+
+	pathItem := doc.Paths.Find(openAPIPath)
+	if pathItem == nil || pathItem.GetOperation(c.Method()) == nil {
+		return nil
+	}
+
+	status := c.Response().StatusCode()
+	header := make(http.Header)
+
+	c.Response().Header.VisitAll(func(key, value []byte) {
+		header.Add(string(key), string(value))
+	})
+
+	route := &routers.Route{
+		Spec:      doc,
+		Path:      openAPIPath,
+		PathItem:  pathItem,
+		Method:    c.Method(),
+		Operation: pathItem.GetOperation(c.Method()),
+	}
+	req := &http.Request{Method: c.Method(), URL: &url.URL{Path: c.Path()}, Header: make(http.Header)}
+
+	err := ValidateResponse(c.UserContext(), route, req, &http.Response{StatusCode: status, Header: header},
+		c.Response().Body())
+	if err == nil {
+		return nil
+	}
+
+	validationError := &ResponseValidationError{
+		Method: c.Method(),
+		Route:  routePath,
+		Status: status,
+		Err:    err,
+	}
+
+	var schemaError *openapi3.SchemaError
+	if errors.As(err, &schemaError) {
+		validationError.Path = "/" + strings.Join(schemaError.JSONPointer(), "/")
+	}
+
+	return validationError
+}
+
+// ValidateResponse validates the response to the request, with its body, against the operation of the route in the
+// OpenAPI spec: its status code, its content type, its headers and its body. The bodies of the content types that
+// openapi3filter cannot decode, e.g. XML, are not validated, only their content type. It is the validation of
+// SetResponseValidation, also used by litetest.Fuzz.
+func ValidateResponse(ctx context.Context, route *routers.Route, req *http.Request, resp *http.Response,
+	body []byte,
+) error {
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request: req,
+			Route:   route,
+		},
+		Status:  resp.StatusCode,
+		Header:  resp.Header,
+		Options: &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+
+	input.SetBodyBytes(body)
+
+	if err := checkUndecodableContentType(input, route.Operation); err != nil {
+		return err
+	}
+
+	return openapi3filter.ValidateResponse(ctx, input)
+}
+
+// checkUndecodableContentType checks that the content type of a response that openapi3filter cannot decode,
+// e.g. XML, is documented, and excludes its body from the validation.
+func checkUndecodableContentType(input *openapi3filter.ResponseValidationInput, operation *openapi3.Operation) error {
+	mediaType, _, _ := mime.ParseMediaType(input.Header.Get(HeaderContentType))
+	if mediaType == "" || openapi3filter.RegisteredBodyDecoder(mediaType) != nil {
+		return nil
+	}
+
+	input.Options.ExcludeResponseBody = true
+
+	response := operation.Responses.Status(input.Status)
+	if response == nil {
+		response = operation.Responses.Default()
+	}
+
+	if response != nil && response.Value != nil && len(response.Value.Content) > 0 &&
+		response.Value.Content.Get(mediaType) == nil {
+		return &openapi3filter.ResponseError{
+			Input:  input,
+			Reason: fmt.Sprintf("response Content-Type %q is not documented", mediaType),
+		}
+	}
+
+	return nil
+}
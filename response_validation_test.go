@@ -0,0 +1,247 @@
+package lite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/go-lite/lite/mime"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type validatedItem struct {
+	Name  string `json:"name" xml:"name"`
+	Count int    `json:"count" xml:"count"`
+}
+
+// driftingItem is documented as a validatedItem, but its count is encoded as a string.
+type driftingItem struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func (d driftingItem) MarshalJSON() ([]byte, error) {
+	return []byte(`{"name":"` + d.Name + `","count":"many"}`), nil
+}
+
+type validatedItems struct {
+	Items []driftingItem `json:"items"`
+}
+
+func newValidatedApp(t *testing.T) (*App, *[]*ResponseValidationError) {
+	t.Helper()
+
+	var validationErrors []*ResponseValidationError
+
+	app := New(SetResponseValidation(func(_ context.Context, err *ResponseValidationError) {
+		validationErrors = append(validationErrors, err)
+	}))
+
+	Get(app, "/items/:id", func(c *ContextNoRequest) (validatedItem, error) {
+		switch c.ctx.Params("id") {
+		case "teapot":
+			c.Status(http.StatusTeapot)
+		case "missing":
+			return validatedItem{}, NewNotFoundError("Item not found")
+		case "panic":
+			panic("boom")
+		}
+
+		return validatedItem{Name: "pen", Count: 1}, nil
+	})
+
+	Get(app, "/lists", func(_ *ContextNoRequest) (validatedItems, error) {
+		return validatedItems{Items: []driftingItem{{Name: "pen"}}}, nil
+	})
+
+	Get(app, "/xml", func(c *ContextNoRequest) (validatedItem, error) {
+		c.SetContentType(mime.ApplicationXML)
+
+		return validatedItem{Name: "pen"}, nil
+	}).SetResponseContentType("application/xml")
+
+	// documented in XML, but sent in JSON
+	Get(app, "/xml-drift", func(_ *ContextNoRequest) (validatedItem, error) {
+		return validatedItem{Name: "pen"}, nil
+	}).SetResponseContentType("application/xml")
+
+	return app, &validationErrors
+}
+
+func TestResponseValidation(t *testing.T) {
+	tests := []struct {
+		target string
+		status int
+		path   string
+		error  string
+	}{
+		{target: "/items/1", status: http.StatusOK},
+		{target: "/xml", status: http.StatusOK},
+		{target: "/unknown", status: http.StatusNotFound},
+		{target: "/items/panic", status: http.StatusInternalServerError},
+		{target: "/xml-drift", status: http.StatusOK, error: `Content-Type has unexpected value: "application/json"`},
+		{target: "/items/teapot", status: http.StatusTeapot, error: "status is not supported"},
+		{target: "/items/missing", status: http.StatusNotFound, error: "status is not supported"},
+		{target: "/lists", status: http.StatusOK, path: "/items/0/count", error: `value must be an integer`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			app, validationErrors := newValidatedApp(t)
+
+			resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, tt.target, nil))
+			require.NoError(t, err)
+			assert.Equal(t, tt.status, resp.StatusCode)
+
+			if tt.error == "" {
+				assert.Empty(t, *validationErrors)
+
+				return
+			}
+
+			require.Len(t, *validationErrors, 1)
+
+			validationError := (*validationErrors)[0]
+			assert.Equal(t, http.MethodGet, validationError.Method)
+			assert.Equal(t, tt.status, validationError.Status)
+			assert.Equal(t, tt.path, validationError.Path)
+			assert.ErrorContains(t, validationError, tt.error)
+		})
+	}
+}
+
+func TestResponseValidation_Route(t *testing.T) {
+	app, validationErrors := newValidatedApp(t)
+
+	_, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/lists", nil))
+	require.NoError(t, err)
+
+	require.Len(t, *validationErrors, 1)
+	assert.Equal(t, "/lists", (*validationErrors)[0].Route)
+	assert.Contains(t, (*validationErrors)[0].Error(),
+		"GET /lists: the 200 response does not conform to the OpenAPI spec at /items/0/count: ")
+}
+
+func TestResponseValidation_UndocumentedContentType(t *testing.T) {
+	app, validationErrors := newValidatedApp(t)
+
+	// the JSON response is sent as XML
+	Get(app, "/raw", func(_ *ContextNoRequest) (validatedItem, error) {
+		return validatedItem{}, nil
+	}, func(c *fiber.Ctx) error {
+		err := c.Next()
+		c.Set(HeaderContentType, "application/xml")
+
+		return err
+	})
+
+	_, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/raw", nil))
+	require.NoError(t, err)
+
+	require.Len(t, *validationErrors, 1)
+	assert.ErrorContains(t, (*validationErrors)[0], `response Content-Type "application/xml" is not documented`)
+}
+
+func TestResponseValidation_Disabled(t *testing.T) {
+	app := New()
+	assert.Nil(t, app.responseValidator)
+
+	Get(app, "/teapot", func(c *ContextNoRequest) (string, error) {
+		c.Status(http.StatusTeapot)
+
+		return "", nil
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/teapot", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTeapot, resp.StatusCode)
+}
+
+func TestResponseValidation_RootRoute(t *testing.T) {
+	app, validationErrors := newValidatedApp(t)
+
+	Get(app, "/", func(c *ContextNoRequest) (validatedItem, error) {
+		c.Status(http.StatusTeapot)
+
+		return validatedItem{}, nil
+	})
+
+	// the routes registered on the fiber app directly are not documented, nor validated
+	app.app.Get("/fiber", func(c *fiber.Ctx) error {
+		return c.SendStatus(http.StatusTeapot)
+	})
+
+	for _, target := range []string{"/", "/fiber", "/unknown"} {
+		_, err := app.app.Test(httptest.NewRequest(http.MethodGet, target, nil))
+		require.NoError(t, err)
+	}
+
+	require.Len(t, *validationErrors, 1)
+	assert.Equal(t, "/", (*validationErrors)[0].Route)
+	assert.ErrorContains(t, (*validationErrors)[0], "status is not supported")
+}
+
+func TestResponseValidation_SpecChanged(t *testing.T) {
+	app, validationErrors := newValidatedApp(t)
+
+	_, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/items/1", nil))
+	require.NoError(t, err)
+	require.Empty(t, *validationErrors)
+
+	// the route registered after the first response is validated against the spec documenting it
+	route := Get(app, "/late", func(c *ContextNoRequest) (validatedItem, error) {
+		c.Status(http.StatusTeapot)
+
+		return validatedItem{}, nil
+	})
+
+	_, err = app.app.Test(httptest.NewRequest(http.MethodGet, "/late", nil))
+	require.NoError(t, err)
+
+	require.Len(t, *validationErrors, 1)
+	assert.Equal(t, "/late", (*validationErrors)[0].Route)
+	assert.ErrorContains(t, (*validationErrors)[0], "status is not supported")
+
+	// so is the route whose operation changed
+	route.AddErrorResponse(http.StatusTeapot)
+
+	_, err = app.app.Test(httptest.NewRequest(http.MethodGet, "/late", nil))
+	require.NoError(t, err)
+	assert.Len(t, *validationErrors, 1)
+}
+
+func TestValidateResponse(t *testing.T) {
+	app, _ := newValidatedApp(t)
+
+	doc, err := app.OpenAPISpec()
+	require.NoError(t, err)
+
+	pathItem := doc.Paths.Find("/items/{id}")
+	route := &routers.Route{
+		Spec:      doc,
+		Path:      "/items/{id}",
+		PathItem:  pathItem,
+		Method:    http.MethodGet,
+		Operation: pathItem.Get,
+	}
+	req := httptest.NewRequest(http.MethodGet, "/items/1", nil)
+
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{HeaderContentType: {"application/json"}}}
+	require.NoError(t, ValidateResponse(context.Background(), route, req, resp, []byte(`{"name":"pen","count":1}`)))
+
+	err = ValidateResponse(context.Background(), route, req, resp, []byte(`{"name":"pen","count":"many"}`))
+
+	var schemaError *openapi3.SchemaError
+	require.ErrorAs(t, err, &schemaError)
+	assert.Equal(t, []string{"count"}, schemaError.JSONPointer())
+
+	// the body of an undecodable content type is not validated, only the content type
+	resp.Header.Set(HeaderContentType, "application/xml")
+	assert.ErrorContains(t, ValidateResponse(context.Background(), route, req, resp, []byte("<item/>")),
+		`response Content-Type "application/xml" is not documented`)
+}
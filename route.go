@@ -1,79 +1,235 @@
 package lite
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"slices"
 	"strconv"
 
 	"github.com/getkin/kin-openapi/openapi3"
 )
 
 type Route[T, B any] struct {
+	app         *App
 	operation   *openapi3.Operation
 	path        string
 	method      string
 	contentType string
 	statusCode  int
+	settings    *routeSettings
+}
+
+// Method returns the HTTP method of the route.
+func (r Route[ResponseBody, Request]) Method() string {
+	return r.method
+}
+
+// Path returns the path of the route, prefixed with the base path of its group.
+func (r Route[ResponseBody, Request]) Path() string {
+	if r.app == nil {
+		return r.path
+	}
+
+	return r.app.basePath + r.path
 }
 
 func (r Route[ResponseBody, Request]) Description(description string) Route[ResponseBody, Request] {
 	r.operation.Description = description
+	r.app.specChanged()
 
 	return r
 }
 
 func (r Route[ResponseBody, Request]) Summary(summary string) Route[ResponseBody, Request] {
 	r.operation.Summary = summary
+	r.app.specChanged()
 
 	return r
 }
 
 func (r Route[ResponseBody, Request]) OperationID(operationID string) Route[ResponseBody, Request] {
 	r.operation.OperationID = operationID
+	r.app.specChanged()
 
 	return r
 }
 
 func (r Route[ResponseBody, Request]) Deprecated() Route[ResponseBody, Request] {
 	r.operation.Deprecated = true
+	r.app.specChanged()
 
 	return r
 }
 
 func (r Route[ResponseBody, Request]) AddTags(tags ...string) Route[ResponseBody, Request] {
 	r.operation.Tags = tags
+	r.app.specChanged()
 
 	return r
 }
 
 func (r Route[ResponseBody, Request]) SetResponseContentType(contentType ContentType) Route[ResponseBody, Request] {
-	r.operation.Responses.Value(strconv.Itoa(r.statusCode)).Value.Content[string(contentType)] = r.operation.Responses.
-		Value(strconv.Itoa(r.statusCode)).Value.Content[r.contentType]
+	for _, statusCode := range []int{r.statusCode, StatusPartialContent} {
+		response := r.operation.Responses.Value(strconv.Itoa(statusCode))
+		if response == nil || response.Value == nil {
+			continue
+		}
+
+		mediaType, ok := response.Value.Content[r.contentType]
+		if !ok && statusCode != r.statusCode {
+			continue
+		}
+
+		response.Value.Content[string(contentType)] = mediaType
+
+		delete(response.Value.Content, r.contentType)
+	}
+
+	if r.app != nil {
+		r.setErrorResponsesContentType(string(contentType))
+	}
 
-	delete(r.operation.Responses.Value(strconv.Itoa(r.statusCode)).Value.Content, r.contentType)
+	r.contentType = string(contentType)
+	r.app.specChanged()
 
 	return r
 }
 
+// setErrorResponsesContentType documents the error responses of the route in the content types of the errors
+// of a route responding in the given content type.
+func (r Route[ResponseBody, Request]) setErrorResponsesContentType(contentType string) {
+	for code, response := range r.operation.Responses.Map() {
+		if statusCode, err := strconv.Atoi(code); err != nil || statusCode < StatusBadRequest || response.Value == nil {
+			continue
+		}
+
+		var schema *openapi3.SchemaRef
+
+		for _, mediaType := range response.Value.Content {
+			if mediaType.Schema != nil && mediaType.Schema.Ref == "#/components/schemas/httpGenericError" {
+				schema = mediaType.Schema
+			}
+		}
+
+		if schema != nil {
+			response.Value.Content = openapi3.NewContentWithSchemaRef(schema, r.app.errorContentTypes(contentType))
+		}
+	}
+}
+
 func (r Route[ResponseBody, Request]) AddErrorResponse(statusCode int, contentType ...ContentType) Route[ResponseBody, Request] {
 	if len(contentType) == 0 {
 		contentType = []ContentType{ContentType(r.contentType)}
 	}
 
+	if r.app != nil {
+		contentType = r.errorContentTypes(contentType...)
+	}
+
+	var contentTypes []string
 	for _, c := range contentType {
-		httpError := NewError(statusCode)
-		description := httpError.Descriptions()
+		contentTypes = append(contentTypes, string(c))
+	}
 
-		response := openapi3.NewResponse().WithDescription(description)
+	httpError := NewError(statusCode)
+	description := httpError.Descriptions()
 
-		content := openapi3.NewContentWithSchemaRef(
-			openapi3.NewSchemaRef(
-				"#/components/schemas/httpGenericError",
-				&openapi3.Schema{}),
-			[]string{string(c)},
-		)
-		response.WithContent(content)
+	response := openapi3.NewResponse().WithDescription(description)
+
+	content := openapi3.NewContentWithSchemaRef(
+		openapi3.NewSchemaRef(
+			"#/components/schemas/httpGenericError",
+			&openapi3.Schema{}),
+		contentTypes,
+	)
+	response.WithContent(content)
+
+	r.operation.AddResponse(statusCode, response)
+	r.app.specChanged()
+
+	return r
+}
 
-		r.operation.AddResponse(statusCode, response)
+// errorContentTypes returns the content types in which the errors of the route are written for the given content types,
+// in the error format of the app.
+func (r Route[ResponseBody, Request]) errorContentTypes(contentType ...ContentType) []ContentType {
+	var errorContentTypes []ContentType
+
+	for _, c := range contentType {
+		for _, errorContentType := range r.app.errorContentTypes(string(c)) {
+			if !slices.Contains(errorContentTypes, ContentType(errorContentType)) {
+				errorContentTypes = append(errorContentTypes, ContentType(errorContentType))
+			}
+		}
+	}
+
+	return errorContentTypes
+}
+
+// AddTypedErrorResponse documents an error response whose body is an ErrorBody, returned by the handler as a TypedError.
+// The ErrorBody type gets its own component schema. The content types default to application/json, and to the XML
+// or YAML content type of the route, in which the TypedError is negotiated.
+func AddTypedErrorResponse[ErrorBody, ResponseBody, Request any](
+	r Route[ResponseBody, Request],
+	statusCode int,
+	contentType ...ContentType,
+) Route[ResponseBody, Request] {
+	if len(contentType) == 0 {
+		contentType = typedErrorContentTypes(r.contentType)
+	}
+
+	name, err := setErrorBodySchema(r.app, reflect.TypeOf(new(ErrorBody)).Elem())
+	if err != nil {
+		slog.ErrorContext(context.Background(), "failed to register error response schema", slog.Any("error", err))
+		panic(err)
+	}
+
+	var contentTypes []string
+	for _, c := range contentType {
+		contentTypes = append(contentTypes, string(c))
 	}
 
+	response := openapi3.NewResponse().
+		WithDescription(StatusMessage(statusCode)).
+		WithContent(openapi3.NewContentWithSchemaRef(
+			openapi3.NewSchemaRef("#/components/schemas/"+name, &openapi3.Schema{}),
+			contentTypes,
+		))
+
+	r.operation.AddResponse(statusCode, response)
+	r.app.specChanged()
+
 	return r
 }
+
+// setErrorBodySchema adds the component schema of an error body type and returns its name,
+// suffixed with a hash if another type has the same name.
+func setErrorBodySchema(s *App, fieldType reflect.Type) (string, error) {
+	schema, err := generatorNewSchemaRefForValue(reflect.New(fieldType).Elem().Interface(), s.openAPISpec.Components.Schemas)
+	if err != nil {
+		return "", InternalServerError{
+			Context:     "/api/contexts/OpenAPIError",
+			Type:        "OpenAPIError",
+			Title:       "Response error",
+			Description: "Failed to generate schema",
+			Violations: []Violation{
+				{
+					PropertyPath: fieldType.Name(),
+					Message:      err.Error(),
+				},
+			},
+		}
+	}
+
+	name := dive(fieldType, 4)
+
+	if existingSchema, ok := s.openAPISpec.Components.Schemas[name]; ok && !reflect.DeepEqual(existingSchema.Value, schema.Value) {
+		name += computeHash(fmt.Sprintf("%v", schema.Value))
+	}
+
+	s.openAPISpec.Components.Schemas[name] = schema
+
+	return name, nil
+}
@@ -11,6 +11,15 @@ type ResponseBody struct{}
 
 type Request struct{}
 
+func TestRoute_MethodPath(t *testing.T) {
+	route := Get(Group(New(), "/users"), "/:id", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+
+	assert.Equal(t, "GET", route.Method())
+	assert.Equal(t, "/users/:id", route.Path())
+}
+
 func TestRoute_Description(t *testing.T) {
 	operation := &openapi3.Operation{}
 	route := Route[ResponseBody, Request]{
@@ -113,3 +122,50 @@ func TestRoute_AddErrorResponse(t *testing.T) {
 
 	assert.Equal(t, "Bad Request", *route.operation.Responses.Value("400").Value.Description)
 }
+
+type conflictDetails struct {
+	Resource   string `json:"resource"`
+	ExistingID int    `json:"existingId"`
+}
+
+type unprocessableDetails struct {
+	Field  string   `json:"field"`
+	Reason string   `json:"reason"`
+	Hints  []string `json:"hints,omitempty"`
+}
+
+func TestAddTypedErrorResponse(t *testing.T) {
+	app := New()
+
+	route := Post(app, "/orders", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+	route = AddTypedErrorResponse[conflictDetails](route, StatusConflict)
+	route = AddTypedErrorResponse[unprocessableDetails](route, StatusUnprocessableEntity, ContentTypeJSON, ContentTypeXML)
+
+	conflict := route.operation.Responses.Value("409").Value
+	assert.Equal(t, "Conflict", *conflict.Description)
+	assert.Equal(t, "#/components/schemas/conflictDetails", conflict.Content.Get("application/json").Schema.Ref)
+
+	unprocessable := route.operation.Responses.Value("422").Value
+	assert.Equal(t, "#/components/schemas/unprocessableDetails", unprocessable.Content.Get("application/xml").Schema.Ref)
+
+	schema := app.openAPISpec.Components.Schemas["conflictDetails"]
+	assert.NotNil(t, schema)
+	assert.Contains(t, schema.Value.Properties, "existingId")
+	assert.Contains(t, app.openAPISpec.Components.Schemas["unprocessableDetails"].Value.Properties, "hints")
+
+	// The typed errors are documented in the content type of the route too.
+	xmlRoute := Post(app, "/invoices", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	}).SetResponseContentType(ContentTypeXML)
+	xmlRoute = AddTypedErrorResponse[conflictDetails](xmlRoute, StatusConflict)
+
+	conflict = xmlRoute.operation.Responses.Value("409").Value
+	assert.NotNil(t, conflict.Content.Get("application/json"))
+	assert.NotNil(t, conflict.Content.Get("application/xml"))
+
+	// The generic error responses are still documented.
+	assert.Equal(t, "#/components/schemas/httpGenericError",
+		route.operation.Responses.Value("400").Value.Content.Get("application/json").Schema.Ref)
+}
@@ -5,13 +5,33 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
-	"log/slog"
 	"net/url"
 	"reflect"
 
 	"github.com/valyala/fasthttp"
 )
 
+// newSerializationError returns the error of a response that could not be encoded, a server-side failure.
+func newSerializationError(description string) InternalServerError {
+	return InternalServerError{
+		Context:     "/api/contexts/SerializationError",
+		Type:        "SerializationError",
+		Status:      StatusInternalServerError,
+		Title:       "Serialization error",
+		Description: description,
+	}
+}
+
+// serializationError returns the error rendered for a response that could not be encoded.
+// The encoder errors describe the internals of the app, so they are only rendered in dev mode.
+func (s *App) serializationError(err error) error {
+	if s.devMode {
+		return err
+	}
+
+	return newSerializationError("The response could not be serialized")
+}
+
 func serializeResponse(ctx *fasthttp.RequestCtx, src any) error {
 	if src == nil {
 		return nil
@@ -31,22 +51,8 @@ func serializeResponse(ctx *fasthttp.RequestCtx, src any) error {
 	case reflect.Invalid, reflect.Chan, reflect.Func, reflect.Complex64, reflect.Complex128,
 		reflect.Uintptr, reflect.UnsafePointer:
 		err := fmt.Errorf("unsupported type: %s", srcVal.Kind())
-		slog.Error("error serializing response", slog.Any("error", err))
-
-		ctx.Error(err.Error(), StatusInternalServerError)
-
-		return BadRequestError{
-			Context:     "/api/contexts/SerializationError",
-			Type:        "SerializationError",
-			Title:       "Bad Request error",
-			Description: err.Error(),
-			Violations: []Violation{
-				{
-					PropertyPath: "body response",
-					Message:      err.Error(),
-				},
-			},
-		}
+
+		return newSerializationError(err.Error())
 	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint,
 		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64,
 		reflect.Array, reflect.Interface, reflect.Map, reflect.Slice, reflect.Struct, reflect.Ptr:
@@ -62,39 +68,13 @@ func serialize(ctx *fasthttp.RequestCtx, srcVal reflect.Value) error {
 	switch ContentType(contentType) {
 	case ContentTypeJSON:
 		if err := json.NewEncoder(ctx).Encode(srcVal.Interface()); err != nil {
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding json failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding json failed with error: " + err.Error())
 		}
 	case ContentTypeXML:
 		if err := xml.NewEncoder(ctx).Encode(srcVal.Interface()); err != nil {
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding xml failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding xml failed with error: " + err.Error())
 		}
 	case ContentTypeXFormData, ContentTypeFormData:
 		if form, ok := srcVal.Interface().(map[string]string); ok {
@@ -107,21 +87,8 @@ func serialize(ctx *fasthttp.RequestCtx, srcVal reflect.Value) error {
 			ctx.SetBody([]byte(formData.Encode()))
 		} else {
 			err := errors.New("expected map[string]string for form data serialization")
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding form data failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding form data failed with error: " + err.Error())
 		}
 	case ContentTypeOctetStream, ContentTypePDF, ContentTypeZIP, ContentTypePNG, ContentTypeJPEG, ContentTypeGIF,
 		ContentTypeWEBP, ContentTypeSVG, ContentTypeTIFF, ContentTypeICO, ContentTypeJNG, ContentTypeDOC, ContentTypeBMP,
@@ -135,21 +102,8 @@ func serialize(ctx *fasthttp.RequestCtx, srcVal reflect.Value) error {
 			ctx.SetBody(data)
 		} else {
 			err := errors.New("expected []byte for binary file serialization")
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding binary file failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding binary file failed with error: " + err.Error())
 		}
 	case ContentTypeTXT, ContentTypeHTML, ContentTypeCSS, ContentTypeJS, ContentTypeATOM,
 		ContentTypeRSS, ContentTypeMML, ContentTypeJAD, ContentTypeWML, ContentTypeHTC:
@@ -157,21 +111,8 @@ func serialize(ctx *fasthttp.RequestCtx, srcVal reflect.Value) error {
 			ctx.SetBodyString(data)
 		} else {
 			err := errors.New("expected string for text serialization")
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding text failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding text failed with error: " + err.Error())
 		}
 	case ContentTypeMIDI, ContentTypeMP3, ContentTypeOGG, ContentTypeM4A, ContentTypeRA,
 		ContentType3GP, ContentTypeTS, ContentTypeMP4, ContentTypeMPEG, ContentTypeMOV,
@@ -181,39 +122,13 @@ func serialize(ctx *fasthttp.RequestCtx, srcVal reflect.Value) error {
 			ctx.SetBody(data)
 		} else {
 			err := errors.New("expected []byte for binary file serialization")
-			ctx.Error(err.Error(), StatusInternalServerError)
-			slog.Error("error serializing response", slog.Any("error", err))
-
-			return BadRequestError{
-				Context:     "/api/contexts/SerializationError",
-				Type:        "SerializationError",
-				Title:       "Bad Request error",
-				Description: "Failed to serialize response, encoding binary file failed with error: " + err.Error(),
-				Violations: []Violation{
-					{
-						PropertyPath: "body response",
-						Message:      err.Error(),
-					},
-				},
-			}
+
+			return newSerializationError("Failed to serialize response, encoding binary file failed with error: " + err.Error())
 		}
 	default:
 		err := fmt.Errorf("unsupported content type: %s", contentType)
-		ctx.Error(err.Error(), StatusInternalServerError)
-		slog.Error("error serializing response", slog.Any("error", err))
-
-		return BadRequestError{
-			Context:     "/api/contexts/SerializationError",
-			Type:        "SerializationError",
-			Title:       "Bad Request error",
-			Description: err.Error(),
-			Violations: []Violation{
-				{
-					PropertyPath: "body response",
-					Message:      err.Error(),
-				},
-			},
-		}
+
+		return newSerializationError(err.Error())
 	}
 
 	return nil
@@ -1,9 +1,13 @@
 package lite
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
 	"testing"
 )
@@ -118,7 +122,9 @@ func TestSerializeResponse(t *testing.T) {
 				assert.NotNil(t, err)
 				assert.Equal(t, tt.expectedErr.Error(), err.Error())
 				if tt.expectedErrCode != 0 {
-					assert.Equal(t, tt.expectedErrCode, ctx.Response.StatusCode())
+					httpError, ok := asHTTPError(err)
+					assert.True(t, ok)
+					assert.Equal(t, tt.expectedErrCode, httpError.StatusCode())
 				}
 			} else {
 				assert.Nil(t, err)
@@ -272,7 +278,9 @@ func TestSerialize(t *testing.T) {
 				assert.NotNil(t, err)
 				assert.Equal(t, tt.expectedErr.Error(), err.Error())
 				if tt.expectedErrCode != 0 {
-					assert.Equal(t, tt.expectedErrCode, ctx.Response.StatusCode())
+					httpError, ok := asHTTPError(err)
+					assert.True(t, ok)
+					assert.Equal(t, tt.expectedErrCode, httpError.StatusCode())
 				}
 			} else {
 				assert.Nil(t, err)
@@ -281,3 +289,46 @@ func TestSerialize(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializationError(t *testing.T) {
+	newBrokenApp := func(config ...Config) *App {
+		app := New(config...)
+
+		Get(app, "/broken", func(c *ContextNoRequest) (any, error) {
+			return map[string]any{"callback": func() {}}, nil
+		})
+
+		return app
+	}
+
+	tests := []struct {
+		name        string
+		app         *App
+		description string
+	}{
+		{
+			name:        "generic description",
+			app:         newBrokenApp(),
+			description: "The response could not be serialized",
+		},
+		{
+			name:        "encoder error in dev mode",
+			app:         newBrokenApp(SetEnvironment(EnvironmentDevelopment), SetDevMode(true)),
+			description: "Failed to serialize response, encoding json failed with error: json: unsupported type: func()",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.app.app.Test(httptest.NewRequest(http.MethodGet, "/broken", nil))
+			require.NoError(t, err)
+
+			var httpError HTTPError
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&httpError))
+
+			assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+			assert.Equal(t, "SerializationError", httpError.Type)
+			assert.Equal(t, tt.description, httpError.Description)
+		})
+	}
+}
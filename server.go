@@ -2,13 +2,18 @@ package lite
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"strconv"
+	"runtime"
 	"strings"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 
@@ -45,6 +50,7 @@ type config struct {
 	swaggerURL       string                             // URL to serve the swagger ui
 	uiHandler        func(specURL string) fiber.Handler // Handler to serve the openapi ui from spec url
 	openapiPath      string                             // Local path to save the openapi json spec
+	asyncapiPath     string                             // Local path to save the asyncapi spec, next to the openapi spec if empty
 	typeOfExtension  TypeOfExtension                    // Type of extension to use for the openapi spec
 }
 
@@ -81,35 +87,114 @@ var defaultOpenAPIConfig = config{
 type App struct {
 	app *fiber.App
 
-	openAPISpec   openapi3.T
-	openAPIConfig config
+	openAPISpec      openapi3.T
+	openAPIConfig    config
+	openAPISpecBuilt bool
+	openAPIData      []byte // OpenAPI spec served by the docs, marshaled by setup
+	asyncAPIData     []byte // AsyncAPI spec served by the docs, marshaled by setup
 
 	tag string
 
 	basePath  string
 	address   string // Address to listen on
+	tls       tlsFiles
 	serverURL string
 
+	admin        *fiber.App // App of the admin listener, nil without admin address
+	adminAddress string
+	metrics      *routeMetrics // Metrics of the routes, served by the admin listener
+
+	configErrors []error // Errors of the configs, returned by Run
+
 	mu sync.Mutex
 
 	logger    *slog.Logger
 	validator *validator.Validate
+
+	errorFormat         ErrorFormat
+	errorContextBaseURL string
+	errorContexts       *errorContextRegistry
+	serveErrorContexts  bool
+	errorMapper         *errorMapper
+
+	panicHook   func(ctx context.Context, report PanicReport)
+	environment Environment
+	devMode     bool
+
+	responseValidator *responseValidator
+
+	timeout          time.Duration
+	detectDisconnect bool
+
+	serverConfig fiber.Config
+	prefork      bool
+	backend      Backend
+	http         *httpBackend
+
+	lifecycle       *lifecycle
+	shutdownTimeout time.Duration
+	shutdownDelay   time.Duration
+
+	health         *healthRegistry
+	livenessPath   string
+	readinessPath  string
+	serveHealth    bool
+	documentHealth bool
+
+	webSocketConfig webSocketConfig
 }
 
 func New(config ...Config) *App {
 	app := &App{
-		app:           fiber.New(),
 		openAPISpec:   newOpenAPISpec(),
 		openAPIConfig: defaultOpenAPIConfig,
 		address:       ":9000",
 		logger:        slog.Default(),
 		validator:     validator.New(),
+
+		errorContextBaseURL: DefaultErrorContextBaseURL,
+		errorContexts:       newErrorContextRegistry(),
+		errorMapper:         newErrorMapper(),
+
+		environment: defaultEnvironment(),
+
+		lifecycle:       newLifecycle(),
+		shutdownTimeout: defaultShutdownTimeout,
+
+		health:        &healthRegistry{},
+		livenessPath:  DefaultLivenessPath,
+		readinessPath: DefaultReadinessPath,
+
+		webSocketConfig: defaultWebSocketConfig,
+
+		http: &httpBackend{},
 	}
 
 	for _, c := range config {
 		c(app)
 	}
 
+	app.serverConfig.ErrorHandler = app.errorHandler
+	app.app = fiber.New(app.serverConfig)
+
+	if app.adminAddress != "" {
+		app.metrics = newRouteMetrics()
+		app.app.Use(app.metricsMiddleware)
+	}
+
+	// the responses are validated once rendered, the panics and the errors included
+	if app.responseValidator != nil {
+		app.app.Use(app.responseValidationMiddleware)
+	}
+
+	app.app.Use(app.recoverMiddleware, app.requestContextMiddleware)
+
+	app.checkDevMode()
+
+	if app.adminAddress != "" {
+		app.admin = app.newAdminApp()
+	}
+
 	return app
 }
 
@@ -169,16 +254,11 @@ func SetTypeOfExtension(extension TypeOfExtension) Config {
 	}
 }
 
+// SetAddress sets the address the app listens on: host:port, [ipv6]:port, :port or unix:/path/to/socket.
+// Default is :9000.
 func SetAddress(address string) Config {
 	// check if address is valid
-	if !strings.HasPrefix(address, ":") {
-		panic("address must start with :")
-	}
-
-	port := strings.TrimPrefix(address, ":")
-
-	_, err := strconv.Atoi(port)
-	if err != nil {
+	if _, _, err := parseAddress(address); err != nil {
 		panic(err)
 	}
 
@@ -215,6 +295,11 @@ func (s *App) saveOpenAPISpec() ([]byte, error) {
 }
 
 func (s *App) saveOpenAPIToFile(path string, swaggerSpec []byte) error {
+	// the prefork master saves the files before starting the children
+	if s.preforkChild() {
+		return nil
+	}
+
 	jsonFolder := filepath.Dir(path)
 
 	err := osMkdirAll(jsonFolder, 0o750)
@@ -308,28 +393,21 @@ func SetTermsOfService(termsOfService string) Config {
 	}
 }
 
-func (s *App) createDefaultErrorResponses() (map[int]*openapi3.Response, error) {
+func (s *App) createDefaultErrorResponses(resContentType string) (map[int]*openapi3.Response, error) {
 	responses := make(map[int]*openapi3.Response)
 
 	for _, errResponse := range DefaultErrorResponses {
-		responseSchema, ok := s.openAPISpec.Components.Schemas["httpGenericError"]
-		if !ok {
-			var err error
-
-			responseSchema, err = generatorNewSchemaRefForValue(new(HTTPError), s.openAPISpec.Components.Schemas)
-			if err != nil {
-				slog.ErrorContext(context.Background(), "failed to generate schema", slog.Any("error", err))
-
-				return nil, err
-			}
+		responseSchema, err := s.errorSchema()
+		if err != nil {
+			slog.ErrorContext(context.Background(), "failed to generate schema", slog.Any("error", err))
 
-			s.openAPISpec.Components.Schemas["httpGenericError"] = responseSchema
+			return nil, err
 		}
 
 		response := openapi3.NewResponse().WithDescription(errResponse.Descriptions())
 
 		var consume []string
-		consume = append(consume, DefaultErrorContentTypeResponses...)
+		consume = append(consume, s.errorContentTypes(resContentType)...)
 
 		if responseSchema != nil {
 			content := openapi3.NewContentWithSchemaRef(
@@ -346,32 +424,102 @@ func (s *App) createDefaultErrorResponses() (map[int]*openapi3.Response, error)
 	return responses, nil
 }
 
-func (s *App) setup() error {
+// OpenAPISpec returns a copy of the OpenAPI spec of the app as it is served, without listening nor registering
+// the routes of the docs, e.g. to compare it with a golden file. The app must be fully registered.
+func (s *App) OpenAPISpec() (*openapi3.T, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if err := s.buildOpenAPISpec(); err != nil {
+		return nil, err
+	}
+
+	return copyOpenAPISpec(&s.openAPISpec)
+}
+
+// copyOpenAPISpec returns a deep copy of the spec, loaded from its JSON encoding.
+func copyOpenAPISpec(spec *openapi3.T) (*openapi3.T, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	return openapi3.NewLoader().LoadFromData(data)
+}
+
+// buildOpenAPISpec completes the spec with the local server, the error contexts and the health probes, whose routes
+// are registered by setup. It is done once, by OpenAPISpec or setup.
+func (s *App) buildOpenAPISpec() error {
+	if s.openAPISpecBuilt {
+		return nil
+	}
+
 	if s.serverURL == "" {
-		s.serverURL = "http://localhost" + s.address
+		s.serverURL = s.localServerURL()
 		s.openAPISpec.Servers = append(s.openAPISpec.Servers, &openapi3.Server{
 			URL:         s.serverURL,
 			Description: "Local server",
 		})
 	}
 
+	if err := s.documentErrorContexts(); err != nil {
+		return err
+	}
+
+	if err := s.documentHealthProbes(); err != nil {
+		return err
+	}
+
+	s.openAPISpecBuilt = true
+	s.specChanged()
+
+	return nil
+}
+
+// setup sets the app up before it is served by Run: it builds the specs, registers the docs and saves the specs
+// to files, next to the working directory.
+func (s *App) setup() error {
+	return s.setupApp(true)
+}
+
+// setupApp sets the app up, saving the specs to files if save is set, unless the local save is disabled with
+// SetDisableLocalSave. The docs serve the specs from memory, so that the app served by ServeHTTP writes no files.
+func (s *App) setupApp(save bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	save = save && !s.openAPIConfig.disableLocalSave
+
+	if err := s.buildOpenAPISpec(); err != nil {
+		return err
+	}
+
+	s.registerErrorContexts()
+	s.registerHealthProbes()
+	s.registerAdminEndpoints()
+
 	if s.openAPIConfig.disableSwagger {
 		return nil
 	}
 
+	// the docs are served by the admin listener if there is one, the Swagger UI then loads the spec from it
+	router := s.docsRouter()
+
+	specURL := s.serverURL + s.openAPIConfig.openapiPath
+	if s.admin != nil {
+		specURL = s.openAPIConfig.openapiPath
+	}
+
 	if !s.openAPIConfig.disableSwagger {
-		s.app.Use(cors.New(cors.Config{
+		router.Use(cors.New(cors.Config{
 			AllowOrigins: "*",
 			AllowMethods: "GET",
 		}))
 
 		// Route to serve the OpenAPI file
-		s.app.Get(s.openAPIConfig.openapiPath, s.openAPIPathHandler)
+		router.Get(s.openAPIConfig.openapiPath, s.openAPIPathHandler)
 
-		s.app.Get(s.openAPIConfig.swaggerURL, s.openAPIConfig.uiHandler(s.serverURL+s.openAPIConfig.openapiPath))
+		router.Get(s.openAPIConfig.swaggerURL, s.openAPIConfig.uiHandler(specURL))
 	}
 
 	swaggerSpec, err := s.saveOpenAPISpec()
@@ -379,6 +527,30 @@ func (s *App) setup() error {
 		return err
 	}
 
+	s.openAPIData = swaggerSpec
+
+	asyncSpec, hasChannels := s.newAsyncAPISpec()
+	if hasChannels {
+		s.asyncAPIData, err = s.saveAsyncAPISpec(asyncSpec)
+		if err != nil {
+			return err
+		}
+
+		if save {
+			err = s.saveOpenAPIToFile("."+s.asyncAPIPath(), s.asyncAPIData)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Route to serve the AsyncAPI file
+		router.Get(s.asyncAPIPath(), s.asyncAPIPathHandler)
+	}
+
+	if !save {
+		return nil
+	}
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 
@@ -396,12 +568,36 @@ func (s *App) setup() error {
 }
 
 func (s *App) openAPIPathHandler(c *fiber.Ctx) error {
-	return c.SendFile("." + s.openAPIConfig.openapiPath)
+	return sendSpec(c, s.openAPIConfig.openapiPath, s.openAPIData)
+}
+
+// sendSpec sends the spec marshaled by setup, with the content type of the extension of its path.
+func sendSpec(c *fiber.Ctx, path string, data []byte) error {
+	c.Type(strings.TrimPrefix(filepath.Ext(path), "."))
+
+	return c.Send(data)
+}
+
+// validateConfig returns the errors of the configuration of the app, e.g. an invalid admin address.
+func (s *App) validateConfig() error {
+	return errors.Join(append(s.configErrors, s.tls.validate())...)
 }
 
+// Listen runs the app on the given address, like Run.
 func (s *App) Listen(address string) error {
 	s.address = address
 
+	return s.Run()
+}
+
+// Run calls the start hooks and serves the app on its address until it is shut down.
+// On SIGTERM or SIGINT, the app is shut down gracefully within the shutdown timeout:
+// the in-flight requests are drained, then the shutdown hooks are called, and Run returns their errors.
+func (s *App) Run() error {
+	if err := s.validateConfig(); err != nil {
+		return err
+	}
+
 	err := s.setup()
 	if err != nil {
 		slog.ErrorContext(context.Background(), "failed to setup", slog.Any("error", err))
@@ -409,20 +605,82 @@ func (s *App) Listen(address string) error {
 		return err
 	}
 
-	return s.app.Listen(address)
-}
+	if s.prefork && !s.preforkChild() {
+		return s.runPrefork()
+	}
 
-func (s *App) Run() error {
-	err := s.setup()
-	if err != nil {
-		slog.ErrorContext(context.Background(), "failed to setup", slog.Any("error", err))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if s.preforkChild() {
+		runtime.GOMAXPROCS(1)
 
+		// the child shuts down when the master exits without shutting it down
+		var stopWatching context.CancelFunc
+
+		ctx, stopWatching = watchPreforkMaster(ctx)
+		defer stopWatching()
+	}
+
+	if err = s.start(ctx); err != nil {
 		return err
 	}
 
-	return s.app.Listen(s.address)
+	ln, err := s.listen()
+	if err != nil {
+		return errors.Join(err, s.runShutdownHooks(ctx))
+	}
+
+	closeAdmin, err := s.serveAdmin()
+	if err != nil {
+		_ = ln.Close()
+
+		return errors.Join(err, s.runShutdownHooks(ctx))
+	}
+
+	// the admin listener is closed on every return, once the app has stopped serving
+	defer closeAdmin()
+
+	served := make(chan error, 1)
+
+	go func() {
+		served <- s.serve(ln)
+	}()
+
+	select {
+	case err = <-served:
+		// the app has been shut down with Shutdown, or has stopped accepting connections
+		if err != nil {
+			return errors.Join(err, s.runShutdownHooks(ctx))
+		}
+
+		return nil
+	case <-ctx.Done():
+	}
+
+	// a second signal stops the process without waiting for the shutdown
+	stop()
+
+	s.logger.InfoContext(ctx, "shutting down", slog.Duration("timeout", s.shutdownTimeout))
+
+	if s.shutdownDelay > 0 {
+		s.lifecycle.shuttingDown.Store(true)
+		time.Sleep(s.shutdownDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+
+	err = s.ShutdownWithContext(shutdownCtx)
+
+	// the listeners are closed even if the signal is received before the apps serve them
+	_ = ln.Close()
+	<-served
+
+	return err
 }
 
+// Shutdown shuts down the app without timeout, see ShutdownWithContext.
 func (s *App) Shutdown() error {
-	return s.app.Shutdown()
+	return s.ShutdownWithContext(context.Background())
 }
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	fiberrecover "github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/invopop/yaml"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewOpenAPISpec(t *testing.T) {
@@ -136,7 +138,7 @@ func TestApp_Setup(t *testing.T) {
 }
 
 func TestApp_Setup_saveOpenAPISpecError(t *testing.T) {
-	app := New()
+	app := New(SetDisableLocalSave(true))
 
 	// Mock yaml.JSONToYAML to simulate an error
 	yamlJSONToYAML = mockJSONToYAML
@@ -161,11 +163,17 @@ func TestOpenAPIHandler(t *testing.T) {
 
 func TestApp_createDefaultErrorResponses(t *testing.T) {
 	app := New()
-	responses, err := app.createDefaultErrorResponses()
+	responses, err := app.createDefaultErrorResponses("application/json")
 
 	assert.Nil(t, err)
 	assert.NotNil(t, responses)
 	assert.Contains(t, responses, 400) // Assuming 400 is in DefaultErrorResponses
+	assert.Equal(t, []string{"application/json"}, mediaTypes(responses[400].Content))
+
+	responses, err = app.createDefaultErrorResponses("application/xml")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"application/json", "application/xml"}, mediaTypes(responses[400].Content))
 }
 
 func TestApp_createDefaultErrorResponses_error(t *testing.T) {
@@ -180,7 +188,7 @@ func TestApp_createDefaultErrorResponses_error(t *testing.T) {
 		return nil, errors.New("error")
 	}
 
-	_, err := app.createDefaultErrorResponses()
+	_, err := app.createDefaultErrorResponses("application/json")
 	assert.NotNil(t, err)
 }
 
@@ -264,7 +272,7 @@ func getFreePort() (int, error) {
 }
 
 func TestApp_Listen(t *testing.T) {
-	app := New()
+	app := New(SetDisableLocalSave(true))
 
 	// Find a free port to avoid conflicts
 	port, err := getFreePort()
@@ -295,7 +303,7 @@ func TestApp_Listen(t *testing.T) {
 }
 
 func TestApp_ListenError(t *testing.T) {
-	app := New()
+	app := New(SetDisableLocalSave(true))
 
 	// Mock yaml.JSONToYAML to simulate an error
 	yamlJSONToYAML = mockJSONToYAML
@@ -312,7 +320,7 @@ func TestApp_Run(t *testing.T) {
 
 	address := fmt.Sprintf(":%d", port)
 
-	app := New(SetAddress(address))
+	app := New(SetDisableLocalSave(true), SetAddress(address))
 
 	// Run Listen in a separate goroutine since it is blocking
 	go func() {
@@ -337,7 +345,7 @@ func TestApp_Run(t *testing.T) {
 }
 
 func TestApp_RunError(t *testing.T) {
-	app := New()
+	app := New(SetDisableLocalSave(true))
 
 	// Mock yaml.JSONToYAML to simulate an error
 	yamlJSONToYAML = mockJSONToYAML
@@ -379,7 +387,7 @@ func TestApp_saveOpenAPIToFile(t *testing.T) {
 		return assert.AnError
 	}
 
-	err := app.saveOpenAPIToFile("test", []byte("test"))
+	err := app.saveOpenAPIToFile(filepath.Join(t.TempDir(), "test"), []byte("test"))
 	assert.Error(t, err)
 }
 
@@ -395,7 +403,7 @@ func TestApp_saveOpenAPIToFile2(t *testing.T) {
 		return nil, assert.AnError
 	}
 
-	err := app.saveOpenAPIToFile("test", []byte("test"))
+	err := app.saveOpenAPIToFile(filepath.Join(t.TempDir(), "test"), []byte("test"))
 	assert.Error(t, err)
 }
 
@@ -421,7 +429,7 @@ func TestApp_saveOpenAPIToFile3(t *testing.T) {
 		return &writeCloserFail{}
 	}
 
-	err := app.saveOpenAPIToFile("test", []byte("test"))
+	err := app.saveOpenAPIToFile(filepath.Join(t.TempDir(), "test"), []byte("test"))
 	assert.Error(t, err)
 }
 
@@ -465,3 +473,60 @@ func TestApp_Setup_saveOpenAPIToFileError(t *testing.T) {
 	// Shutdown the server
 	assert.NoError(t, app.Shutdown())
 }
+
+func TestApp_OpenAPISpec(t *testing.T) {
+	app := New(SetDisableSwagger(true), SetServeHealthProbes(true), SetDocumentHealthProbes(true))
+	Get(app, "/ping", func(_ *ContextNoRequest) (string, error) {
+		return "pong", nil
+	})
+
+	spec, err := app.OpenAPISpec()
+	require.NoError(t, err)
+	assert.NotNil(t, spec.Paths.Find("/ping"))
+	assert.NotNil(t, spec.Paths.Find("/healthz"))
+	assert.Len(t, spec.Servers, 1)
+
+	// the spec is a copy
+	spec.Paths.Delete("/ping")
+	assert.NotNil(t, app.openAPISpec.Paths.Find("/ping"))
+
+	// the routes of the documented probes are registered by setup only
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// the spec is built once, setup does not add the local server again
+	require.NoError(t, app.setup())
+
+	spec, err = app.OpenAPISpec()
+	require.NoError(t, err)
+	assert.Len(t, spec.Servers, 1)
+
+	resp, err = app.app.Test(httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestApp_Setup_DisableLocalSave(t *testing.T) {
+	realOsCreate := osCreate
+	defer func() {
+		osCreate = realOsCreate
+	}()
+
+	osCreate = func(path string) (*os.File, error) {
+		t.Errorf("the spec is saved to %s", path)
+
+		return nil, assert.AnError
+	}
+
+	app := New(SetDisableLocalSave(true))
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	require.NoError(t, app.setup())
+
+	// the specs are still served
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/api/openapi.yaml", nil))
+	require.NoError(t, err)
+	assert.Equal(t, StatusOK, resp.StatusCode)
+}
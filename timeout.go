@@ -0,0 +1,111 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	errRequestTimeout     = errors.New("request timeout")
+	errServerShutdown     = errors.New("server shutting down")
+	errClientDisconnected = errors.New("client disconnected")
+)
+
+// routeSettings holds the settings of a route read by its handlers, changed by the Route methods.
+type routeSettings struct {
+	path       string // full path of the route, as registered on the fiber app
+	timeout    time.Duration
+	bodyLimit  int
+	streamBody bool // the handler reads the request body as a stream, e.g. with a MultipartStream
+	webSocket  bool
+}
+
+// SetTimeout sets the default timeout of the routes, overridden by Route.Timeout. Default is no timeout.
+// The timeout is cooperative, see Route.Timeout.
+func SetTimeout(timeout time.Duration) Config {
+	return func(s *App) {
+		s.timeout = timeout
+	}
+}
+
+// Timeout sets the maximum duration of the handler and of the middlewares of the route.
+// The context of the handler gets a deadline, and a 504 HTTPError is written when it is exceeded.
+// The timeout is cooperative: the 504 is written once the handler returns, which must then watch its context,
+// e.g. by passing it to the database calls. A handler ignoring its context holds its worker until it returns,
+// and its late response is replaced by the 504.
+// The timeout is documented in the x-timeout extension of the operation. A zero timeout disables it.
+func (r Route[ResponseBody, Request]) Timeout(timeout time.Duration) Route[ResponseBody, Request] {
+	r.settings.timeout = timeout
+
+	if timeout <= 0 {
+		delete(r.operation.Extensions, "x-timeout")
+		r.operation.Responses.Delete(strconv.Itoa(StatusGatewayTimeout))
+		r.app.specChanged()
+
+		return r
+	}
+
+	if r.operation.Extensions == nil {
+		r.operation.Extensions = make(map[string]any)
+	}
+
+	r.operation.Extensions["x-timeout"] = timeout.String()
+
+	return r.AddErrorResponse(StatusGatewayTimeout)
+}
+
+// SetDisconnectDetection cancels the context of the handlers when the client disconnects before the response is
+// written, off by default. It watches the connection during every request, so that the slow handlers stop working
+// for the clients which are gone. A client resetting the connection is detected, one closing it gracefully is not:
+// it cannot be told from a client which only closes its half of the connection, and still waits for the response.
+// The disconnections are not detected on Windows, nor while a streamed request body is being read.
+func SetDisconnectDetection(enabled bool) Config {
+	return func(s *App) {
+		s.detectDisconnect = enabled
+	}
+}
+
+// runWithTimeout runs the next handlers of a route with a context canceled when the timeout of the route is exceeded,
+// or when the client disconnects if the detection is enabled. It writes a 504 HTTPError on timeout,
+// instead of the response of the handler, once the handler returns.
+func (s *App) runWithTimeout(c *fiber.Ctx, settings *routeSettings) error {
+	ctx, cancel := context.WithCancelCause(c.UserContext())
+	defer cancel(nil)
+
+	timeout := settings.timeout
+	if timeout > 0 {
+		var cancelTimeout context.CancelFunc
+
+		ctx, cancelTimeout = context.WithTimeoutCause(ctx, timeout, errRequestTimeout)
+		defer cancelTimeout()
+	}
+
+	// the connection is read by the handler while the request body is streamed
+	if s.detectDisconnect && !c.Request().IsBodyStream() {
+		stopWatching := watchDisconnect(c.Context().Conn(), func() {
+			cancel(errClientDisconnected)
+		})
+		defer stopWatching()
+	}
+
+	c.SetUserContext(ctx)
+
+	err := c.Next()
+
+	switch context.Cause(ctx) {
+	case errRequestTimeout:
+		c.Response().ResetBody()
+
+		return s.renderError(c, NewError(StatusGatewayTimeout, fmt.Sprintf("The request timed out after %s", timeout)))
+	case errClientDisconnected:
+		s.logger.InfoContext(c.Context(), "client disconnected", slog.String("path", c.Path()))
+	}
+
+	return err
+}
@@ -0,0 +1,225 @@
+package lite
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitForCancel(c *ContextNoRequest) (string, error) {
+	<-c.Context().Done()
+
+	return "", c.Context().Err()
+}
+
+func TestTimeout(t *testing.T) {
+	app := New(SetTimeout(time.Hour))
+
+	Get(app, "/slow", waitForCancel).Timeout(20 * time.Millisecond)
+
+	Get(app, "/late", func(c *ContextNoRequest) (string, error) {
+		<-c.Context().Done()
+
+		// the response written after the deadline is discarded
+		return "done", nil
+	}).Timeout(20 * time.Millisecond)
+
+	// the timeout is cooperative, the response of the handler ignoring its context is replaced once it returns
+	Get(app, "/ignoring", func(c *ContextNoRequest) (string, error) {
+		time.Sleep(50 * time.Millisecond)
+
+		return "done", nil
+	}).Timeout(20 * time.Millisecond)
+
+	Get(app, "/fast", func(c *ContextNoRequest) (string, error) {
+		deadline, ok := c.Context().Deadline()
+		if !ok || time.Until(deadline) < time.Minute {
+			return "", errors.New("no deadline")
+		}
+
+		return "done", nil
+	})
+
+	for _, target := range []string{"/slow", "/late", "/ignoring"} {
+		resp, document := getJSON(t, app, target)
+
+		assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode, target)
+		assert.Equal(t, "The request timed out after 20ms", document["description"], target)
+	}
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/fast", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestTimeout_OpenAPI(t *testing.T) {
+	app := New(SetTimeout(time.Minute))
+
+	Get(app, "/default", waitForCancel)
+	Get(app, "/route", waitForCancel).Timeout(5 * time.Second)
+	Get(app, "/none", waitForCancel).Timeout(0)
+
+	operation := app.openAPISpec.Paths.Find("/default").Get
+	assert.Equal(t, "1m0s", operation.Extensions["x-timeout"])
+	assert.NotNil(t, operation.Responses.Value("504"))
+
+	operation = app.openAPISpec.Paths.Find("/route").Get
+	assert.Equal(t, "5s", operation.Extensions["x-timeout"])
+
+	operation = app.openAPISpec.Paths.Find("/none").Get
+	assert.NotContains(t, operation.Extensions, "x-timeout")
+	assert.Nil(t, operation.Responses.Value("504"))
+}
+
+func TestTimeout_Shutdown(t *testing.T) {
+	app := New()
+	started := make(chan struct{})
+
+	Get(app, "/slow", func(c *ContextNoRequest) (string, error) {
+		close(started)
+
+		return waitForCancel(c)
+	})
+
+	addr := serveWebSocketApp(t, app)
+
+	go func() {
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_ = app.ShutdownWithContext(ctx)
+	}()
+
+	resp, err := http.Get("http://" + addr + "/slow")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestTimeout_ClientDisconnected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the disconnections are not detected on windows")
+	}
+
+	app := New(SetDisconnectDetection(true))
+	started := make(chan struct{})
+	cause := make(chan error, 1)
+
+	Get(app, "/slow", func(c *ContextNoRequest) (string, error) {
+		close(started)
+		<-c.Context().Done()
+		cause <- context.Cause(c.Context())
+
+		return "", c.Context().Err()
+	})
+
+	addr := serveWebSocketApp(t, app)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	_, err = conn.Write([]byte("GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+
+	<-started
+
+	// the connection is reset, a graceful close cannot be told from a half-close
+	require.NoError(t, conn.(*net.TCPConn).SetLinger(0))
+	require.NoError(t, conn.Close())
+
+	select {
+	case err = <-cause:
+		assert.ErrorIs(t, err, errClientDisconnected)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the context of the handler was not canceled")
+	}
+}
+
+func TestTimeout_HalfClose(t *testing.T) {
+	app := New(SetDisconnectDetection(true))
+
+	Get(app, "/slow", func(c *ContextNoRequest) (string, error) {
+		select {
+		case <-c.Context().Done():
+			return "", c.Context().Err()
+		case <-time.After(50 * time.Millisecond):
+			return "done", nil
+		}
+	})
+
+	addr := serveWebSocketApp(t, app)
+
+	conn, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("GET /slow HTTP/1.1\r\nHost: localhost\r\n\r\n"))
+	require.NoError(t, err)
+
+	// the client is done sending, but still waits for the response
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "done", string(body))
+}
+
+func TestTimeout_Disabled(t *testing.T) {
+	app := New()
+
+	// without timeout nor disconnect detection, the handler gets the context of the request as is
+	Get(app, "/ctx", func(c *ContextNoRequest) (string, error) {
+		if c.Context() != app.lifecycle.requests {
+			return "", errors.New("the context of the request was wrapped")
+		}
+
+		return "done", nil
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodGet, "/ctx", nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestTimeout_KeepAlive(t *testing.T) {
+	app := New(SetDisconnectDetection(true))
+
+	Get(app, "/ping", func(c *ContextNoRequest) (string, error) {
+		return "pong", c.Context().Err()
+	})
+
+	addr := serveWebSocketApp(t, app)
+
+	// the connection is still read after the watch of the disconnection is stopped
+	client := &http.Client{Transport: &http.Transport{}}
+	t.Cleanup(client.CloseIdleConnections)
+	for range 3 {
+		resp, err := client.Get("http://" + addr + "/ping")
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
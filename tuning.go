@@ -0,0 +1,172 @@
+package lite
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetBodyLimit sets the maximum size in bytes of the request bodies, overridden by Route.BodyLimit.
+// A larger body is rejected with a 413 HTTPError. Default is 4 MB.
+func SetBodyLimit(limit int) Config {
+	return func(s *App) {
+		s.serverConfig.BodyLimit = limit
+	}
+}
+
+// SetStreamRequestBody streams the request bodies to the handlers instead of buffering them before the handlers run,
+// e.g. to read the uploads with MultipartStream. The bodies are still limited, while they are read.
+func SetStreamRequestBody(enabled bool) Config {
+	return func(s *App) {
+		s.serverConfig.StreamRequestBody = enabled
+		s.serverConfig.DisablePreParseMultipartForm = enabled
+	}
+}
+
+// SetReadTimeout sets the maximum duration for reading a request, including its body. Default is no timeout.
+func SetReadTimeout(timeout time.Duration) Config {
+	return func(s *App) {
+		s.serverConfig.ReadTimeout = timeout
+	}
+}
+
+// SetWriteTimeout sets the maximum duration for writing a response. Default is no timeout.
+func SetWriteTimeout(timeout time.Duration) Config {
+	return func(s *App) {
+		s.serverConfig.WriteTimeout = timeout
+	}
+}
+
+// SetIdleTimeout sets the maximum duration a keep-alive connection waits for the next request.
+// Default is the read timeout.
+func SetIdleTimeout(timeout time.Duration) Config {
+	return func(s *App) {
+		s.serverConfig.IdleTimeout = timeout
+	}
+}
+
+// SetConcurrency sets the maximum number of concurrent connections. Default is 256 * 1024.
+func SetConcurrency(concurrency int) Config {
+	return func(s *App) {
+		s.serverConfig.Concurrency = concurrency
+	}
+}
+
+// SetMaxHeaderSize sets the size in bytes of the buffer reading the requests, which limits the size of their headers.
+// A request with larger headers is rejected with a 431 HTTPError. Default is 4096.
+func SetMaxHeaderSize(size int) Config {
+	return func(s *App) {
+		s.serverConfig.ReadBufferSize = size
+	}
+}
+
+// bodyLimit returns the maximum size of the request bodies of the app.
+func (s *App) bodyLimit() int {
+	if s.serverConfig.BodyLimit <= 0 {
+		return fiber.DefaultBodyLimit
+	}
+
+	return s.serverConfig.BodyLimit
+}
+
+// BodyLimit sets the maximum size in bytes of the request bodies of the route, e.g. a larger limit for an upload.
+// A larger body is rejected with a 413 HTTPError. The limit is documented in the x-body-limit extension of the operation.
+// A limit larger than the limit of the app requires SetStreamRequestBody(true), BodyLimit panics otherwise: the server
+// rejects the buffered bodies larger than the limit of the app before the route runs, while the streamed bodies are
+// read by the route up to its own limit, so the limit of the other routes is unchanged.
+func (r Route[ResponseBody, Request]) BodyLimit(limit int) Route[ResponseBody, Request] {
+	if limit > r.app.bodyLimit() && !r.app.serverConfig.StreamRequestBody {
+		panic(fmt.Sprintf("the body limit of %s %s exceeds the limit of the app, which requires SetStreamRequestBody(true)",
+			r.method, r.settings.path))
+	}
+
+	r.settings.bodyLimit = limit
+
+	if r.operation.Extensions == nil {
+		r.operation.Extensions = make(map[string]any)
+	}
+
+	r.operation.Extensions["x-body-limit"] = limit
+
+	return r.AddErrorResponse(StatusRequestEntityTooLarge)
+}
+
+// bodyLimitKey is the local holding the body limit of the route, read by the streamed request bodies.
+const bodyLimitKey = "lite.body.limit"
+
+// limitBody returns a 413 HTTPError if the body of the request is larger than the limit of the route, or than the
+// limit of the app. The streamed bodies are buffered up to the limit, except for the routes reading them as a stream,
+// which get the error from the body reader once the limit is exceeded.
+func (s *App) limitBody(c *fiber.Ctx, settings *routeSettings) error {
+	limit := settings.bodyLimit
+	if limit <= 0 {
+		limit = s.bodyLimit()
+	}
+
+	c.Locals(bodyLimitKey, limit)
+
+	if !c.Request().IsBodyStream() {
+		if len(c.Request().Body()) > limit {
+			return bodyTooLargeError(limit)
+		}
+
+		return nil
+	}
+
+	if c.Request().Header.ContentLength() > limit {
+		// the rest of the body is not read, so the connection cannot be reused
+		c.Context().SetConnectionClose()
+
+		return bodyTooLargeError(limit)
+	}
+
+	if settings.streamBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(requestBodyReader(c.Context()))
+	if err != nil {
+		c.Context().SetConnectionClose()
+
+		return err
+	}
+
+	c.Request().SetBody(body)
+
+	return nil
+}
+
+func bodyTooLargeError(limit int) HTTPError {
+	return newStatusError(StatusRequestEntityTooLarge, fmt.Sprintf("The request body exceeds the limit of %d bytes", limit))
+}
+
+// limitedReader reads up to limit bytes, then returns err once the reader exceeds the limit, e.g. the 413 HTTPError
+// of a streamed request body or of an uploaded file. It reads one byte past the limit at most, to tell a reader of
+// the maximum size from a larger one.
+type limitedReader struct {
+	reader io.Reader
+	limit  int64
+	read   int64
+	err    error
+}
+
+func (r *limitedReader) Read(b []byte) (int, error) {
+	if r.read > r.limit {
+		return 0, r.err
+	}
+
+	if remaining := r.limit - r.read + 1; int64(len(b)) > remaining {
+		b = b[:remaining]
+	}
+
+	n, err := r.reader.Read(b)
+	r.read += int64(n)
+
+	if r.read > r.limit {
+		return n - 1, r.err
+	}
+
+	return n, err
+}
@@ -0,0 +1,217 @@
+package lite
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoBody(c *ContextWithRequest[string]) (string, error) {
+	return c.Requests()
+}
+
+func postBody(t *testing.T, address, target string, size int) (*http.Response, map[string]any) {
+	t.Helper()
+
+	// the connection is closed by the server when it rejects the body, so it is not reused
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Post("http://"+address+target, "text/plain", strings.NewReader(strings.Repeat("a", size)))
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusBadRequest {
+		return resp, nil
+	}
+
+	var document map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&document))
+
+	return resp, document
+}
+
+func TestServerTuning(t *testing.T) {
+	app := New(
+		SetBodyLimit(1024),
+		SetReadTimeout(time.Second),
+		SetWriteTimeout(2*time.Second),
+		SetIdleTimeout(3*time.Second),
+		SetConcurrency(16),
+		SetMaxHeaderSize(8192),
+		SetPrefork(true),
+	)
+
+	config := app.app.Config()
+	assert.Equal(t, 1024, config.BodyLimit)
+	assert.Equal(t, time.Second, config.ReadTimeout)
+	assert.Equal(t, 2*time.Second, config.WriteTimeout)
+	assert.Equal(t, 3*time.Second, config.IdleTimeout)
+	assert.Equal(t, 16, config.Concurrency)
+	assert.Equal(t, 8192, config.ReadBufferSize)
+	assert.True(t, app.prefork)
+
+	assert.Equal(t, 1024, Group(app, "/v1").bodyLimit())
+	assert.Equal(t, fiber.DefaultBodyLimit, New().bodyLimit())
+}
+
+func TestBodyLimit(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true), SetBodyLimit(16), SetStreamRequestBody(true))
+
+	Post(app, "/notes", echoBody)
+	Post(app, "/uploads", echoBody).BodyLimit(64)
+
+	address, ran := runApp(t, app)
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown())
+		assert.NoError(t, <-ran)
+	})
+
+	resp, _ := postBody(t, address, "/notes", 16)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	// the larger limit of a route does not raise the limit of the other routes
+	resp, document := postBody(t, address, "/notes", 32)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "The request body exceeds the limit of 16 bytes", document["description"])
+
+	resp, _ = postBody(t, address, "/uploads", 64)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, document = postBody(t, address, "/uploads", 128)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "The request body exceeds the limit of 64 bytes", document["description"])
+
+	// the limit of the server is the limit of the app
+	assert.Equal(t, 16, app.app.Server().MaxRequestBodySize)
+}
+
+func TestBodyLimit_Buffered(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true), SetBodyLimit(16))
+
+	Post(app, "/notes", echoBody).BodyLimit(8)
+
+	assert.PanicsWithValue(t,
+		"the body limit of POST /uploads exceeds the limit of the app, which requires SetStreamRequestBody(true)",
+		func() {
+			Post(app, "/uploads", echoBody).BodyLimit(64)
+		})
+
+	address, ran := runApp(t, app)
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown())
+		assert.NoError(t, <-ran)
+	})
+
+	resp, document := postBody(t, address, "/notes", 12)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "The request body exceeds the limit of 8 bytes", document["description"])
+
+	// the server rejects the bodies larger than the limit of the app
+	resp, document = postBody(t, address, "/notes", 32)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "Request Entity Too Large", document["description"])
+	assert.Equal(t, "Error", document["@type"])
+}
+
+func TestBodyLimit_Streamed(t *testing.T) {
+	app := New(SetDisableLocalSave(true), SetDisableSwagger(true), SetBodyLimit(16), SetStreamRequestBody(true))
+
+	Post(app, "/notes", echoBody)
+
+	address, ran := runApp(t, app)
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown())
+		assert.NoError(t, <-ran)
+	})
+
+	resp, _ := postBody(t, address, "/notes", 16)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, document := postBody(t, address, "/notes", 32)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "The request body exceeds the limit of 16 bytes", document["description"])
+
+	// a chunked body has no length, it is rejected once it is read past the limit
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Post("http://"+address+"/notes", "text/plain", io.MultiReader(strings.NewReader(strings.Repeat("a", 32))))
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestLimitedReader(t *testing.T) {
+	newReader := func(data string) *limitedReader {
+		return &limitedReader{reader: strings.NewReader(data), limit: 4, err: bodyTooLargeError(4)}
+	}
+
+	data, err := io.ReadAll(newReader("abcd"))
+	require.NoError(t, err)
+	assert.Equal(t, "abcd", string(data))
+
+	data, err = io.ReadAll(newReader("abcdef"))
+	assert.Equal(t, "abcd", string(data))
+	assert.EqualError(t, err, "An error occurred [413]: The request body exceeds the limit of 4 bytes")
+
+	n, err := newReader("abcdef").Read(make([]byte, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestBodyLimit_OpenAPI(t *testing.T) {
+	app := New(SetStreamRequestBody(true))
+
+	Get(app, "/notes", func(c *ContextNoRequest) (string, error) {
+		return "", nil
+	})
+	Post(app, "/notes", echoBody)
+	Post(app, "/uploads", echoBody).BodyLimit(10 << 20)
+
+	operation := app.openAPISpec.Paths.Find("/notes").Get
+	assert.Nil(t, operation.Responses.Value("413"))
+
+	operation = app.openAPISpec.Paths.Find("/notes").Post
+	assert.NotContains(t, operation.Extensions, "x-body-limit")
+	assert.Equal(t, "Request Entity Too Large", *operation.Responses.Value("413").Value.Description)
+
+	operation = app.openAPISpec.Paths.Find("/uploads").Post
+	assert.Equal(t, 10<<20, operation.Extensions["x-body-limit"])
+	assert.NotNil(t, operation.Responses.Value("413"))
+}
+
+func TestMaxHeaderSize(t *testing.T) {
+	app := newListenerApp(SetMaxHeaderSize(1024))
+
+	address, ran := runApp(t, app)
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown())
+		assert.NoError(t, <-ran)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+address+"/ping", nil)
+	require.NoError(t, err)
+	req.Header.Set("X-Large", strings.Repeat("a", 2048))
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusRequestHeaderFieldsTooLarge, resp.StatusCode)
+
+	var document map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&document))
+	assert.Equal(t, "Error", document["@type"])
+}
@@ -0,0 +1,581 @@
+package lite
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// TusVersion is the version of the tus resumable upload protocol implemented by Tus.
+	TusVersion = "1.0.0"
+
+	tusExtensions = "creation,creation-with-upload,termination,expiration"
+)
+
+type tusConfig struct {
+	maxSize       int64
+	expiration    time.Duration
+	purgeInterval time.Duration
+}
+
+// TusConfig configures the endpoints mounted by Tus.
+type TusConfig func(*tusConfig)
+
+// SetTusMaxSize sets the maximum size in bytes of an upload. There is no limit by default.
+func SetTusMaxSize(size int64) TusConfig {
+	return func(c *tusConfig) {
+		c.maxSize = size
+	}
+}
+
+// SetTusExpiration sets the duration after which an unfinished upload expires. Default is 24 hours.
+// A zero duration disables the expiration.
+func SetTusExpiration(expiration time.Duration) TusConfig {
+	return func(c *tusConfig) {
+		c.expiration = expiration
+	}
+}
+
+// SetTusPurgeInterval sets the interval at which the expired uploads are removed from the store, when the store
+// implements PurgeExpired like TusFileStore. Default is 1 hour. The uploads are purged from the start hooks of Run
+// to its shutdown hooks: a zero interval disables the purge, and the app served by ServeHTTP is not purged,
+// PurgeExpired must then be scheduled by the caller.
+func SetTusPurgeInterval(interval time.Duration) TusConfig {
+	return func(c *tusConfig) {
+		c.purgeInterval = interval
+	}
+}
+
+// tusPurger is implemented by the stores removing their expired uploads, e.g. TusFileStore.
+type tusPurger interface {
+	PurgeExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// TusUpload is a completed resumable upload, with its metadata decoded into Metadata.
+type TusUpload[Metadata any] struct {
+	Info     TusInfo
+	Metadata Metadata
+
+	store TusStore
+}
+
+// Open returns the content of the upload.
+func (u TusUpload[Metadata]) Open(ctx context.Context) (io.ReadCloser, error) {
+	return u.store.Open(ctx, u.Info.ID)
+}
+
+type tus[Metadata any] struct {
+	app        *App
+	path       string
+	store      TusStore
+	config     tusConfig
+	onComplete func(c *ContextNoRequest, upload TusUpload[Metadata]) error
+
+	purgeMu   sync.Mutex
+	stopPurge func() // stops the purge started by the start hook, nil when it is not running
+}
+
+type tusCreateRequest struct {
+	TusResumable   string  `lite:"header=Tus-Resumable"`
+	UploadLength   int64   `lite:"header=Upload-Length"`
+	UploadMetadata *string `lite:"header=Upload-Metadata"`
+}
+
+type tusUploadRequest struct {
+	ID           string `lite:"params=id"`
+	TusResumable string `lite:"header=Tus-Resumable"`
+}
+
+type tusPatchRequest struct {
+	ID           string `lite:"params=id"`
+	TusResumable string `lite:"header=Tus-Resumable"`
+	UploadOffset int64  `lite:"header=Upload-Offset"`
+	Body         []byte `lite:"req=body,application/offset+octet-stream"`
+}
+
+// Tus mounts the endpoints of the tus 1.0 resumable upload protocol on path, with the creation,
+// creation-with-upload, termination and expiration extensions.
+// Uploads are created with a POST on path and resumed with HEAD and PATCH requests on path/:id.
+// The Upload-Metadata of an upload is decoded into Metadata, whose fields are named with the tus tag,
+// and validated when the upload is created. onComplete is called when the last chunk has been received,
+// and an error it returns is sent to the client.
+func Tus[Metadata any](
+	app *App,
+	path string,
+	store TusStore,
+	onComplete func(c *ContextNoRequest, upload TusUpload[Metadata]) error,
+	configs ...TusConfig,
+) {
+	path = strings.TrimRight(path, "/")
+
+	t := &tus[Metadata]{
+		app:   app,
+		path:  app.basePath + path,
+		store: store,
+		config: tusConfig{
+			expiration:    24 * time.Hour,
+			purgeInterval: time.Hour,
+		},
+		onComplete: onComplete,
+	}
+
+	for _, config := range configs {
+		config(&t.config)
+	}
+
+	t.register(http.MethodOptions, path, t.options, struct{}{}, StatusNoContent, HeaderTusVersion, HeaderTusExtension, HeaderTusMaxSize)
+	t.register(http.MethodPost, path, t.create, tusCreateRequest{}, StatusCreated, HeaderLocation, HeaderUploadExpires)
+	t.register(http.MethodHead, path+"/:id", t.head, tusUploadRequest{}, StatusOK, HeaderUploadOffset, HeaderUploadLength, HeaderUploadMetadata, HeaderUploadExpires)
+	t.register(http.MethodPatch, path+"/:id", t.patch, tusPatchRequest{}, StatusNoContent, HeaderUploadOffset, HeaderUploadExpires)
+	t.register(http.MethodDelete, path+"/:id", t.terminate, tusUploadRequest{}, StatusNoContent)
+
+	if purger, ok := store.(tusPurger); ok && t.config.expiration > 0 && t.config.purgeInterval > 0 {
+		app.OnStart(func(ctx context.Context) error {
+			t.startPurge(ctx, purger)
+
+			return nil
+		})
+		app.OnShutdown(func(context.Context) error {
+			t.endPurge()
+
+			return nil
+		})
+	}
+}
+
+// startPurge purges the expired uploads of the store now and at every purge interval, until endPurge is called.
+func (t *tus[Metadata]) startPurge(ctx context.Context, purger tusPurger) {
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	done := make(chan struct{})
+
+	t.purgeMu.Lock()
+	t.stopPurge = func() {
+		cancel()
+		<-done
+	}
+	t.purgeMu.Unlock()
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(t.config.purgeInterval)
+		defer ticker.Stop()
+
+		now := time.Now()
+
+		for {
+			if _, err := purger.PurgeExpired(ctx, now); err != nil && ctx.Err() == nil {
+				t.app.logger.ErrorContext(ctx, "failed to purge the expired uploads", slog.String("path", t.path), slog.Any("error", err))
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case now = <-ticker.C:
+			}
+		}
+	}()
+}
+
+// endPurge stops the purge and waits for the purge in progress, if any.
+func (t *tus[Metadata]) endPurge() {
+	t.purgeMu.Lock()
+	stop := t.stopPurge
+	t.stopPurge = nil
+	t.purgeMu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+func (t *tus[Metadata]) options(c *fiber.Ctx) error {
+	c.Set(HeaderTusVersion, TusVersion)
+	c.Set(HeaderTusExtension, tusExtensions)
+
+	if t.config.maxSize > 0 {
+		c.Set(HeaderTusMaxSize, strconv.FormatInt(t.config.maxSize, 10))
+	}
+
+	c.Status(StatusNoContent)
+
+	return nil
+}
+
+func (t *tus[Metadata]) create(c *fiber.Ctx) error {
+	if c.Get(HeaderUploadDeferLength) != "" {
+		return t.error(c, NewBadRequestError("Upload-Defer-Length is not supported"))
+	}
+
+	size, err := strconv.ParseInt(c.Get(HeaderUploadLength), 10, 64)
+	if err != nil || size < 0 {
+		return t.error(c, NewBadRequestError("Upload-Length should be a positive integer"))
+	}
+
+	if t.config.maxSize > 0 && size > t.config.maxSize {
+		return t.error(c, NewError(StatusRequestEntityTooLarge,
+			fmt.Sprintf("Upload-Length should be at most %d bytes", t.config.maxSize)))
+	}
+
+	metadata, err := parseTusMetadata(c.Get(HeaderUploadMetadata))
+	if err != nil {
+		return t.error(c, NewBadRequestError(err.Error()))
+	}
+
+	if _, err = t.decodeMetadata(metadata); err != nil {
+		return t.error(c, err)
+	}
+
+	info := TusInfo{
+		ID:       strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Size:     size,
+		Metadata: metadata,
+	}
+
+	if t.config.expiration > 0 {
+		info.ExpiresAt = time.Now().Add(t.config.expiration).UTC()
+	}
+
+	if err = t.store.Create(c.UserContext(), info); err != nil {
+		return t.error(c, err)
+	}
+
+	c.Set(HeaderLocation, c.BaseURL()+t.path+"/"+info.ID)
+	t.setExpires(c, info)
+
+	// creation-with-upload: the request may contain the first chunk of the upload.
+	if strings.HasPrefix(c.Get(HeaderContentType), string(ContentTypeOffsetOctetStream)) {
+		info.Offset, err = t.store.Write(c.UserContext(), info.ID, 0, requestBodyReader(c.Context()))
+		if err != nil {
+			return t.error(c, err)
+		}
+
+		c.Set(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	}
+
+	if info.Complete() {
+		if err = t.complete(c, info); err != nil {
+			return t.error(c, err)
+		}
+	}
+
+	c.Status(StatusCreated)
+
+	return nil
+}
+
+func (t *tus[Metadata]) head(c *fiber.Ctx) error {
+	info, err := t.info(c)
+	if err != nil {
+		return t.error(c, err)
+	}
+
+	c.Set(HeaderCacheControl, "no-store")
+	c.Set(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	c.Set(HeaderUploadLength, strconv.FormatInt(info.Size, 10))
+
+	if len(info.Metadata) > 0 {
+		c.Set(HeaderUploadMetadata, formatTusMetadata(info.Metadata))
+	}
+
+	t.setExpires(c, info)
+
+	c.Status(StatusOK)
+
+	return nil
+}
+
+func (t *tus[Metadata]) patch(c *fiber.Ctx) error {
+	if !strings.HasPrefix(c.Get(HeaderContentType), string(ContentTypeOffsetOctetStream)) {
+		return t.error(c, NewError(StatusUnsupportedMediaType,
+			"Content-Type should be "+string(ContentTypeOffsetOctetStream)))
+	}
+
+	offset, err := strconv.ParseInt(c.Get(HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return t.error(c, NewBadRequestError("Upload-Offset should be a positive integer"))
+	}
+
+	info, err := t.info(c)
+	if err != nil {
+		return t.error(c, err)
+	}
+
+	if info.Complete() {
+		return t.error(c, NewConflictError("The upload is already complete"))
+	}
+
+	info.Offset, err = t.store.Write(c.UserContext(), info.ID, offset, requestBodyReader(c.Context()))
+	if err != nil {
+		return t.error(c, err)
+	}
+
+	c.Set(HeaderUploadOffset, strconv.FormatInt(info.Offset, 10))
+	t.setExpires(c, info)
+
+	if info.Complete() {
+		if err = t.complete(c, info); err != nil {
+			return t.error(c, err)
+		}
+	}
+
+	c.Status(StatusNoContent)
+
+	return nil
+}
+
+func (t *tus[Metadata]) terminate(c *fiber.Ctx) error {
+	if err := t.store.Terminate(c.UserContext(), c.Params("id")); err != nil {
+		return t.error(c, err)
+	}
+
+	c.Status(StatusNoContent)
+
+	return nil
+}
+
+// info returns the upload of the request, terminating it if it has expired.
+func (t *tus[Metadata]) info(c *fiber.Ctx) (TusInfo, error) {
+	info, err := t.store.Info(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return info, err
+	}
+
+	if info.Expired(time.Now()) {
+		if err = t.store.Terminate(c.UserContext(), info.ID); err != nil && !errors.Is(err, ErrTusUploadNotFound) {
+			return info, err
+		}
+
+		return info, NewError(StatusGone, "The upload has expired")
+	}
+
+	return info, nil
+}
+
+func (t *tus[Metadata]) complete(c *fiber.Ctx, info TusInfo) error {
+	metadata, err := t.decodeMetadata(info.Metadata)
+	if err != nil {
+		return err
+	}
+
+	ctx := &ContextNoRequest{ctx: c, path: t.path, app: t.app}
+
+	return t.onComplete(ctx, TusUpload[Metadata]{
+		Info:     info,
+		Metadata: metadata,
+		store:    t.store,
+	})
+}
+
+// decodeMetadata decodes the metadata of an upload into the string fields of Metadata and validates it.
+func (t *tus[Metadata]) decodeMetadata(metadata map[string]string) (Metadata, error) {
+	var dst Metadata
+
+	dstVal := reflect.ValueOf(&dst).Elem()
+	if dstVal.Kind() != reflect.Struct {
+		return dst, nil
+	}
+
+	dstType := dstVal.Type()
+
+	for i := 0; i < dstType.NumField(); i++ {
+		field := dstType.Field(i)
+
+		key := field.Tag.Get("tus")
+		if key == "" {
+			key = field.Name
+		}
+
+		value, ok := metadata[key]
+		if !ok || !field.IsExported() || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		dstVal.Field(i).SetString(value)
+	}
+
+	if err := t.app.validate(dst); err != nil {
+		return dst, err
+	}
+
+	return dst, nil
+}
+
+func (t *tus[Metadata]) setExpires(c *fiber.Ctx, info TusInfo) {
+	if !info.ExpiresAt.IsZero() {
+		c.Set(HeaderUploadExpires, info.ExpiresAt.Format(http.TimeFormat))
+	}
+}
+
+// error writes the error response of a tus request.
+func (t *tus[Metadata]) error(c *fiber.Ctx, err error) error {
+	httpError, ok := t.app.resolveError(err)
+
+	switch {
+	case ok:
+	case errors.Is(err, ErrTusUploadNotFound):
+		httpError = NewNotFoundError("The upload does not exist")
+	case errors.Is(err, ErrTusOffsetMismatch):
+		httpError = NewConflictError("Upload-Offset does not match the offset of the upload")
+	default:
+		t.app.logger.ErrorContext(c.UserContext(), "tus error", slog.Any("path", t.path), slog.Any("error", err))
+
+		httpError = NewInternalServerError()
+	}
+
+	return t.app.renderError(c, httpError)
+}
+
+// register mounts a tus endpoint and documents it in the OpenAPI spec.
+// Every request except OPTIONS must send the Tus-Resumable header.
+func (t *tus[Metadata]) register(
+	method, path string,
+	handler fiber.Handler,
+	request any,
+	statusCode int,
+	headers ...string,
+) {
+	fullPath := t.app.basePath + path
+
+	t.app.app.Add(method, fullPath, func(c *fiber.Ctx) error {
+		t.app.logger.InfoContext(c.Context(), "tus request made", slog.Any("path", fullPath))
+
+		c.Set(HeaderTusResumable, TusVersion)
+		c.Locals(routePathKey, fullPath)
+		c.Locals(bodyLimitKey, t.app.bodyLimit())
+
+		if method != http.MethodOptions && c.Get(HeaderTusResumable) != TusVersion {
+			c.Set(HeaderTusVersion, TusVersion)
+
+			return t.error(c, NewError(StatusPreconditionFailed, "Tus-Resumable should be "+TusVersion))
+		}
+
+		return handler(c)
+	})
+
+	operation := openapi3.NewOperation()
+
+	if err := register(t.app, operation, reflect.ValueOf(request)); err != nil {
+		slog.ErrorContext(context.Background(), "failed to register tus operation", slog.Any("error", err))
+		panic(err)
+	}
+
+	response := openapi3.NewResponse().WithDescription(StatusMessage(statusCode))
+	response.Headers = openapi3.Headers{
+		HeaderTusResumable: tusHeader(),
+	}
+
+	for _, header := range headers {
+		response.Headers[header] = tusHeader()
+	}
+
+	operation.AddResponse(statusCode, response)
+
+	errorStatuses := []int{StatusPreconditionFailed}
+
+	switch method {
+	case http.MethodPost:
+		errorStatuses = append(errorStatuses, StatusRequestEntityTooLarge)
+	case http.MethodHead, http.MethodDelete:
+		errorStatuses = append(errorStatuses, StatusNotFound, StatusGone)
+	case http.MethodPatch:
+		errorStatuses = append(errorStatuses, StatusNotFound, StatusConflict, StatusGone, StatusUnsupportedMediaType)
+	}
+
+	for _, status := range errorStatuses {
+		operation.AddResponse(status, t.app.errorResponse(status, "application/json"))
+	}
+
+	responses, _ := t.app.createDefaultErrorResponses("application/json")
+
+	for code, resp := range responses {
+		operation.AddResponse(code, resp)
+	}
+
+	operation.Responses.Delete("default")
+
+	if t.app.tag != "" {
+		operation.Tags = append(operation.Tags, t.app.tag)
+		operation.Description = setDescription(method, t.app.tag)
+	}
+
+	operation.Extensions = map[string]any{
+		"x-tus-version":    TusVersion,
+		"x-tus-extensions": strings.Split(tusExtensions, ","),
+	}
+
+	routePath, _ := parseRoutePath(fullPath)
+	t.app.openAPISpec.AddOperation(routePath, method, operation)
+	t.app.specChanged()
+}
+
+func tusHeader() *openapi3.HeaderRef {
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Schema: openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+			},
+		},
+	}
+}
+
+// parseTusMetadata parses an Upload-Metadata header: comma separated keys and base64 encoded values.
+func parseTusMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		key, encoded, _ := strings.Cut(pair, " ")
+
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("Upload-Metadata value of %s should be base64 encoded", key)
+		}
+
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
+
+// formatTusMetadata formats the metadata of an upload as an Upload-Metadata header.
+func formatTusMetadata(metadata map[string]string) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+
+	for _, key := range keys {
+		pair := key
+		if metadata[key] != "" {
+			pair += " " + base64.StdEncoding.EncodeToString([]byte(metadata[key]))
+		}
+
+		pairs = append(pairs, pair)
+	}
+
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,279 @@
+package lite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrTusUploadNotFound is returned by a TusStore when the upload does not exist.
+	ErrTusUploadNotFound = errors.New("upload not found")
+	// ErrTusOffsetMismatch is returned by a TusStore when a chunk is not written at the current offset of the upload.
+	ErrTusOffsetMismatch = errors.New("upload offset mismatch")
+)
+
+// TusInfo describes a resumable upload.
+type TusInfo struct {
+	ID        string            `json:"id"`
+	Size      int64             `json:"size"`
+	Offset    int64             `json:"offset"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	ExpiresAt time.Time         `json:"expiresAt,omitempty"`
+}
+
+// Complete reports whether all the bytes of the upload have been received.
+func (i TusInfo) Complete() bool {
+	return i.Offset >= i.Size
+}
+
+// Expired reports whether the upload has expired at the given time.
+func (i TusInfo) Expired(now time.Time) bool {
+	return !i.ExpiresAt.IsZero() && now.After(i.ExpiresAt)
+}
+
+// TusStore is the storage backend of resumable uploads.
+type TusStore interface {
+	// Create creates a new upload.
+	Create(ctx context.Context, info TusInfo) error
+	// Info returns the upload, or ErrTusUploadNotFound.
+	Info(ctx context.Context, id string) (TusInfo, error)
+	// Write appends the content of src to the upload, which must be at the given offset,
+	// and returns the new offset of the upload.
+	Write(ctx context.Context, id string, offset int64, src io.Reader) (int64, error)
+	// Open returns the content of the upload.
+	Open(ctx context.Context, id string) (io.ReadCloser, error)
+	// Terminate deletes the upload and its content.
+	Terminate(ctx context.Context, id string) error
+}
+
+var tusIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// TusFileStore stores the resumable uploads in a directory of the filesystem.
+// Each upload is stored in an <id>.bin file, and its info in an <id>.info file.
+type TusFileStore struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+var _ TusStore = &TusFileStore{}
+
+// NewTusFileStore creates a TusFileStore storing the uploads in dir, created if it does not exist.
+func NewTusFileStore(dir string) (*TusFileStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+
+	return &TusFileStore{
+		dir:   dir,
+		locks: make(map[string]*sync.Mutex),
+	}, nil
+}
+
+func (s *TusFileStore) Create(_ context.Context, info TusInfo) error {
+	if !tusIDPattern.MatchString(info.ID) {
+		return errors.New("invalid upload id " + info.ID)
+	}
+
+	unlock := s.lock(info.ID)
+	defer unlock()
+
+	f, err := os.OpenFile(s.binPath(info.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	return s.writeInfo(info)
+}
+
+func (s *TusFileStore) Info(_ context.Context, id string) (TusInfo, error) {
+	if !tusIDPattern.MatchString(id) {
+		return TusInfo{}, ErrTusUploadNotFound
+	}
+
+	unlock := s.lock(id)
+	defer unlock()
+
+	return s.readInfo(id)
+}
+
+func (s *TusFileStore) Write(_ context.Context, id string, offset int64, src io.Reader) (int64, error) {
+	if !tusIDPattern.MatchString(id) {
+		return 0, ErrTusUploadNotFound
+	}
+
+	unlock := s.lock(id)
+	defer unlock()
+
+	info, err := s.readInfo(id)
+	if err != nil {
+		return 0, err
+	}
+
+	if info.Offset != offset {
+		return info.Offset, ErrTusOffsetMismatch
+	}
+
+	f, err := os.OpenFile(s.binPath(id), os.O_WRONLY, 0o640)
+	if err != nil {
+		return info.Offset, err
+	}
+
+	defer f.Close()
+
+	if _, err = f.Seek(offset, io.SeekStart); err != nil {
+		return info.Offset, err
+	}
+
+	// The bytes written before a failure are kept, so the client can resume from the new offset.
+	written, copyErr := io.Copy(f, io.LimitReader(src, info.Size-offset))
+
+	info.Offset += written
+
+	if err = s.writeInfo(info); err != nil {
+		return offset, err
+	}
+
+	return info.Offset, copyErr
+}
+
+func (s *TusFileStore) Open(_ context.Context, id string) (io.ReadCloser, error) {
+	if !tusIDPattern.MatchString(id) {
+		return nil, ErrTusUploadNotFound
+	}
+
+	f, err := os.Open(s.binPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTusUploadNotFound
+	}
+
+	return f, err
+}
+
+func (s *TusFileStore) Terminate(_ context.Context, id string) error {
+	if !tusIDPattern.MatchString(id) {
+		return ErrTusUploadNotFound
+	}
+
+	unlock := s.lock(id)
+	defer unlock()
+
+	err := os.Remove(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrTusUploadNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(s.binPath(id))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// PurgeExpired terminates the uploads expired at the given time and returns how many were removed.
+// Tus calls it periodically while the app runs, see SetTusPurgeInterval.
+func (s *TusFileStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, entry := range entries {
+		id, ok := strings.CutSuffix(entry.Name(), ".info")
+		if !ok {
+			continue
+		}
+
+		info, err := s.Info(ctx, id)
+		if err != nil || !info.Expired(now) {
+			continue
+		}
+
+		if err = s.Terminate(ctx, id); err != nil {
+			return purged, err
+		}
+
+		purged++
+	}
+
+	return purged, nil
+}
+
+// lock serializes the operations on an upload.
+func (s *TusFileStore) lock(id string) func() {
+	s.mu.Lock()
+
+	l, ok := s.locks[id]
+	if !ok {
+		l = &sync.Mutex{}
+		s.locks[id] = l
+	}
+
+	s.mu.Unlock()
+
+	l.Lock()
+
+	return l.Unlock
+}
+
+func (s *TusFileStore) readInfo(id string) (TusInfo, error) {
+	data, err := os.ReadFile(s.infoPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return TusInfo{}, ErrTusUploadNotFound
+	}
+
+	if err != nil {
+		return TusInfo{}, err
+	}
+
+	var info TusInfo
+
+	if err = json.Unmarshal(data, &info); err != nil {
+		return TusInfo{}, err
+	}
+
+	return info, nil
+}
+
+func (s *TusFileStore) writeInfo(info TusInfo) error {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated info file.
+	tmp := s.infoPath(info.ID) + ".tmp"
+
+	if err = os.WriteFile(tmp, data, 0o640); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, s.infoPath(info.ID))
+}
+
+func (s *TusFileStore) binPath(id string) string {
+	return filepath.Join(s.dir, id+".bin")
+}
+
+func (s *TusFileStore) infoPath(id string) string {
+	return filepath.Join(s.dir, id+".info")
+}
@@ -0,0 +1,83 @@
+package lite
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTusFileStore(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewTusFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(ctx, TusInfo{ID: "upload", Size: 10, Metadata: map[string]string{"a": "b"}}))
+	assert.Error(t, store.Create(ctx, TusInfo{ID: "upload", Size: 10}))
+	assert.Error(t, store.Create(ctx, TusInfo{ID: "../escape", Size: 10}))
+
+	offset, err := store.Write(ctx, "upload", 0, strings.NewReader("hello"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), offset)
+
+	_, err = store.Write(ctx, "upload", 0, strings.NewReader("hello"))
+	assert.ErrorIs(t, err, ErrTusOffsetMismatch)
+
+	// The content beyond the size of the upload is ignored.
+	offset, err = store.Write(ctx, "upload", 5, strings.NewReader("world and more"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), offset)
+
+	info, err := store.Info(ctx, "upload")
+	require.NoError(t, err)
+	assert.True(t, info.Complete())
+	assert.Equal(t, map[string]string{"a": "b"}, info.Metadata)
+
+	f, err := store.Open(ctx, "upload")
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	assert.Equal(t, "helloworld", string(content))
+
+	require.NoError(t, store.Terminate(ctx, "upload"))
+
+	_, err = store.Info(ctx, "upload")
+	assert.ErrorIs(t, err, ErrTusUploadNotFound)
+
+	_, err = store.Open(ctx, "upload")
+	assert.ErrorIs(t, err, ErrTusUploadNotFound)
+
+	assert.ErrorIs(t, store.Terminate(ctx, "upload"), ErrTusUploadNotFound)
+}
+
+func TestTusFileStore_PurgeExpired(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	store, err := NewTusFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(ctx, TusInfo{ID: "expired", Size: 1, ExpiresAt: now.Add(-time.Minute)}))
+	require.NoError(t, store.Create(ctx, TusInfo{ID: "active", Size: 1, ExpiresAt: now.Add(time.Minute)}))
+	require.NoError(t, store.Create(ctx, TusInfo{ID: "forever", Size: 1}))
+
+	purged, err := store.PurgeExpired(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
+
+	_, err = store.Info(ctx, "expired")
+	assert.ErrorIs(t, err, ErrTusUploadNotFound)
+
+	_, err = store.Info(ctx, "active")
+	assert.NoError(t, err)
+
+	_, err = store.Info(ctx, "forever")
+	assert.NoError(t, err)
+}
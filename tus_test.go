@@ -0,0 +1,365 @@
+package lite
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type videoMetadata struct {
+	Filename string `tus:"filename" validate:"required"`
+	Kind     string `tus:"filetype"`
+}
+
+type tusResult struct {
+	upload  TusUpload[videoMetadata]
+	content string
+}
+
+func newTusApp(t *testing.T, configs ...TusConfig) (*App, chan tusResult) {
+	t.Helper()
+
+	store, err := NewTusFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	completed := make(chan tusResult, 1)
+
+	app := New()
+	files := Group(app, "/files")
+
+	Tus(files, "/", store, func(c *ContextNoRequest, upload TusUpload[videoMetadata]) error {
+		f, err := upload.Open(c.Context())
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		content, err := io.ReadAll(f)
+		if err != nil {
+			return err
+		}
+
+		completed <- tusResult{upload: upload, content: string(content)}
+
+		return nil
+	}, configs...)
+
+	return app, completed
+}
+
+func tusRequest(method, target string, body io.Reader, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, target, body)
+	req.Header.Set(HeaderTusResumable, TusVersion)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	return req
+}
+
+func tusMetadata(filename string) string {
+	return "filename " + base64.StdEncoding.EncodeToString([]byte(filename)) + ",filetype " +
+		base64.StdEncoding.EncodeToString([]byte("video/mp4"))
+}
+
+func createTusUpload(t *testing.T, app *App, size int) string {
+	t.Helper()
+
+	resp, err := app.app.Test(tusRequest(http.MethodPost, "/files", nil, map[string]string{
+		HeaderUploadLength:   strconv.Itoa(size),
+		HeaderUploadMetadata: tusMetadata("movie.mp4"),
+	}))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	location := resp.Header.Get(HeaderLocation)
+	require.Contains(t, location, "/files/")
+
+	return location[strings.Index(location, "/files/"):]
+}
+
+func TestTus_Options(t *testing.T) {
+	app, _ := newTusApp(t, SetTusMaxSize(1<<30))
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodOptions, "/files", nil))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, TusVersion, resp.Header.Get(HeaderTusResumable))
+	assert.Equal(t, TusVersion, resp.Header.Get(HeaderTusVersion))
+	assert.Equal(t, tusExtensions, resp.Header.Get(HeaderTusExtension))
+	assert.Equal(t, strconv.Itoa(1<<30), resp.Header.Get(HeaderTusMaxSize))
+}
+
+func TestTus_Resume(t *testing.T) {
+	app, completed := newTusApp(t)
+
+	location := createTusUpload(t, app, 11)
+
+	resp, err := app.app.Test(tusRequest(http.MethodPatch, location, strings.NewReader("hello "), map[string]string{
+		HeaderContentType:  string(ContentTypeOffsetOctetStream),
+		HeaderUploadOffset: "0",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "6", resp.Header.Get(HeaderUploadOffset))
+	assert.NotEmpty(t, resp.Header.Get(HeaderUploadExpires))
+
+	resp, err = app.app.Test(tusRequest(http.MethodHead, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "6", resp.Header.Get(HeaderUploadOffset))
+	assert.Equal(t, "11", resp.Header.Get(HeaderUploadLength))
+	assert.Equal(t, "no-store", resp.Header.Get(HeaderCacheControl))
+	assert.Equal(t, tusMetadata("movie.mp4"), resp.Header.Get(HeaderUploadMetadata))
+
+	// A chunk sent at a stale offset is rejected.
+	resp, err = app.app.Test(tusRequest(http.MethodPatch, location, strings.NewReader("world"), map[string]string{
+		HeaderContentType:  string(ContentTypeOffsetOctetStream),
+		HeaderUploadOffset: "0",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = app.app.Test(tusRequest(http.MethodPatch, location, strings.NewReader("world"), map[string]string{
+		HeaderContentType:  string(ContentTypeOffsetOctetStream),
+		HeaderUploadOffset: "6",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, "11", resp.Header.Get(HeaderUploadOffset))
+
+	select {
+	case result := <-completed:
+		assert.Equal(t, "hello world", result.content)
+		assert.Equal(t, "movie.mp4", result.upload.Metadata.Filename)
+		assert.Equal(t, "video/mp4", result.upload.Metadata.Kind)
+		assert.Equal(t, int64(11), result.upload.Info.Size)
+	default:
+		t.Fatal("the completion handler was not called")
+	}
+}
+
+func TestTus_CreationWithUpload(t *testing.T) {
+	app, completed := newTusApp(t)
+
+	resp, err := app.app.Test(tusRequest(http.MethodPost, "/files", bytes.NewBufferString("data"), map[string]string{
+		HeaderContentType:    string(ContentTypeOffsetOctetStream),
+		HeaderUploadLength:   "4",
+		HeaderUploadMetadata: tusMetadata("clip.mp4"),
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "4", resp.Header.Get(HeaderUploadOffset))
+
+	result := <-completed
+	assert.Equal(t, "data", result.content)
+	assert.Equal(t, "clip.mp4", result.upload.Metadata.Filename)
+}
+
+func TestTus_Errors(t *testing.T) {
+	app, _ := newTusApp(t, SetTusMaxSize(10))
+
+	location := createTusUpload(t, app, 4)
+
+	tests := []struct {
+		name   string
+		req    *http.Request
+		status int
+	}{
+		{
+			name:   "missing Tus-Resumable",
+			req:    httptest.NewRequest(http.MethodHead, location, nil),
+			status: http.StatusPreconditionFailed,
+		},
+		{
+			name:   "missing Upload-Length",
+			req:    tusRequest(http.MethodPost, "/files", nil, map[string]string{HeaderUploadMetadata: tusMetadata("a")}),
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "too large",
+			req: tusRequest(http.MethodPost, "/files", nil, map[string]string{
+				HeaderUploadLength:   "11",
+				HeaderUploadMetadata: tusMetadata("a"),
+			}),
+			status: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "invalid metadata",
+			req:    tusRequest(http.MethodPost, "/files", nil, map[string]string{HeaderUploadLength: "1"}),
+			status: http.StatusBadRequest,
+		},
+		{
+			name: "metadata not base64",
+			req: tusRequest(http.MethodPost, "/files", nil, map[string]string{
+				HeaderUploadLength:   "1",
+				HeaderUploadMetadata: "filename !!!",
+			}),
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "unknown upload",
+			req:    tusRequest(http.MethodHead, "/files/unknown", nil, nil),
+			status: http.StatusNotFound,
+		},
+		{
+			name: "wrong content type",
+			req: tusRequest(http.MethodPatch, location, strings.NewReader("a"), map[string]string{
+				HeaderContentType:  string(ContentTypeOctetStream),
+				HeaderUploadOffset: "0",
+			}),
+			status: http.StatusUnsupportedMediaType,
+		},
+		{
+			name: "missing Upload-Offset",
+			req: tusRequest(http.MethodPatch, location, strings.NewReader("a"), map[string]string{
+				HeaderContentType: string(ContentTypeOffsetOctetStream),
+			}),
+			status: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.app.Test(tt.req)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			assert.Equal(t, TusVersion, resp.Header.Get(HeaderTusResumable))
+		})
+	}
+}
+
+func TestTus_Terminate(t *testing.T) {
+	app, _ := newTusApp(t)
+
+	location := createTusUpload(t, app, 4)
+
+	resp, err := app.app.Test(tusRequest(http.MethodDelete, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	resp, err = app.app.Test(tusRequest(http.MethodHead, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = app.app.Test(tusRequest(http.MethodDelete, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestTus_Expiration(t *testing.T) {
+	app, _ := newTusApp(t, SetTusExpiration(time.Nanosecond))
+
+	location := createTusUpload(t, app, 4)
+
+	time.Sleep(time.Millisecond)
+
+	resp, err := app.app.Test(tusRequest(http.MethodHead, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusGone, resp.StatusCode)
+
+	resp, err = app.app.Test(tusRequest(http.MethodHead, location, nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestTus_Purge(t *testing.T) {
+	ctx := context.Background()
+
+	store, err := NewTusFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	app := New()
+
+	Tus(app, "/files", store, func(_ *ContextNoRequest, _ TusUpload[videoMetadata]) error {
+		return nil
+	}, SetTusExpiration(time.Nanosecond), SetTusPurgeInterval(10*time.Millisecond))
+
+	require.NoError(t, app.start(ctx))
+
+	location := createTusUpload(t, app, 4)
+	id := location[strings.LastIndex(location, "/")+1:]
+
+	// the expired upload is removed without being requested
+	assert.Eventually(t, func() bool {
+		_, err := store.Info(ctx, id)
+
+		return errors.Is(err, ErrTusUploadNotFound)
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, app.runShutdownHooks(ctx))
+
+	// the purge is not scheduled without interval
+	app, _ = newTusApp(t, SetTusPurgeInterval(0))
+	assert.Empty(t, app.lifecycle.hooks(false))
+}
+
+func TestTus_OpenAPI(t *testing.T) {
+	app, _ := newTusApp(t)
+
+	collection := app.openAPISpec.Paths.Find("/files")
+	require.NotNil(t, collection)
+	require.NotNil(t, collection.Options)
+	require.NotNil(t, collection.Post)
+	assert.Equal(t, []string{"Files"}, collection.Post.Tags)
+	assert.Contains(t, tusParameters(collection.Post), HeaderUploadLength)
+	assert.NotNil(t, collection.Post.Responses.Value("201").Value.Headers[HeaderLocation])
+
+	upload := app.openAPISpec.Paths.Find("/files/{id}")
+	require.NotNil(t, upload)
+	require.NotNil(t, upload.Head)
+	require.NotNil(t, upload.Delete)
+	require.NotNil(t, upload.Patch)
+	assert.Contains(t, tusParameters(upload.Patch), "id")
+	assert.Contains(t, tusParameters(upload.Patch), HeaderUploadOffset)
+	assert.NotNil(t, upload.Patch.RequestBody.Value.Content.Get(string(ContentTypeOffsetOctetStream)))
+	assert.NotNil(t, upload.Patch.Responses.Value("409"))
+	assert.NotNil(t, upload.Patch.Responses.Value("204").Value.Headers[HeaderUploadOffset])
+	assert.Equal(t, TusVersion, upload.Patch.Extensions["x-tus-version"])
+}
+
+func tusParameters(operation *openapi3.Operation) []string {
+	var names []string
+
+	for _, parameter := range operation.Parameters {
+		if parameter.Value != nil {
+			names = append(names, parameter.Value.Name)
+		} else {
+			names = append(names, strings.TrimPrefix(parameter.Ref, "#/components/parameters/"))
+		}
+	}
+
+	return names
+}
+
+func TestParseTusMetadata(t *testing.T) {
+	metadata, err := parseTusMetadata("filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential")
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"filename":        "world_domination_plan.pdf",
+		"is_confidential": "",
+	}, metadata)
+
+	assert.Equal(t, "filename d29ybGRfZG9taW5hdGlvbl9wbGFuLnBkZg==,is_confidential", formatTusMetadata(metadata))
+
+	_, err = parseTusMetadata("filename %%%")
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package lite
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// TypedError is an error whose body is serialized as is instead of an HTTPError, with the status code of the error.
+// Document it on the route with AddTypedErrorResponse.
+type TypedError[T any] struct {
+	Status int
+	Body   T
+}
+
+// NewTypedError returns an error serialized as body with the status code.
+func NewTypedError[T any](status int, body T) TypedError[T] {
+	return TypedError[T]{
+		Status: status,
+		Body:   body,
+	}
+}
+
+func (e TypedError[T]) Error() string {
+	return fmt.Sprintf("%s [%d]: %+v", http.StatusText(e.StatusCode()), e.StatusCode(), e.Body)
+}
+
+func (e TypedError[T]) StatusCode() int {
+	if e.Status == 0 {
+		return StatusInternalServerError
+	}
+
+	return e.Status
+}
+
+func (e TypedError[T]) errorBody() any {
+	return e.Body
+}
+
+// typedError is implemented by TypedError whatever the type of its body.
+type typedError interface {
+	Error
+	errorBody() any
+}
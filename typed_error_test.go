@@ -0,0 +1,105 @@
+package lite
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedError(t *testing.T) {
+	app := New()
+
+	Post(app, "/orders", func(c *ContextNoRequest) (string, error) {
+		return "", fmt.Errorf("create order: %w", NewTypedError(StatusConflict, conflictDetails{
+			Resource:   "order",
+			ExistingID: 42,
+		}))
+	})
+
+	resp, err := app.app.Test(httptest.NewRequest(http.MethodPost, "/orders", nil))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+	assert.Equal(t, string(ContentTypeJSON), resp.Header.Get(HeaderContentType))
+	assert.JSONEq(t, `{"resource":"order","existingId":42}`, string(body))
+}
+
+func TestTypedError_Negotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		app         *App
+		accept      string
+		contentType string
+		body        string
+	}{
+		{
+			name:        "xml",
+			app:         New(),
+			accept:      "application/xml",
+			contentType: "application/xml",
+			body:        "<conflictDetails><Resource>order</Resource><ExistingID>42</ExistingID></conflictDetails>",
+		},
+		{
+			name:        "problem format",
+			app:         New(SetErrorFormat(ErrorFormatProblem)),
+			contentType: "application/json",
+			body:        `{"resource":"order","existingId":42}`,
+		},
+		{
+			name:        "problem+json accepted",
+			app:         New(),
+			accept:      "application/problem+json",
+			contentType: "application/json",
+			body:        `{"resource":"order","existingId":42}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Post(tt.app, "/orders", func(c *ContextNoRequest) (string, error) {
+				return "", NewTypedError(StatusConflict, conflictDetails{Resource: "order", ExistingID: 42})
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/orders", nil)
+			req.Header.Set(HeaderAccept, tt.accept)
+
+			resp, err := tt.app.app.Test(req)
+			require.NoError(t, err)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, http.StatusConflict, resp.StatusCode)
+			assert.Equal(t, tt.contentType, resp.Header.Get(HeaderContentType))
+			assert.Contains(t, string(body), tt.body)
+		})
+	}
+}
+
+func TestTypedErrorContentTypes(t *testing.T) {
+	assert.Equal(t, []ContentType{ContentTypeJSON}, typedErrorContentTypes("application/json"))
+	assert.Equal(t, []ContentType{ContentTypeJSON}, typedErrorContentTypes("text/plain"))
+	assert.Equal(t, []ContentType{ContentTypeJSON, ContentTypeXML}, typedErrorContentTypes("application/xml"))
+	assert.Equal(t, []ContentType{ContentTypeJSON, ContentTypeYAML}, typedErrorContentTypes("application/yaml"))
+}
+
+func TestTypedError_Error(t *testing.T) {
+	err := NewTypedError(StatusUnprocessableEntity, unprocessableDetails{Field: "quantity", Reason: "negative"})
+
+	assert.Equal(t, StatusUnprocessableEntity, err.StatusCode())
+	assert.Equal(t, "Unprocessable Entity [422]: {Field:quantity Reason:negative Hints:[]}", err.Error())
+	assert.Equal(t, StatusInternalServerError, TypedError[string]{}.StatusCode())
+
+	data, jsonErr := json.Marshal(err.errorBody())
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{"field":"quantity","reason":"negative"}`, string(data))
+}
@@ -0,0 +1,412 @@
+package lite
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/valyala/fasthttp"
+)
+
+// sniffLen is the number of bytes read to detect the media type of an uploaded file.
+const sniffLen = 3072
+
+// fileConstraint holds the upload constraints declared with the file tag,
+// e.g. `file:"maxsize=5MB,types=image/png|image/jpeg,maxcount=3"`.
+type fileConstraint struct {
+	maxSize  int64
+	types    []string
+	maxCount int
+}
+
+var fileConstraintsCache sync.Map
+
+// fileConstraints returns the upload constraints of the fields of t, by form field name.
+func fileConstraints(t reflect.Type) map[string]fileConstraint {
+	if cached, ok := fileConstraintsCache.Load(t); ok {
+		return cached.(map[string]fileConstraint)
+	}
+
+	constraints := make(map[string]fileConstraint)
+
+	if t.Kind() == reflect.Struct {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+
+			tag := field.Tag.Get("file")
+			if tag == "" {
+				continue
+			}
+
+			key := field.Tag.Get("form")
+			if key == "" {
+				key = field.Name
+			}
+
+			constraint, err := parseFileConstraint(tag)
+			if err != nil {
+				panic(fmt.Sprintf("invalid file tag on field %s: %v", field.Name, err))
+			}
+
+			constraints[key] = constraint
+		}
+	}
+
+	fileConstraintsCache.Store(t, constraints)
+
+	return constraints
+}
+
+func parseFileConstraint(tag string) (fileConstraint, error) {
+	var constraint fileConstraint
+
+	for key, value := range parseTag(tag) {
+		switch key {
+		case "maxsize":
+			size, err := parseByteSize(value)
+			if err != nil {
+				return constraint, err
+			}
+
+			constraint.maxSize = size
+		case "types":
+			constraint.types = strings.Split(value, "|")
+		case "maxcount":
+			count, err := strconv.Atoi(value)
+			if err != nil {
+				return constraint, err
+			}
+
+			constraint.maxCount = count
+		default:
+			return constraint, errors.New("unknown constraint " + key)
+		}
+	}
+
+	return constraint, nil
+}
+
+// parseByteSize parses a size such as 512, 10KB, 5MB or 1GB, with 1024-based units.
+func parseByteSize(value string) (int64, error) {
+	units := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"B", 1},
+	}
+
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+
+	for _, unit := range units {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.multiplier
+
+			break
+		}
+	}
+
+	size, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	return size * multiplier, nil
+}
+
+// allows reports whether the detected media type is one of the allowed types.
+// Allowed types may use a wildcard subtype, e.g. image/*.
+func (c fileConstraint) allows(detected *mimetype.MIME) bool {
+	if len(c.types) == 0 {
+		return true
+	}
+
+	for _, allowed := range c.types {
+		allowed = strings.TrimSpace(allowed)
+
+		if strings.HasSuffix(allowed, "/*") {
+			if strings.HasPrefix(detected.String(), strings.TrimSuffix(allowed, "*")) {
+				return true
+			}
+
+			continue
+		}
+
+		for m := detected; m != nil; m = m.Parent() {
+			if m.Is(allowed) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func newUploadError(status int, propertyPath, message string) HTTPError {
+	return HTTPError{
+		Context:     "/api/contexts/ConstraintViolationList",
+		Type:        "ConstraintViolation",
+		Status:      status,
+		Title:       "A constraint violation occurred",
+		Description: message,
+		Violations: []Violation{
+			{
+				PropertyPath: propertyPath,
+				Message:      message,
+				Code:         uuid.NewString(),
+			},
+		},
+	}
+}
+
+func tooManyFilesError(key string, constraint fileConstraint) HTTPError {
+	return newUploadError(
+		StatusRequestEntityTooLarge,
+		key,
+		fmt.Sprintf("%s should contain at most %d files", key, constraint.maxCount),
+	)
+}
+
+func fileTooLargeError(key string, constraint fileConstraint) HTTPError {
+	return newUploadError(
+		StatusRequestEntityTooLarge,
+		key,
+		fmt.Sprintf("%s should be at most %d bytes", key, constraint.maxSize),
+	)
+}
+
+func unsupportedFileTypeError(key string, detected string, constraint fileConstraint) HTTPError {
+	return newUploadError(
+		StatusUnsupportedMediaType,
+		key,
+		fmt.Sprintf("%s should be one of %s, got %s", key, strings.Join(constraint.types, ", "), detected),
+	)
+}
+
+// scanMultipartFiles checks the files of a multipart body against the constraints of dst while reading its parts,
+// before the form is parsed: the parts are not copied, and the scan stops at the first violation,
+// e.g. at the file past the maximum count or at the byte past the maximum size. The malformed bodies are left
+// to the parsing of the form.
+func scanMultipartFiles(body []byte, boundary string, dst any) error {
+	constraints := fileConstraints(reflect.TypeOf(dst).Elem())
+	if len(constraints) == 0 || boundary == "" {
+		return nil
+	}
+
+	stream := &MultipartStream[struct{}]{
+		reader:      multipart.NewReader(bytes.NewReader(body), boundary),
+		constraints: constraints,
+		counts:      make(map[string]int),
+	}
+
+	for {
+		if _, err := stream.NextPart(); err != nil {
+			var httpError HTTPError
+			if errors.As(err, &httpError) {
+				return err
+			}
+
+			return nil
+		}
+	}
+}
+
+// multipartStreamBinder is implemented by MultipartStream to bind the request body stream.
+type multipartStreamBinder interface {
+	bindMultipart(ctx *fasthttp.RequestCtx) error
+	streamType() reflect.Type
+}
+
+// MultipartStream streams the parts of a multipart/form-data body to the handler,
+// without buffering the files in memory or in temporary files.
+// The form fields and their upload constraints are declared by the fields of T.
+// It requires SetStreamRequestBody(true), registering a route reading it panics otherwise.
+type MultipartStream[T any] struct {
+	reader      *multipart.Reader
+	constraints map[string]fileConstraint
+	counts      map[string]int
+	current     *Part
+}
+
+var _ multipartStreamBinder = &MultipartStream[struct{}]{}
+
+func (s *MultipartStream[T]) bindMultipart(ctx *fasthttp.RequestCtx) error {
+	boundary := string(ctx.Request.Header.MultipartFormBoundary())
+	if boundary == "" {
+		return NewErrorResponse(
+			StatusBadRequest,
+			"/api/contexts/DeserializationError",
+			"Bad request",
+			"DeserializationError",
+			"Missing multipart boundary",
+			nil,
+		)
+	}
+
+	s.reader = multipart.NewReader(requestBodyReader(ctx), boundary)
+	s.constraints = fileConstraints(s.streamType())
+	s.counts = make(map[string]int)
+
+	return nil
+}
+
+func (s *MultipartStream[T]) streamType() reflect.Type {
+	return reflect.TypeOf(*new(T))
+}
+
+// streamsBody reports whether the request type t reads its body as a stream, with a MultipartStream.
+func streamsBody(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.Type.Kind() == reflect.Struct && field.Tag.Get("lite") == "" {
+			if streamsBody(field.Type) {
+				return true
+			}
+
+			continue
+		}
+
+		if _, ok := reflect.New(field.Type).Interface().(multipartStreamBinder); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NextPart returns the next part of the body, or io.EOF when all the parts have been read.
+// The previous part is drained and can no longer be read.
+// Constraint violations are returned as HTTPError with a 413 or 415 status.
+func (s *MultipartStream[T]) NextPart() (*Part, error) {
+	if s.reader == nil {
+		return nil, io.EOF
+	}
+
+	if s.current != nil {
+		if _, err := io.Copy(io.Discard, s.current); err != nil {
+			return nil, err
+		}
+	}
+
+	part, err := s.reader.NextPart()
+	if err != nil {
+		return nil, err
+	}
+
+	key := part.FormName()
+	constraint, constrained := s.constraints[key]
+
+	if constrained && part.FileName() != "" {
+		s.counts[key]++
+
+		if constraint.maxCount > 0 && s.counts[key] > constraint.maxCount {
+			return nil, tooManyFilesError(key, constraint)
+		}
+	}
+
+	p := &Part{
+		FieldName:   key,
+		FileName:    part.FileName(),
+		ContentType: part.Header.Get(HeaderContentType),
+		reader:      part,
+	}
+
+	if constrained && len(constraint.types) > 0 {
+		buffered := bufio.NewReaderSize(part, sniffLen)
+
+		peeked, err := buffered.Peek(sniffLen)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, err
+		}
+
+		detected := mimetype.Detect(peeked)
+		if !constraint.allows(detected) {
+			return nil, unsupportedFileTypeError(key, detected.String(), constraint)
+		}
+
+		p.ContentType = detected.String()
+		p.reader = buffered
+	}
+
+	if constrained && constraint.maxSize > 0 {
+		p.reader = &limitedReader{reader: p.reader, limit: constraint.maxSize, err: fileTooLargeError(key, constraint)}
+	}
+
+	s.current = p
+
+	return p, nil
+}
+
+// Part is a part of a multipart body streamed by MultipartStream.
+type Part struct {
+	FieldName   string // Name of the form field
+	FileName    string // Name of the uploaded file, empty for a regular form field
+	ContentType string // Media type of the part, detected from its content when types are constrained
+
+	reader io.Reader
+}
+
+// Read reads the content of the part.
+// It returns an HTTPError with a 413 status once the part exceeds its maximum size, without the bytes past it.
+func (p *Part) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// setFileConstraintsSchema documents the upload constraints of the fields of t on the request body.
+func setFileConstraintsSchema(s *App, operation *openapi3.Operation, t reflect.Type, contentType string) {
+	constraints := fileConstraints(t)
+	if len(constraints) == 0 || operation.RequestBody == nil || operation.RequestBody.Value == nil {
+		return
+	}
+
+	mediaType := operation.RequestBody.Value.Content.Get(contentType)
+	if mediaType == nil {
+		return
+	}
+
+	for key, constraint := range constraints {
+		encoding := openapi3.NewEncoding()
+		encoding.Extensions = make(map[string]any)
+
+		if len(constraint.types) > 0 {
+			encoding.ContentType = strings.Join(constraint.types, ", ")
+		}
+
+		if constraint.maxSize > 0 {
+			encoding.Extensions["x-max-size"] = constraint.maxSize
+		}
+
+		if constraint.maxCount > 0 {
+			encoding.Extensions["x-max-count"] = constraint.maxCount
+		}
+
+		if mediaType.Encoding == nil {
+			mediaType.Encoding = make(map[string]*openapi3.Encoding)
+		}
+
+		mediaType.Encoding[key] = encoding
+	}
+
+	for _, status := range []int{StatusRequestEntityTooLarge, StatusUnsupportedMediaType} {
+		operation.AddResponse(status, s.errorResponse(status, "application/json"))
+	}
+}
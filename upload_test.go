@@ -0,0 +1,411 @@
+package lite
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+type avatarForm struct {
+	Name   string                  `form:"name"`
+	Avatar []*multipart.FileHeader `form:"avatar" file:"maxsize=1KB,types=image/png|image/jpeg,maxcount=1"`
+}
+
+type avatarRequest struct {
+	Body avatarForm `lite:"req=body,multipart/form-data"`
+}
+
+type avatarStreamRequest struct {
+	Body MultipartStream[avatarForm] `lite:"req=body,multipart/form-data"`
+}
+
+type uploadResponse struct {
+	Files map[string]int `json:"files"`
+}
+
+type multipartFile struct {
+	field string
+	name  string
+	data  []byte
+}
+
+func newMultipartRequest(t *testing.T, path string, files ...multipartFile) *http.Request {
+	t.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	require.NoError(t, writer.WriteField("name", "bob"))
+
+	for _, file := range files {
+		part, err := writer.CreateFormFile(file.field, file.name)
+		require.NoError(t, err)
+
+		_, err = part.Write(file.data)
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Close())
+
+	req := httptest.NewRequest(http.MethodPost, path, body)
+	req.Header.Set(HeaderContentType, writer.FormDataContentType())
+
+	return req
+}
+
+func newUploadApp(config ...Config) *App {
+	app := New(append([]Config{SetStreamRequestBody(true)}, config...)...)
+
+	Post(app, "/avatars", func(c *ContextWithRequest[avatarRequest]) (uploadResponse, error) {
+		req, err := c.Requests()
+		if err != nil {
+			return uploadResponse{}, err
+		}
+
+		files := make(map[string]int)
+		for _, f := range req.Body.Avatar {
+			files[f.Filename] = int(f.Size)
+		}
+
+		return uploadResponse{Files: files}, nil
+	})
+
+	Post(app, "/avatars/stream", func(c *ContextWithRequest[avatarStreamRequest]) (uploadResponse, error) {
+		req, err := c.Requests()
+		if err != nil {
+			return uploadResponse{}, err
+		}
+
+		files := make(map[string]int)
+
+		for {
+			part, err := req.Body.NextPart()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				return uploadResponse{}, err
+			}
+
+			n, err := io.Copy(io.Discard, part)
+			if err != nil {
+				return uploadResponse{}, err
+			}
+
+			if part.FileName != "" {
+				files[part.FileName] = int(n)
+			}
+		}
+
+		return uploadResponse{Files: files}, nil
+	})
+
+	return app
+}
+
+func TestUpload_Constraints(t *testing.T) {
+	app := newUploadApp()
+
+	tests := []struct {
+		name   string
+		files  []multipartFile
+		status int
+		files2 map[string]int
+	}{
+		{
+			name:   "valid file",
+			files:  []multipartFile{{"avatar", "a.png", pngHeader}},
+			status: http.StatusCreated,
+			files2: map[string]int{"a.png": len(pngHeader)},
+		},
+		{
+			name:   "too large",
+			files:  []multipartFile{{"avatar", "a.png", append(pngHeader, make([]byte, 2048)...)}},
+			status: StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "unsupported type",
+			files:  []multipartFile{{"avatar", "a.png", []byte("%PDF-1.4 not an image")}},
+			status: StatusUnsupportedMediaType,
+		},
+		{
+			name:   "too many files",
+			files:  []multipartFile{{"avatar", "a.png", pngHeader}, {"avatar", "b.png", pngHeader}},
+			status: StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, path := range []string{"/avatars", "/avatars/stream"} {
+		for _, tt := range tests {
+			t.Run(path+" "+tt.name, func(t *testing.T) {
+				resp, err := app.app.Test(newMultipartRequest(t, path, tt.files...))
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.status, resp.StatusCode)
+
+				body, _ := io.ReadAll(resp.Body)
+
+				if tt.status != http.StatusCreated {
+					var httpError HTTPError
+					require.NoError(t, json.Unmarshal(body, &httpError))
+					require.Len(t, httpError.Violations, 1)
+					assert.Equal(t, "avatar", httpError.Violations[0].PropertyPath)
+
+					return
+				}
+
+				var response uploadResponse
+				require.NoError(t, json.Unmarshal(body, &response))
+				assert.Equal(t, tt.files2, response.Files)
+			})
+		}
+	}
+}
+
+func TestUpload_OpenAPI(t *testing.T) {
+	app := newUploadApp()
+
+	for _, path := range []string{"/avatars", "/avatars/stream"} {
+		operation := app.openAPISpec.Paths.Find(path).Post
+		require.NotNil(t, operation)
+
+		mediaType := operation.RequestBody.Value.Content.Get("multipart/form-data")
+		require.NotNil(t, mediaType)
+		assert.Equal(t, "#/components/schemas/avatarForm", mediaType.Schema.Ref)
+
+		encoding := mediaType.Encoding["avatar"]
+		require.NotNil(t, encoding)
+		assert.Equal(t, "image/png, image/jpeg", encoding.ContentType)
+		assert.Equal(t, int64(1024), encoding.Extensions["x-max-size"])
+		assert.Equal(t, 1, encoding.Extensions["x-max-count"])
+
+		assert.NotNil(t, operation.Responses.Value(strconv.Itoa(StatusRequestEntityTooLarge)))
+		assert.NotNil(t, operation.Responses.Value(strconv.Itoa(StatusUnsupportedMediaType)))
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := map[string]int64{
+		"512":   512,
+		"10B":   10,
+		"10KB":  10 << 10,
+		"5mb":   5 << 20,
+		"1 GB":  1 << 30,
+		"2 KB ": 2 << 10,
+	}
+
+	for value, expected := range tests {
+		size, err := parseByteSize(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, size, value)
+	}
+
+	_, err := parseByteSize("big")
+	assert.Error(t, err)
+}
+
+func TestParseFileConstraint(t *testing.T) {
+	constraint, err := parseFileConstraint("maxsize=2MB,types=image/*|application/pdf,maxcount=4")
+	require.NoError(t, err)
+
+	assert.Equal(t, fileConstraint{maxSize: 2 << 20, types: []string{"image/*", "application/pdf"}, maxCount: 4}, constraint)
+
+	_, err = parseFileConstraint("maxcount=many")
+	assert.Error(t, err)
+
+	_, err = parseFileConstraint("unknown=1")
+	assert.Error(t, err)
+
+	_, err = parseFileConstraint("maxsize=huge")
+	assert.Error(t, err)
+}
+
+func TestFileConstraint_Allows(t *testing.T) {
+	png := mimetype.Detect(pngHeader)
+	json := mimetype.Detect([]byte(`{"a":1}`))
+
+	assert.True(t, fileConstraint{}.allows(png))
+	assert.True(t, fileConstraint{types: []string{"image/*"}}.allows(png))
+	assert.False(t, fileConstraint{types: []string{"video/*"}}.allows(png))
+	assert.True(t, fileConstraint{types: []string{"text/plain"}}.allows(json))
+	assert.False(t, fileConstraint{types: []string{"image/png"}}.allows(json))
+}
+
+func TestMultipartStream_Unbound(t *testing.T) {
+	var stream MultipartStream[avatarForm]
+
+	_, err := stream.NextPart()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestMultipartStream_MissingBoundary(t *testing.T) {
+	app := newUploadApp()
+
+	req := httptest.NewRequest(http.MethodPost, "/avatars/stream", bytes.NewBufferString("data"))
+	req.Header.Set(HeaderContentType, "multipart/form-data")
+
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestMultipartStream_RequiresStreaming(t *testing.T) {
+	app := New()
+
+	assert.PanicsWithValue(t, "the request body of POST /avatars/stream is streamed, which requires SetStreamRequestBody(true)", func() {
+		Post(app, "/avatars/stream", func(c *ContextWithRequest[avatarStreamRequest]) (uploadResponse, error) {
+			return uploadResponse{}, nil
+		})
+	})
+}
+
+func TestMultipartStream_RejectedWhileRead(t *testing.T) {
+	app := newUploadApp(SetDisableLocalSave(true), SetDisableSwagger(true))
+
+	address, ran := runApp(t, app)
+	t.Cleanup(func() {
+		assert.NoError(t, app.Shutdown())
+		assert.NoError(t, <-ran)
+	})
+
+	conn, err := net.Dial("tcp", address)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.NoError(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	// the body announces 1 MB but only its first 16 KB are sent, the response cannot wait for the rest
+	const boundary = "boundary"
+
+	_, err = conn.Write([]byte("POST /avatars/stream HTTP/1.1\r\nHost: " + address + "\r\n" +
+		"Content-Type: multipart/form-data; boundary=" + boundary + "\r\n" +
+		"Content-Length: 1048576\r\n\r\n" +
+		"--" + boundary + "\r\n" +
+		"Content-Disposition: form-data; name=\"avatar\"; filename=\"a.png\"\r\n" +
+		"Content-Type: image/png\r\n\r\n"))
+	require.NoError(t, err)
+
+	_, err = conn.Write(append(pngHeader, make([]byte, 16<<10)...))
+	require.NoError(t, err)
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	var httpError HTTPError
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&httpError))
+
+	assert.Equal(t, StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Equal(t, "avatar should be at most 1024 bytes", httpError.Description)
+}
+
+// newMultipartStream returns a stream of the parts of the files, as bound from a request body.
+func newMultipartStream(t *testing.T, files ...multipartFile) *MultipartStream[avatarForm] {
+	t.Helper()
+
+	req := newMultipartRequest(t, "/avatars", files...)
+
+	_, params, err := mime.ParseMediaType(req.Header.Get(HeaderContentType))
+	require.NoError(t, err)
+
+	return &MultipartStream[avatarForm]{
+		reader:      multipart.NewReader(req.Body, params["boundary"]),
+		constraints: fileConstraints(reflect.TypeOf(avatarForm{})),
+		counts:      make(map[string]int),
+	}
+}
+
+func TestPart_Read(t *testing.T) {
+	stream := newMultipartStream(t, multipartFile{"avatar", "a.png", append(pngHeader, make([]byte, 2048)...)})
+
+	part, err := stream.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "name", part.FieldName)
+
+	part, err = stream.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "image/png", part.ContentType)
+
+	// the part is read up to its maximum size
+	data, err := io.ReadAll(part)
+	assert.Len(t, data, 1024)
+
+	var httpError HTTPError
+	require.ErrorAs(t, err, &httpError)
+	assert.Equal(t, StatusRequestEntityTooLarge, httpError.Status)
+}
+
+func TestScanMultipartFiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  []multipartFile
+		status int
+	}{
+		{
+			name:  "valid file",
+			files: []multipartFile{{"avatar", "a.png", pngHeader}},
+		},
+		{
+			name:   "too large",
+			files:  []multipartFile{{"avatar", "a.png", append(pngHeader, make([]byte, 2048)...)}},
+			status: StatusRequestEntityTooLarge,
+		},
+		{
+			name:   "unsupported type",
+			files:  []multipartFile{{"avatar", "a.pdf", []byte("%PDF-1.4")}},
+			status: StatusUnsupportedMediaType,
+		},
+		{
+			name:   "too many files",
+			files:  []multipartFile{{"avatar", "a.png", pngHeader}, {"avatar", "b.png", pngHeader}},
+			status: StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newMultipartRequest(t, "/avatars", tt.files...)
+
+			body, err := io.ReadAll(req.Body)
+			require.NoError(t, err)
+
+			_, params, err := mime.ParseMediaType(req.Header.Get(HeaderContentType))
+			require.NoError(t, err)
+
+			err = scanMultipartFiles(body, params["boundary"], &avatarForm{})
+			if tt.status == 0 {
+				assert.NoError(t, err)
+
+				return
+			}
+
+			var httpError HTTPError
+			require.ErrorAs(t, err, &httpError)
+			assert.Equal(t, tt.status, httpError.Status)
+		})
+	}
+
+	// the malformed bodies are left to the parsing of the form
+	assert.NoError(t, scanMultipartFiles([]byte("--boundary\r\nmalformed"), "boundary", &avatarForm{}))
+}
@@ -0,0 +1,414 @@
+package lite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ErrWebSocketClosed is returned when a message is sent on a closed WebSocket connection.
+var ErrWebSocketClosed = errors.New("websocket connection closed")
+
+type webSocketConfig struct {
+	pingInterval time.Duration // Interval between two pings sent to the client
+	pongWait     time.Duration // Time allowed to read the next pong (or message) from the client
+	writeWait    time.Duration // Time allowed to write a message to the client
+	readLimit    int64         // Maximum size in bytes of a message read from the client
+}
+
+var defaultWebSocketConfig = webSocketConfig{
+	pingInterval: 54 * time.Second,
+	pongWait:     60 * time.Second,
+	writeWait:    10 * time.Second,
+	readLimit:    1 << 20,
+}
+
+// SetWebSocketPingInterval sets the interval between two pings sent on WebSocket connections.
+// The client must answer with a pong before the next interval ends, otherwise the connection is closed.
+func SetWebSocketPingInterval(interval time.Duration) Config {
+	return func(s *App) {
+		s.webSocketConfig.pingInterval = interval
+		s.webSocketConfig.pongWait = interval + interval/9
+	}
+}
+
+// SetWebSocketReadLimit sets the maximum size in bytes of a message read from a WebSocket client.
+func SetWebSocketReadLimit(limit int64) Config {
+	return func(s *App) {
+		s.webSocketConfig.readLimit = limit
+	}
+}
+
+// WebSocketConn is a typed WebSocket connection.
+// Messages received from the client are decoded from JSON into In,
+// messages sent to the client are encoded from Out into JSON.
+type WebSocketConn[Request, In, Out any] struct {
+	conn    *websocket.Conn
+	ctx     context.Context
+	cancel  context.CancelFunc
+	config  webSocketConfig
+	request Request
+
+	in  chan In
+	out chan Out
+
+	mu  sync.Mutex
+	err error
+}
+
+// Context returns the context of the connection, cancelled when the connection is closed.
+func (w *WebSocketConn[Request, In, Out]) Context() context.Context {
+	return w.ctx
+}
+
+// Request returns the request decoded and validated before the upgrade.
+func (w *WebSocketConn[Request, In, Out]) Request() Request {
+	return w.request
+}
+
+// In returns the channel of messages received from the client.
+// The channel is closed when the connection is closed.
+func (w *WebSocketConn[Request, In, Out]) In() <-chan In {
+	return w.in
+}
+
+// Out returns the channel of messages sent to the client.
+func (w *WebSocketConn[Request, In, Out]) Out() chan<- Out {
+	return w.out
+}
+
+// Send sends a message to the client.
+// It returns ErrWebSocketClosed if the connection is closed before the message is written.
+func (w *WebSocketConn[Request, In, Out]) Send(message Out) error {
+	select {
+	case w.out <- message:
+		return nil
+	case <-w.ctx.Done():
+		return ErrWebSocketClosed
+	}
+}
+
+// Done returns a channel closed when the connection is closed.
+func (w *WebSocketConn[Request, In, Out]) Done() <-chan struct{} {
+	return w.ctx.Done()
+}
+
+// Err returns the error that closed the connection, if any.
+// A normal closure from the client is not an error.
+func (w *WebSocketConn[Request, In, Out]) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+// Close sends a close message with the given code and reason to the client and closes the connection.
+func (w *WebSocketConn[Request, In, Out]) Close(code int, reason string) error {
+	defer w.cancel()
+
+	message := websocket.FormatCloseMessage(code, reason)
+
+	err := w.conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(w.config.writeWait))
+	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		return err
+	}
+
+	return nil
+}
+
+func (w *WebSocketConn[Request, In, Out]) setErr(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *WebSocketConn[Request, In, Out]) readLoop() {
+	defer close(w.in)
+	defer w.cancel()
+
+	w.conn.SetReadLimit(w.config.readLimit)
+	_ = w.conn.SetReadDeadline(time.Now().Add(w.config.pongWait))
+	w.conn.SetPongHandler(func(string) error {
+		return w.conn.SetReadDeadline(time.Now().Add(w.config.pongWait))
+	})
+
+	for {
+		var message In
+
+		err := w.conn.ReadJSON(&message)
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) &&
+				w.ctx.Err() == nil {
+				w.setErr(err)
+			}
+
+			return
+		}
+
+		select {
+		case w.in <- message:
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+func (w *WebSocketConn[Request, In, Out]) writeLoop() {
+	ticker := time.NewTicker(w.config.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message := <-w.out:
+			_ = w.conn.SetWriteDeadline(time.Now().Add(w.config.writeWait))
+
+			if err := w.conn.WriteJSON(message); err != nil {
+				w.setErr(err)
+				w.cancel()
+
+				return
+			}
+		case <-ticker.C:
+			err := w.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(w.config.writeWait))
+			if err != nil {
+				w.setErr(err)
+				w.cancel()
+
+				return
+			}
+		case <-w.ctx.Done():
+			return
+		}
+	}
+}
+
+// WebSocket registers a WebSocket endpoint.
+// The request is decoded and validated from the path, query and header fields before the upgrade,
+// so an invalid request is rejected with a regular HTTP error response.
+func WebSocket[Request, In, Out any](
+	app *App,
+	path string,
+	handler func(conn *WebSocketConn[Request, In, Out]) error,
+	middleware ...fiber.Handler,
+) Route[Out, Request] {
+	route := registerRoute[Out, Request](
+		app,
+		Route[Out, Request]{
+			path:        path,
+			method:      http.MethodGet,
+			contentType: "application/json",
+			statusCode:  StatusSwitchingProtocols,
+		},
+		webSocketHandler[Request, In, Out](handler, app.basePath+path, app.logger, app),
+		middleware...,
+	)
+
+	err := registerWebSocketOperation[In](app, route.operation)
+	if err != nil {
+		slog.ErrorContext(context.Background(), "failed to register websocket operation", slog.Any("error", err))
+		panic(err)
+	}
+
+	app.specChanged()
+
+	return route
+}
+
+func webSocketHandler[Request, In, Out any](
+	handler func(conn *WebSocketConn[Request, In, Out]) error,
+	path string,
+	logger *slog.Logger,
+	app *App,
+) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		logger.InfoContext(c.Context(), "websocket request made", slog.Any("path", path))
+
+		if !websocket.FastHTTPIsWebSocketUpgrade(c.Context()) {
+			return app.renderError(c, NewError(StatusUpgradeRequired, "A WebSocket upgrade request is required"))
+		}
+
+		ctx := &ContextWithRequest[Request]{
+			ContextNoRequest: ContextNoRequest{ctx: c, path: path, app: app},
+		}
+
+		request, err := ctx.Requests()
+		if err != nil {
+			logger.ErrorContext(c.Context(), "error", slog.Any("error", err))
+
+			var httpError HTTPError
+			if errors.As(err, &httpError) {
+				return app.renderError(c, httpError)
+			}
+
+			return app.renderError(c, NewBadRequestError(err.Error()))
+		}
+
+		userContext := c.UserContext()
+		upgrader := websocket.FastHTTPUpgrader{}
+
+		err = upgrader.Upgrade(c.Context(), func(conn *websocket.Conn) {
+			serveWebSocket(userContext, conn, request, handler, logger, path, app.webSocketConfig)
+		})
+		if err != nil {
+			// The upgrader has already written the error response.
+			logger.ErrorContext(c.Context(), "websocket upgrade failed", slog.Any("error", err))
+		}
+
+		return nil
+	}
+}
+
+func serveWebSocket[Request, In, Out any](
+	parent context.Context,
+	conn *websocket.Conn,
+	request Request,
+	handler func(conn *WebSocketConn[Request, In, Out]) error,
+	logger *slog.Logger,
+	path string,
+	config webSocketConfig,
+) {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	ws := &WebSocketConn[Request, In, Out]{
+		conn:    conn,
+		ctx:     ctx,
+		cancel:  cancel,
+		config:  config,
+		request: request,
+		in:      make(chan In),
+		out:     make(chan Out),
+	}
+
+	var readers, writers sync.WaitGroup
+
+	readers.Add(1)
+	writers.Add(1)
+
+	go func() {
+		defer readers.Done()
+		ws.readLoop()
+	}()
+
+	go func() {
+		defer writers.Done()
+		ws.writeLoop()
+	}()
+
+	err := runWebSocketHandler(ws, handler)
+
+	cancel()
+	writers.Wait()
+
+	code, reason := websocket.CloseNormalClosure, ""
+
+	if err != nil {
+		logger.ErrorContext(parent, "websocket error", slog.Any("path", path), slog.Any("error", err))
+
+		code, reason = websocket.CloseInternalServerErr, "Internal server error"
+	}
+
+	_ = conn.WriteControl(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(code, reason),
+		time.Now().Add(config.writeWait),
+	)
+	_ = conn.Close()
+
+	readers.Wait()
+}
+
+func runWebSocketHandler[Request, In, Out any](
+	ws *WebSocketConn[Request, In, Out],
+	handler func(conn *WebSocketConn[Request, In, Out]) error,
+) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("websocket handler panic: %v", r)
+		}
+	}()
+
+	return handler(ws)
+}
+
+// registerWebSocketOperation replaces the generated response of a WebSocket operation with the upgrade response
+// and documents the messages exchanged on the connection in the x-websocket extension.
+func registerWebSocketOperation[In any](s *App, operation *openapi3.Operation) error {
+	status := strconv.Itoa(StatusSwitchingProtocols)
+
+	var sendRef string
+
+	if response := operation.Responses.Value(status); response != nil && response.Value != nil {
+		for _, mediaType := range response.Value.Content {
+			if mediaType.Schema != nil {
+				sendRef = mediaType.Schema.Ref
+			}
+		}
+	}
+
+	scratch := openapi3.NewOperation()
+
+	err := setResponseSchema(s, scratch, tagFromType(*new(In)), "application/json", StatusOK, reflect.TypeOf(*new(In)))
+	if err != nil {
+		return err
+	}
+
+	var receiveRef string
+
+	for _, mediaType := range scratch.Responses.Value(strconv.Itoa(StatusOK)).Value.Content {
+		if mediaType.Schema != nil {
+			receiveRef = mediaType.Schema.Ref
+		}
+	}
+
+	response := openapi3.NewResponse().WithDescription(StatusMessage(StatusSwitchingProtocols))
+	response.Headers = openapi3.Headers{
+		HeaderUpgrade:            webSocketHeader("websocket"),
+		HeaderConnection:         webSocketHeader("Upgrade"),
+		HeaderSecWebSocketAccept: webSocketHeader(""),
+	}
+
+	operation.Responses.Delete(status)
+	operation.AddResponse(StatusSwitchingProtocols, response)
+
+	operation.AddResponse(StatusUpgradeRequired, s.errorResponse(StatusUpgradeRequired, "application/json"))
+
+	if operation.Extensions == nil {
+		operation.Extensions = make(map[string]any)
+	}
+
+	operation.Extensions["x-websocket"] = map[string]any{
+		"send":    map[string]any{"$ref": sendRef},
+		"receive": map[string]any{"$ref": receiveRef},
+	}
+
+	return nil
+}
+
+func webSocketHeader(value string) *openapi3.HeaderRef {
+	schema := openapi3.NewStringSchema()
+	if value != "" {
+		schema.WithEnum(value)
+	}
+
+	return &openapi3.HeaderRef{
+		Value: &openapi3.Header{
+			Parameter: openapi3.Parameter{
+				Schema: openapi3.NewSchemaRef("", schema),
+			},
+		},
+	}
+}
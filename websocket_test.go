@@ -0,0 +1,166 @@
+package lite
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type chatRequest struct {
+	Room string `lite:"params=room" validate:"required,min=3"`
+	Name string `lite:"query=name"`
+}
+
+type chatIn struct {
+	Text string `json:"text"`
+}
+
+type chatOut struct {
+	Room string `json:"room"`
+	From string `json:"from"`
+	Text string `json:"text"`
+}
+
+func chatHandler(conn *WebSocketConn[chatRequest, chatIn, chatOut]) error {
+	req := conn.Request()
+
+	for message := range conn.In() {
+		err := conn.Send(chatOut{Room: req.Room, From: req.Name, Text: message.Text})
+		if err != nil {
+			return err
+		}
+	}
+
+	return conn.Err()
+}
+
+func serveWebSocketApp(t *testing.T, app *App) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	go func() {
+		_ = app.app.Listener(ln)
+	}()
+
+	t.Cleanup(func() {
+		_ = app.Shutdown()
+	})
+
+	return ln.Addr().String()
+}
+
+func TestWebSocket_Echo(t *testing.T) {
+	app := New()
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	addr := serveWebSocketApp(t, app)
+
+	conn, resp, err := websocket.DefaultDialer.Dial("ws://"+addr+"/chat/general?name=bob", nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(chatIn{Text: "hello"}))
+
+	var out chatOut
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	require.NoError(t, conn.ReadJSON(&out))
+
+	assert.Equal(t, chatOut{Room: "general", From: "bob", Text: "hello"}, out)
+
+	err = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	assert.NoError(t, err)
+}
+
+func TestWebSocket_ValidationErrorBeforeUpgrade(t *testing.T) {
+	app := New()
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	addr := serveWebSocketApp(t, app)
+
+	_, resp, err := websocket.DefaultDialer.Dial("ws://"+addr+"/chat/ab", nil)
+	assert.ErrorIs(t, err, websocket.ErrBadHandshake)
+	require.NotNil(t, resp)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWebSocket_HandlerErrorClosesConnection(t *testing.T) {
+	app := New()
+
+	WebSocket(app, "/fail", func(conn *WebSocketConn[struct{}, chatIn, chatOut]) error {
+		<-conn.In()
+
+		return assert.AnError
+	})
+
+	addr := serveWebSocketApp(t, app)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws://"+addr+"/fail", nil)
+	require.NoError(t, err)
+
+	defer conn.Close()
+
+	require.NoError(t, conn.WriteJSON(chatIn{Text: "boom"}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err = conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseInternalServerErr))
+}
+
+func TestWebSocket_UpgradeRequired(t *testing.T) {
+	app := New()
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	req := httptest.NewRequest(http.MethodGet, "/chat/general", nil)
+	resp, err := app.app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, StatusUpgradeRequired, resp.StatusCode)
+}
+
+func TestWebSocket_OpenAPI(t *testing.T) {
+	app := New()
+
+	WebSocket(app, "/chat/:room", chatHandler)
+
+	operation := app.openAPISpec.Paths.Find("/chat/{room}").Get
+	require.NotNil(t, operation)
+
+	response := operation.Responses.Value(strconv.Itoa(StatusSwitchingProtocols))
+	require.NotNil(t, response)
+	assert.Contains(t, response.Value.Headers, HeaderUpgrade)
+	assert.Contains(t, response.Value.Headers, HeaderSecWebSocketAccept)
+	assert.Nil(t, operation.Responses.Value(strconv.Itoa(StatusOK)))
+	assert.NotNil(t, operation.Responses.Value(strconv.Itoa(StatusUpgradeRequired)))
+
+	assert.Equal(t, map[string]any{
+		"send":    map[string]any{"$ref": "#/components/schemas/chatOut"},
+		"receive": map[string]any{"$ref": "#/components/schemas/chatIn"},
+	}, operation.Extensions["x-websocket"])
+	assert.Contains(t, app.openAPISpec.Components.Schemas, "chatIn")
+	assert.Contains(t, app.openAPISpec.Components.Schemas, "chatOut")
+}
+
+func TestSetWebSocketConfig(t *testing.T) {
+	app := New(
+		SetWebSocketPingInterval(9*time.Second),
+		SetWebSocketReadLimit(512),
+	)
+
+	assert.Equal(t, 9*time.Second, app.webSocketConfig.pingInterval)
+	assert.Equal(t, 10*time.Second, app.webSocketConfig.pongWait)
+	assert.Equal(t, int64(512), app.webSocketConfig.readLimit)
+}